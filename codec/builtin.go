@@ -0,0 +1,77 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/bytedance/sonic"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Built-in codecs.
+var (
+	// Sonic encodes JSON with bytedance/sonic.
+	Sonic Codec = sonicCodec{}
+
+	// JSON encodes JSON with encoding/json. Its payloads are
+	// interchangeable with Sonic's.
+	JSON Codec = jsonCodec{}
+
+	// Gob encodes with encoding/gob. Values stored in interface fields
+	// must be registered with gob.Register.
+	Gob Codec = gobCodec{}
+
+	// MsgPack encodes MessagePack with vmihailenco/msgpack.
+	MsgPack Codec = msgpackCodec{}
+
+	// CBOR encodes CBOR (RFC 8949) with fxamacker/cbor.
+	CBOR Codec = cborCodec{}
+)
+
+type sonicCodec struct{}
+
+func (sonicCodec) ID() byte                           { return IDSonic }
+func (sonicCodec) ContentType() string                { return "application/json" }
+func (sonicCodec) Marshal(v any) ([]byte, error)      { return sonic.Marshal(v) }
+func (sonicCodec) Unmarshal(data []byte, v any) error { return sonic.Unmarshal(data, v) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte                           { return IDJSON }
+func (jsonCodec) ContentType() string                { return "application/json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) ID() byte            { return IDGob }
+func (gobCodec) ContentType() string { return "application/x-gob" }
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte                           { return IDMsgPack }
+func (msgpackCodec) ContentType() string                { return "application/msgpack" }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type cborCodec struct{}
+
+func (cborCodec) ID() byte                           { return IDCBOR }
+func (cborCodec) ContentType() string                { return "application/cbor" }
+func (cborCodec) Marshal(v any) ([]byte, error)      { return cbor.Marshal(v) }
+func (cborCodec) Unmarshal(data []byte, v any) error { return cbor.Unmarshal(data, v) }
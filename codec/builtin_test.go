@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestBuiltin_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	type profile struct {
+		Name  string   `json:"name" msgpack:"name" cbor:"name"`
+		Age   int      `json:"age" msgpack:"age" cbor:"age"`
+		Tags  []string `json:"tags" msgpack:"tags" cbor:"tags"`
+		Admin bool     `json:"admin" msgpack:"admin" cbor:"admin"`
+	}
+
+	tests := []struct {
+		name        string
+		codec       Codec
+		id          byte
+		contentType string
+	}{
+		{name: "should round-trip with sonic", codec: Sonic, id: IDSonic, contentType: "application/json"},
+		{name: "should round-trip with encoding/json", codec: JSON, id: IDJSON, contentType: "application/json"},
+		{name: "should round-trip with gob", codec: Gob, id: IDGob, contentType: "application/x-gob"},
+		{name: "should round-trip with msgpack", codec: MsgPack, id: IDMsgPack, contentType: "application/msgpack"},
+		{name: "should round-trip with cbor", codec: CBOR, id: IDCBOR, contentType: "application/cbor"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			in := profile{Name: "ada", Age: 36, Tags: []string{"a", "b"}, Admin: true}
+
+			// --- Act ---
+			data, err := tt.codec.Marshal(in)
+			assert.NoError(t, err, "expected no error when marshalling")
+
+			var out profile
+			err = tt.codec.Unmarshal(data, &out)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when unmarshalling")
+			assert.Equal(t, in, out, "decoded value must match the original")
+			assert.Equal(t, tt.id, tt.codec.ID(), "ID must match")
+			assert.Equal(t, tt.contentType, tt.codec.ContentType(), "content type must match")
+
+			registered, ok := Lookup(tt.id)
+			assert.True(t, ok, "expected built-in codec to be registered")
+			assert.Equal(t, tt.codec, registered, "expected registry to return the built-in codec")
+		})
+	}
+}
+
+func TestBuiltin_Gob(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return error for invalid payload", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		var out string
+		err := Gob.Unmarshal([]byte("not gob"), &out)
+
+		// --- Assert ---
+		assert.Error(t, err, "expected error for invalid gob payload")
+	})
+
+	t.Run("should return error for unsupported value", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		_, err := Gob.Marshal(make(chan int))
+
+		// --- Assert ---
+		assert.Error(t, err, "expected error for unsupported value")
+	})
+}
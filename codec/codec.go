@@ -0,0 +1,129 @@
+// Package codec defines how cached values are serialized.
+//
+// Every payload written through Encode starts with a one-byte header holding
+// the codec ID, so Decode can read values written by any registered codec.
+// This lets a store switch codecs while entries written by the previous one
+// are still live.
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Built-in codec IDs. IDs are control characters, so a header never
+// collides with the first byte of JSON text written without a header.
+const (
+	IDSonic   byte = 0x01
+	IDJSON    byte = 0x02
+	IDGob     byte = 0x03
+	IDMsgPack byte = 0x04
+	IDCBOR    byte = 0x05
+)
+
+// Custom codecs must use an ID in [MinCustomID, MaxCustomID].
+const (
+	MinCustomID byte = 0x10
+	MaxCustomID byte = 0x1d
+)
+
+// ErrUnknownCodec is returned by Decode when the payload has no known
+// header and no fallback codec is given.
+var ErrUnknownCodec = errors.New("codec: unknown codec")
+
+// Codec marshals and unmarshals cached values.
+//
+// Implementations must be safe for concurrent use.
+type Codec interface {
+	// ID identifies the codec in the header byte of stored payloads.
+	ID() byte
+
+	// ContentType is the MIME type of the encoded payload, e.g. "application/json".
+	ContentType() string
+
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+
+	// Unmarshal decodes data into the value pointed to by v.
+	Unmarshal(data []byte, v any) error
+}
+
+// Raw is an encoded payload, header byte included, whose Go type is
+// unknown to the store that read it. Use Decode to unmarshal it.
+type Raw []byte
+
+// Default is the codec used when none is configured.
+var Default Codec = Sonic
+
+var registry = struct {
+	sync.RWMutex
+	byID map[byte]Codec
+}{
+	byID: map[byte]Codec{
+		IDSonic:   Sonic,
+		IDJSON:    JSON,
+		IDGob:     Gob,
+		IDMsgPack: MsgPack,
+		IDCBOR:    CBOR,
+	},
+}
+
+// Register makes a custom codec available to Decode.
+// It panics if the ID is outside [MinCustomID, MaxCustomID] or already
+// registered to another codec.
+func Register(c Codec) {
+	id := c.ID()
+	if id < MinCustomID || id > MaxCustomID {
+		panic(fmt.Sprintf("codec: id %#x out of custom range [%#x, %#x]", id, MinCustomID, MaxCustomID))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if existing, ok := registry.byID[id]; ok && existing != c {
+		panic(fmt.Sprintf("codec: id %#x already registered to %s", id, existing.ContentType()))
+	}
+
+	registry.byID[id] = c
+}
+
+// Lookup returns the codec registered under id.
+func Lookup(id byte) (Codec, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	c, ok := registry.byID[id]
+	return c, ok
+}
+
+// Encode marshals v with c and prepends the codec header byte.
+func Encode(c Codec, v any) ([]byte, error) {
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(data)+1)
+	out = append(out, c.ID())
+
+	return append(out, data...), nil
+}
+
+// Decode unmarshals data into v using the codec named by its header byte.
+// Data without a known header, such as values written before headers were
+// introduced, is decoded with fallback. Returns ErrUnknownCodec if there
+// is neither a known header nor a fallback.
+func Decode(data []byte, fallback Codec, v any) error {
+	if len(data) > 0 {
+		if c, ok := Lookup(data[0]); ok {
+			return c.Unmarshal(data[1:], v)
+		}
+	}
+
+	if fallback == nil {
+		return ErrUnknownCodec
+	}
+
+	return fallback.Unmarshal(data, v)
+}
@@ -0,0 +1,158 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+type customCodec struct {
+	id byte
+}
+
+func (c customCodec) ID() byte                           { return c.id }
+func (c customCodec) ContentType() string                { return "text/plain" }
+func (c customCodec) Marshal(v any) ([]byte, error)      { return []byte(v.(string)), nil }
+func (c customCodec) Unmarshal(data []byte, v any) error { *v.(*string) = string(data); return nil }
+
+func TestCodec_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name     string
+		data     func(t *testing.T) []byte
+		fallback Codec
+		expected payload
+		err      error
+	}{
+		{
+			name: "should decode payload with the codec named in its header",
+			data: func(t *testing.T) []byte {
+				data, err := Encode(MsgPack, payload{Name: "ada"})
+				assert.NoError(t, err)
+				assert.Equal(t, IDMsgPack, data[0], "expected msgpack header byte")
+				return data
+			},
+			fallback: Sonic,
+			expected: payload{Name: "ada"},
+		},
+		{
+			name: "should decode headerless payload with fallback codec",
+			data: func(t *testing.T) []byte {
+				return []byte(`{"name":"legacy"}`)
+			},
+			fallback: JSON,
+			expected: payload{Name: "legacy"},
+		},
+		{
+			name: "should return ErrUnknownCodec without header or fallback",
+			data: func(t *testing.T) []byte {
+				return []byte(`{"name":"legacy"}`)
+			},
+			err: ErrUnknownCodec,
+		},
+		{
+			name: "should return ErrUnknownCodec for empty data without fallback",
+			data: func(t *testing.T) []byte {
+				return nil
+			},
+			err: ErrUnknownCodec,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			data := tt.data(t)
+
+			// --- Act ---
+			var out payload
+			err := Decode(data, tt.fallback, &out)
+
+			// --- Assert ---
+			if tt.err != nil {
+				assert.True(t, errors.Is(err, tt.err), "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when decoding")
+			assert.Equal(t, tt.expected, out, "decoded value must match")
+		})
+	}
+}
+
+func TestCodec_Encode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return marshal error", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		data, err := Encode(JSON, make(chan int))
+
+		// --- Assert ---
+		assert.Error(t, err, "expected error for unsupported value")
+		assert.True(t, data == nil, "expected no data on error")
+	})
+}
+
+func TestCodec_Register(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should make custom codec available to Decode", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		c := customCodec{id: MinCustomID}
+		Register(c)
+
+		data, err := Encode(c, "hello")
+		assert.NoError(t, err)
+
+		// --- Act ---
+		var out string
+		err = Decode(data, nil, &out)
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when decoding custom payload")
+		assert.Equal(t, "hello", out, "decoded value must match")
+
+		found, ok := Lookup(MinCustomID)
+		assert.True(t, ok, "expected codec to be registered")
+		assert.Equal(t, Codec(c), found, "expected registered codec")
+	})
+
+	t.Run("should panic when ID is outside the custom range", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			assert.NotNil(t, recover(), "expected panic for reserved ID")
+		}()
+
+		Register(customCodec{id: IDSonic})
+	})
+
+	t.Run("should panic when ID is registered to another codec", func(t *testing.T) {
+		t.Parallel()
+
+		Register(customCodec{id: MaxCustomID})
+
+		defer func() {
+			assert.NotNil(t, recover(), "expected panic for duplicate ID")
+		}()
+
+		Register(otherCodec{customCodec{id: MaxCustomID}})
+	})
+}
+
+type otherCodec struct {
+	customCodec
+}
@@ -0,0 +1,331 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// NewManagerFromConfig creates a Manager with the stores described by
+// config, a free-form map as decoded from JSON, YAML or TOML:
+//
+//	default: main
+//	stores:
+//	  main:
+//	    driver: redis
+//	    fallback: local
+//	    options:
+//	      addr: localhost:6379
+//	      dial_timeout: 2s
+//	  local:
+//	    driver: memory
+//	    options:
+//	      cleanup_interval: 5m
+//
+// Each store is created by the factory registered for its driver with
+// RegisterDriver, from its options. fallback optionally names the store to
+// fail over to (see Manager.SetFallback). default names the default store;
+// it may be omitted when a single store is configured. opts are applied
+// to the Manager as with NewManager.
+//
+// The fields and drivers are validated before any store is created; the
+// options of a store are validated by its driver. Returns a ConfigError
+// naming the offending field, e.g. "stores.main.options.dial_timeout", if
+// the configuration is invalid. If a store cannot be created, e.g. because
+// its server is unreachable, the stores created before it are closed.
+func NewManagerFromConfig(config map[string]any, opts ...Option) (*Manager, error) {
+	defaultAlias, stores, err := parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make([]string, 0, len(stores))
+	for alias := range stores {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	m := NewManager(opts...)
+	for _, alias := range aliases {
+		spec := stores[alias]
+
+		store, err := spec.factory(spec.options)
+		if err == nil && store == nil {
+			err = fmt.Errorf("%w: driver %q returned no store", ErrInternal, spec.driver)
+		}
+		if errors.Is(err, ErrInvalidConfig) {
+			err = prefixConfigError("stores."+alias+".options", err)
+		} else if err != nil {
+			err = fmt.Errorf("cache: creating store %q: %w", alias, err)
+		}
+		if err != nil {
+			return nil, errors.Join(err, m.Close(context.Background()))
+		}
+
+		if err := m.Register(alias, store); err != nil {
+			return nil, errors.Join(err, store.Close(context.Background()), m.Close(context.Background()))
+		}
+	}
+
+	for _, alias := range aliases {
+		if fallback := stores[alias].fallback; fallback != "" {
+			if err := m.SetFallback(alias, fallback); err != nil {
+				return nil, errors.Join(err, m.Close(context.Background()))
+			}
+		}
+	}
+
+	if err := m.SetDefault(defaultAlias); err != nil {
+		return nil, errors.Join(err, m.Close(context.Background()))
+	}
+
+	return m, nil
+}
+
+// NewManagerFromEnv is like NewManagerFromConfig, reading the configuration
+// from the environment variables starting with prefix and an underscore:
+//
+//	CACHE_DEFAULT=main
+//	CACHE_STORES_MAIN_DRIVER=redis
+//	CACHE_STORES_MAIN_FALLBACK=local
+//	CACHE_STORES_MAIN_OPTIONS_ADDR=localhost:6379
+//	CACHE_STORES_MAIN_OPTIONS_TLS__SERVER_NAME=cache.internal
+//	CACHE_STORES_LOCAL_DRIVER=memory
+//
+// Store aliases, including those of DEFAULT and FALLBACK, and option names
+// are lowercased. A double underscore
+// separates the names of nested options, such as the TLS settings above.
+// Values are strings, converted as described by DecodeOptions.
+func NewManagerFromEnv(prefix string, opts ...Option) (*Manager, error) {
+	config, err := configFromEnv(prefix, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewManagerFromConfig(config, opts...)
+}
+
+// storeSpec is a validated store configuration.
+type storeSpec struct {
+	driver   string
+	factory  DriverFactory
+	options  map[string]any
+	fallback string
+}
+
+func parseConfig(config map[string]any) (string, map[string]storeSpec, error) {
+	var defaultAlias string
+	var rawStores map[string]any
+
+	for _, key := range sortedKeys(config) {
+		switch key {
+		case "default":
+			alias, ok := config[key].(string)
+			if !ok {
+				return "", nil, configErrorf(key, "expected a string, got %T", config[key])
+			}
+			defaultAlias = alias
+		case "stores":
+			stores, ok := toOptions(config[key])
+			if !ok {
+				return "", nil, configErrorf(key, "expected a map of stores by alias, got %T", config[key])
+			}
+			rawStores = stores
+		default:
+			return "", nil, configErrorf(key, "unknown field")
+		}
+	}
+
+	if len(rawStores) == 0 {
+		return "", nil, configErrorf("stores", "at least one store is required")
+	}
+
+	stores := make(map[string]storeSpec, len(rawStores))
+	for _, alias := range sortedKeys(rawStores) {
+		spec, err := parseStoreConfig("stores."+alias, rawStores[alias])
+		if err != nil {
+			return "", nil, err
+		}
+		stores[alias] = spec
+	}
+
+	if defaultAlias == "" {
+		if len(stores) > 1 {
+			return "", nil, configErrorf("default", "required when more than one store is configured")
+		}
+		for alias := range stores {
+			defaultAlias = alias
+		}
+	}
+
+	if _, exists := stores[defaultAlias]; !exists {
+		return "", nil, configErrorf("default", "store %q is not configured", defaultAlias)
+	}
+
+	for _, alias := range sortedKeys(rawStores) {
+		fallback := stores[alias].fallback
+		if fallback == "" {
+			continue
+		}
+		if _, exists := stores[fallback]; !exists {
+			return "", nil, configErrorf("stores."+alias+".fallback", "store %q is not configured", fallback)
+		}
+		if fallback == alias {
+			return "", nil, configErrorf("stores."+alias+".fallback", "a store cannot be its own fallback")
+		}
+	}
+
+	return defaultAlias, stores, nil
+}
+
+func parseStoreConfig(path string, raw any) (storeSpec, error) {
+	config, ok := toOptions(raw)
+	if !ok {
+		return storeSpec{}, configErrorf(path, "expected a map, got %T", raw)
+	}
+
+	var spec storeSpec
+	for _, key := range sortedKeys(config) {
+		switch key {
+		case "driver":
+			driver, ok := config[key].(string)
+			if !ok {
+				return storeSpec{}, configErrorf(path+".driver", "expected a string, got %T", config[key])
+			}
+			spec.driver = driver
+		case "options":
+			options, ok := toOptions(config[key])
+			if !ok && config[key] != nil {
+				return storeSpec{}, configErrorf(path+".options", "expected a map, got %T", config[key])
+			}
+			spec.options = options
+		case "fallback":
+			fallback, ok := config[key].(string)
+			if !ok {
+				return storeSpec{}, configErrorf(path+".fallback", "expected a string, got %T", config[key])
+			}
+			spec.fallback = fallback
+		default:
+			return storeSpec{}, configErrorf(path+"."+key, "unknown field")
+		}
+	}
+
+	if spec.driver == "" {
+		return storeSpec{}, configErrorf(path+".driver", "required")
+	}
+
+	factory, ok := lookupDriver(spec.driver)
+	if !ok {
+		return storeSpec{}, configErrorf(path+".driver", "unknown driver %q (registered: %s); is its package imported?",
+			spec.driver, strings.Join(Drivers(), ", "))
+	}
+	spec.factory = factory
+
+	if spec.options == nil {
+		spec.options = make(map[string]any)
+	}
+
+	return spec, nil
+}
+
+// configFromEnv builds the configuration read by NewManagerFromEnv from
+// environ, a list of "key=value" strings as returned by os.Environ.
+func configFromEnv(prefix string, environ []string) (map[string]any, error) {
+	prefix = strings.ToUpper(prefix) + "_"
+	config := make(map[string]any)
+	stores := make(map[string]any)
+
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(strings.ToUpper(name), prefix) {
+			continue
+		}
+		key := strings.ToUpper(name[len(prefix):])
+
+		if key == "DEFAULT" {
+			config["default"] = strings.ToLower(value)
+			continue
+		}
+
+		rest := strings.TrimPrefix(key, "STORES_")
+		if rest == key {
+			return nil, configErrorf(name, "unknown variable")
+		}
+
+		if alias, option, found := strings.Cut(rest, "_OPTIONS_"); found && alias != "" && option != "" {
+			store := envStore(stores, strings.ToLower(alias))
+			if err := setEnvOption(name, store["options"].(map[string]any), strings.ToLower(option), value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if alias := strings.TrimSuffix(rest, "_DRIVER"); alias != rest && alias != "" {
+			envStore(stores, strings.ToLower(alias))["driver"] = value
+			continue
+		}
+
+		if alias := strings.TrimSuffix(rest, "_FALLBACK"); alias != rest && alias != "" {
+			envStore(stores, strings.ToLower(alias))["fallback"] = strings.ToLower(value)
+			continue
+		}
+
+		return nil, configErrorf(name, "unknown variable")
+	}
+
+	if len(stores) > 0 {
+		config["stores"] = stores
+	}
+
+	return config, nil
+}
+
+// envStore returns the configuration of alias in stores, creating it.
+func envStore(stores map[string]any, alias string) map[string]any {
+	store, ok := stores[alias].(map[string]any)
+	if !ok {
+		store = map[string]any{"options": make(map[string]any)}
+		stores[alias] = store
+	}
+
+	return store
+}
+
+// setEnvOption sets the option named by a "__"-separated path in options.
+func setEnvOption(variable string, options map[string]any, path, value string) error {
+	names := strings.Split(path, "__")
+	for _, name := range names[:len(names)-1] {
+		nested, exists := options[name]
+		if !exists {
+			nested = make(map[string]any)
+			options[name] = nested
+		}
+
+		m, ok := nested.(map[string]any)
+		if !ok {
+			return configErrorf(variable, "option %q is both a value and nested options", name)
+		}
+		options = m
+	}
+
+	name := names[len(names)-1]
+	if _, nested := options[name].(map[string]any); nested {
+		return configErrorf(variable, "option %q is both a value and nested options", name)
+	}
+	options[name] = value
+
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
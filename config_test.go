@@ -0,0 +1,393 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// configuredStore is created by the "configtest" driver. Only Close may be
+// called on it.
+type configuredStore struct {
+	contract.Store
+	config configtestConfig
+	closed int32
+}
+
+func (s *configuredStore) Close(ctx context.Context) error {
+	atomic.AddInt32(&s.closed, 1)
+	return nil
+}
+
+type configtestConfig struct {
+	Name string
+	Fail bool
+}
+
+// configtestCreated records the stores created by the "configtest" driver
+// by name.
+var configtestCreated = struct {
+	sync.Mutex
+	stores map[string]*configuredStore
+}{
+	stores: make(map[string]*configuredStore),
+}
+
+func init() {
+	RegisterDriver("configtest", func(options map[string]any) (contract.Store, error) {
+		var config configtestConfig
+		if err := DecodeOptions(options, &config); err != nil {
+			return nil, err
+		}
+
+		if config.Fail {
+			return nil, errors.New("connection refused")
+		}
+
+		store := &configuredStore{config: config}
+
+		configtestCreated.Lock()
+		configtestCreated.stores[config.Name] = store
+		configtestCreated.Unlock()
+
+		return store, nil
+	})
+
+	RegisterDriver("configtest-invalid", func(options map[string]any) (contract.Store, error) {
+		return nil, ErrInvalidConfig
+	})
+}
+
+func createdStore(name string) *configuredStore {
+	configtestCreated.Lock()
+	defer configtestCreated.Unlock()
+
+	return configtestCreated.stores[name]
+}
+
+func TestNewManagerFromConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		config          map[string]any
+		expectedDefault string
+		expectedAliases []string
+	}{
+		{
+			name: "should register every store and set the default",
+			config: map[string]any{
+				"default": "secondary",
+				"stores": map[string]any{
+					"primary":   map[string]any{"driver": "configtest", "options": map[string]any{"name": "valid-primary"}},
+					"secondary": map[string]any{"driver": "configtest", "options": map[string]any{"name": "valid-secondary"}},
+				},
+			},
+			expectedDefault: "valid-secondary",
+			expectedAliases: []string{"primary", "secondary"},
+		},
+		{
+			name: "should use the only store as default",
+			config: map[string]any{
+				"stores": map[any]any{
+					"only": map[any]any{"driver": "configtest", "options": map[any]any{"name": "valid-only"}},
+				},
+			},
+			expectedDefault: "valid-only",
+			expectedAliases: []string{"only"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			m, err := NewManagerFromConfig(tt.config)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, contract.Store(createdStore(tt.expectedDefault)), m.current(), "expected the default store")
+
+			aliases := make([]string, 0)
+			for alias := range m.Stats() {
+				aliases = append(aliases, alias)
+			}
+			sort.Strings(aliases)
+			assert.Equal(t, tt.expectedAliases, aliases, "expected every store to be registered")
+		})
+	}
+}
+
+func TestNewManagerFromConfig_errors(t *testing.T) {
+	t.Parallel()
+
+	store := func(driver string, options map[string]any) map[string]any {
+		return map[string]any{"driver": driver, "options": options}
+	}
+
+	tests := []struct {
+		name        string
+		config      map[string]any
+		expectedErr string
+	}{
+		{
+			name:        "should reject unknown fields",
+			config:      map[string]any{"stores": map[string]any{"a": store("configtest", nil)}, "defualt": "a"},
+			expectedErr: "cache: invalid config: defualt: unknown field",
+		},
+		{
+			name:        "should require stores",
+			config:      map[string]any{"default": "a"},
+			expectedErr: "cache: invalid config: stores: at least one store is required",
+		},
+		{
+			name:        "should reject stores that are not maps",
+			config:      map[string]any{"stores": map[string]any{"a": "configtest"}},
+			expectedErr: "cache: invalid config: stores.a: expected a map, got string",
+		},
+		{
+			name:        "should require a driver",
+			config:      map[string]any{"stores": map[string]any{"a": map[string]any{}}},
+			expectedErr: "cache: invalid config: stores.a.driver: required",
+		},
+		{
+			name:        "should reject unknown store fields",
+			config:      map[string]any{"stores": map[string]any{"a": map[string]any{"driver": "configtest", "ttl": "1m"}}},
+			expectedErr: "cache: invalid config: stores.a.ttl: unknown field",
+		},
+		{
+			name:        "should require the default when several stores are configured",
+			config:      map[string]any{"stores": map[string]any{"a": store("configtest", nil), "b": store("configtest", nil)}},
+			expectedErr: "cache: invalid config: default: required when more than one store is configured",
+		},
+		{
+			name:        "should reject a default that is not configured",
+			config:      map[string]any{"default": "c", "stores": map[string]any{"a": store("configtest", nil)}},
+			expectedErr: `cache: invalid config: default: store "c" is not configured`,
+		},
+		{
+			name: "should reject a fallback that is not configured",
+			config: map[string]any{"stores": map[string]any{
+				"a": map[string]any{"driver": "configtest", "fallback": "b"},
+			}},
+			expectedErr: `cache: invalid config: stores.a.fallback: store "b" is not configured`,
+		},
+		{
+			name: "should reject a store as its own fallback",
+			config: map[string]any{"stores": map[string]any{
+				"a": map[string]any{"driver": "configtest", "fallback": "a"},
+			}},
+			expectedErr: "cache: invalid config: stores.a.fallback: a store cannot be its own fallback",
+		},
+		{
+			name:        "should name the option path of invalid options",
+			config:      map[string]any{"stores": map[string]any{"a": store("configtest", map[string]any{"fail": "maybe"})}},
+			expectedErr: `cache: invalid config: stores.a.options.fail: invalid boolean "maybe"`,
+		},
+		{
+			name:        "should name the store of options rejected by its driver",
+			config:      map[string]any{"stores": map[string]any{"a": store("configtest-invalid", nil)}},
+			expectedErr: "cache: invalid config: stores.a.options: invalid value",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			m, err := NewManagerFromConfig(tt.config)
+
+			// --- Assert ---
+			assert.True(t, m == nil, "expected no manager")
+			assert.True(t, errors.Is(err, ErrInvalidConfig), "expected ErrInvalidConfig")
+			assert.Equal(t, tt.expectedErr, err.Error(), "expected the error to name the field")
+
+			var configErr *ConfigError
+			assert.True(t, errors.As(err, &configErr), "expected a ConfigError")
+		})
+	}
+}
+
+func TestNewManagerFromConfig_fallback(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	m, err := NewManagerFromConfig(map[string]any{
+		"default": "main",
+		"stores": map[string]any{
+			"main":  map[string]any{"driver": "configtest", "fallback": "local", "options": map[string]any{"name": "fallback-main"}},
+			"local": map[string]any{"driver": "configtest", "options": map[string]any{"name": "fallback-local"}},
+		},
+	})
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, map[string]string{"main": "local"}, m.fallbacks, "expected the fallback to be set")
+}
+
+func TestNewManagerFromConfig_unknownDriver(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	_, err := NewManagerFromConfig(map[string]any{
+		"stores": map[string]any{"a": map[string]any{"driver": "nosuchdriver"}},
+	})
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, ErrInvalidConfig), "expected ErrInvalidConfig")
+	assert.Contains(t, err.Error(), `stores.a.driver: unknown driver "nosuchdriver"`, "expected the driver field")
+	assert.Contains(t, err.Error(), "configtest", "expected the registered drivers to be listed")
+}
+
+func TestNewManagerFromConfig_closesStoresOnFailure(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	m, err := NewManagerFromConfig(map[string]any{
+		"default": "a",
+		"stores": map[string]any{
+			"a": map[string]any{"driver": "configtest", "options": map[string]any{"name": "closed-a"}},
+			"b": map[string]any{"driver": "configtest", "options": map[string]any{"fail": true}},
+		},
+	})
+
+	// --- Assert ---
+	assert.True(t, m == nil, "expected no manager")
+	assert.EqualError(t, errors.New(`cache: creating store "b": connection refused`), err, "expected the store error")
+	assert.False(t, errors.Is(err, ErrInvalidConfig), "expected connection errors not to be config errors")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&createdStore("closed-a").closed), "expected the created store to be closed")
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		environ     []string
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name: "should read stores, options and nested options",
+			environ: []string{
+				"PATH=/usr/bin",
+				"CACHE_DEFAULT=main",
+				"CACHE_STORES_MAIN_DRIVER=redis",
+				"CACHE_STORES_MAIN_OPTIONS_DIAL_TIMEOUT=2s",
+				"CACHE_STORES_MAIN_OPTIONS_TLS__SERVER_NAME=cache.internal",
+				"CACHE_STORES_LOCAL_CACHE_DRIVER=memory",
+				"CACHE_STORES_LOCAL_CACHE_OPTIONS_URL=a=b",
+			},
+			expected: map[string]any{
+				"default": "main",
+				"stores": map[string]any{
+					"main": map[string]any{
+						"driver": "redis",
+						"options": map[string]any{
+							"dial_timeout": "2s",
+							"tls":          map[string]any{"server_name": "cache.internal"},
+						},
+					},
+					"local_cache": map[string]any{
+						"driver":  "memory",
+						"options": map[string]any{"url": "a=b"},
+					},
+				},
+			},
+		},
+		{
+			name: "should read lowercased fallbacks",
+			environ: []string{
+				"CACHE_DEFAULT=MAIN",
+				"CACHE_STORES_MAIN_DRIVER=redis",
+				"CACHE_STORES_MAIN_FALLBACK=LOCAL",
+				"CACHE_STORES_LOCAL_DRIVER=memory",
+			},
+			expected: map[string]any{
+				"default": "main",
+				"stores": map[string]any{
+					"main":  map[string]any{"driver": "redis", "fallback": "local", "options": map[string]any{}},
+					"local": map[string]any{"driver": "memory", "options": map[string]any{}},
+				},
+			},
+		},
+		{
+			name:        "should reject unknown variables",
+			environ:     []string{"CACHE_STORE_MAIN_DRIVER=redis"},
+			expectedErr: "cache: invalid config: CACHE_STORE_MAIN_DRIVER: unknown variable",
+		},
+		{
+			name: "should reject options that are both values and nested options",
+			environ: []string{
+				"CACHE_STORES_MAIN_OPTIONS_TLS=true",
+				"CACHE_STORES_MAIN_OPTIONS_TLS__SERVER_NAME=cache.internal",
+			},
+			expectedErr: `cache: invalid config: CACHE_STORES_MAIN_OPTIONS_TLS__SERVER_NAME: option "tls" is both a value and nested options`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			config, err := configFromEnv("cache", tt.environ)
+
+			// --- Assert ---
+			if tt.expectedErr != "" {
+				assert.Error(t, err, "expected an error")
+				assert.Equal(t, tt.expectedErr, err.Error(), "expected the error to name the variable")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, config, "expected the configuration")
+		})
+	}
+}
+
+func TestRegisterDriver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		driver  string
+		factory DriverFactory
+	}{
+		{
+			name:    "should panic when the driver is already registered",
+			driver:  "configtest",
+			factory: func(map[string]any) (contract.Store, error) { return nil, nil },
+		},
+		{
+			name:   "should panic when the factory is nil",
+			driver: "configtest-nil",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			defer func() {
+				// --- Assert ---
+				assert.NotNil(t, recover(), "expected a panic")
+				assert.True(t, len(Drivers()) >= 2, "expected the registered drivers to be kept")
+			}()
+			RegisterDriver(tt.driver, tt.factory)
+		})
+	}
+}
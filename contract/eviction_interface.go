@@ -0,0 +1,8 @@
+package contract
+
+// EvictionCounter is implemented by stores that evict entries before they
+// expire, e.g. to stay within a size limit.
+type EvictionCounter interface {
+	// Evictions returns the number of entries evicted since the store was created.
+	Evictions() uint64
+}
@@ -0,0 +1,11 @@
+package contract
+
+import "context"
+
+// Pinger is implemented by stores that can check that they are able to
+// serve requests, e.g. that their server is reachable. Manager.Health
+// uses it; stores that do not implement it are probed with Has.
+type Pinger interface {
+	// Ping returns an error if the store cannot currently serve requests.
+	Ping(ctx context.Context) error
+}
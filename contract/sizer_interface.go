@@ -0,0 +1,8 @@
+package contract
+
+// Sizer is implemented by stores that can report how much they hold.
+type Sizer interface {
+	// Size returns the number of entries in the store and their
+	// approximate size in bytes.
+	Size() (entries int64, bytes int64)
+}
@@ -0,0 +1,25 @@
+package contract
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Streamer is implemented by stores that can read and write byte values
+// without holding them in memory as a whole. Stores that do not implement
+// it are streamed through a buffer by the Manager.
+//
+// Values written with SetFromReader are []byte values: Get returns them
+// the way the store returns any other []byte value.
+type Streamer interface {
+	// GetReader returns a reader over the []byte or string value stored
+	// under key. The caller must close it.
+	// It returns ErrCacheMiss if the key is not found or expired, and
+	// ErrTypeMismatch if the value is neither a []byte nor a string.
+	GetReader(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// SetFromReader stores everything read from r as a []byte value with
+	// the given key and TTL. Readers never observe a partially written value.
+	SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error
+}
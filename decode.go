@@ -0,0 +1,400 @@
+package omnicache
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigError reports an invalid configuration value. It matches
+// ErrInvalidConfig with errors.Is.
+type ConfigError struct {
+	// Path locates the value, e.g. "stores.main.options.cleanup_interval".
+	Path string
+
+	// Err describes what is wrong with the value.
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	reason := e.Err.Error()
+	if errors.Is(e.Err, ErrInvalidConfig) {
+		// Avoid repeating the sentinel wrapped by driver errors.
+		reason = strings.TrimPrefix(reason, ErrInvalidConfig.Error())
+		reason = strings.TrimPrefix(reason, ": ")
+		if reason == "" {
+			reason = "invalid value"
+		}
+	}
+
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidConfig, e.Path, reason)
+}
+
+func (e *ConfigError) Unwrap() []error {
+	return []error{ErrInvalidConfig, e.Err}
+}
+
+// configErrorf returns a ConfigError for path with a formatted reason.
+func configErrorf(path, format string, args ...any) error {
+	return &ConfigError{Path: path, Err: fmt.Errorf(format, args...)}
+}
+
+// prefixConfigError prepends prefix to the path of a ConfigError and
+// reports any other error as an error of the value at prefix.
+func prefixConfigError(prefix string, err error) error {
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return &ConfigError{Path: prefix + "." + configErr.Path, Err: configErr.Err}
+	}
+
+	return &ConfigError{Path: prefix, Err: err}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// DecodeOptions decodes free-form options, as read from JSON, YAML, TOML
+// or environment variables, into the struct target points to.
+//
+// An option sets the exported field whose name matches the option name
+// ignoring case, underscores and hyphens: "cleanup_interval",
+// "cleanupInterval" and "CLEANUP_INTERVAL" all set CleanupInterval.
+// Values are converted to the field type, so strings are accepted for
+// every field:
+//   - durations are strings such as "1m30s";
+//   - integers may be written in any base Go accepts, e.g. "0o600";
+//   - slices may be a comma-separated string, maps a string of
+//     comma-separated key=value pairs;
+//   - structs and pointers to structs are nested option maps.
+//
+// Fields holding functions, interfaces or structs without exported fields,
+// such as a *slog.Logger, cannot be configured this way.
+//
+// Returns a ConfigError naming the option for unknown options and values
+// that do not convert.
+func DecodeOptions(options map[string]any, target any) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: decode target must be a non-nil pointer to a struct, got %T", ErrInvalidConfig, target)
+	}
+
+	return decodeStruct("", options, v.Elem())
+}
+
+func decodeStruct(path string, options map[string]any, v reflect.Value) error {
+	fields := make(map[string]int, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if field := v.Type().Field(i); field.IsExported() && !field.Anonymous {
+			fields[normalizeOptionName(field.Name)] = i
+		}
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fieldPath := joinPath(path, name)
+
+		i, ok := fields[normalizeOptionName(name)]
+		if !ok {
+			return configErrorf(fieldPath, "unknown option")
+		}
+
+		if err := decodeValue(fieldPath, options[name], v.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(path string, raw any, v reflect.Value) error {
+	if raw == nil {
+		return nil
+	}
+
+	if v.Type() == durationType {
+		s, ok := raw.(string)
+		if !ok {
+			return configErrorf(path, "expected a duration such as \"30s\", got %T", raw)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			return configErrorf(path, "invalid duration %q", s)
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		switch raw.(type) {
+		case string, bool, int, int64, uint64, float64:
+			v.SetString(fmt.Sprint(raw))
+			return nil
+		}
+
+	case reflect.Bool:
+		switch r := raw.(type) {
+		case bool:
+			v.SetBool(r)
+			return nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(r))
+			if err != nil {
+				return configErrorf(path, "invalid boolean %q", r)
+			}
+			v.SetBool(b)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok, err := toInt(raw)
+		if err != nil || ok && v.OverflowInt(n) {
+			return configErrorf(path, "invalid integer %v", raw)
+		}
+		if ok {
+			v.SetInt(n)
+			return nil
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok, err := toInt(raw)
+		if err != nil || ok && (n < 0 || v.OverflowUint(uint64(n))) {
+			return configErrorf(path, "invalid unsigned integer %v", raw)
+		}
+		if ok {
+			v.SetUint(uint64(n))
+			return nil
+		}
+
+	case reflect.Float32, reflect.Float64:
+		f, ok, err := toFloat(raw)
+		if err != nil {
+			return configErrorf(path, "invalid number %v", raw)
+		}
+		if ok {
+			v.SetFloat(f)
+			return nil
+		}
+
+	case reflect.Slice:
+		return decodeSlice(path, raw, v)
+
+	case reflect.Map:
+		return decodeMap(path, raw, v)
+
+	case reflect.Struct:
+		if options, ok := toOptions(raw); ok && hasExportedField(v.Type()) {
+			return decodeStruct(path, options, v)
+		}
+
+	case reflect.Pointer:
+		if v.Type().Elem().Kind() == reflect.Struct && hasExportedField(v.Type().Elem()) {
+			elem := reflect.New(v.Type().Elem())
+			if err := decodeValue(path, raw, elem.Elem()); err != nil {
+				return err
+			}
+			v.Set(elem)
+			return nil
+		}
+	}
+
+	if !configurable(v.Type()) {
+		return configErrorf(path, "option cannot be set from configuration")
+	}
+
+	return configErrorf(path, "cannot use %T as %s", raw, v.Type())
+}
+
+func decodeSlice(path string, raw any, v reflect.Value) error {
+	var items []any
+	switch r := raw.(type) {
+	case string:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte(r))
+			return nil
+		}
+		for _, item := range strings.Split(r, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case []any:
+		items = r
+	case []string:
+		for _, item := range r {
+			items = append(items, item)
+		}
+	default:
+		return configErrorf(path, "cannot use %T as %s", raw, v.Type())
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := decodeValue(fmt.Sprintf("%s[%d]", path, i), item, slice.Index(i)); err != nil {
+			return err
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
+
+func decodeMap(path string, raw any, v reflect.Value) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return configErrorf(path, "option cannot be set from configuration")
+	}
+
+	entries, ok := toOptions(raw)
+	if s, isString := raw.(string); isString {
+		entries, ok = make(map[string]any), true
+		for _, pair := range strings.Split(s, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+
+			key, value, found := strings.Cut(pair, "=")
+			if !found {
+				return configErrorf(path, "expected key=value pairs, got %q", pair)
+			}
+			entries[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	if !ok {
+		return configErrorf(path, "cannot use %T as %s", raw, v.Type())
+	}
+
+	m := reflect.MakeMapWithSize(v.Type(), len(entries))
+	for key, value := range entries {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(joinPath(path, key), value, elem); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+	}
+	v.Set(m)
+
+	return nil
+}
+
+// toInt converts raw to an integer. ok is false if raw is not a number or
+// a string.
+func toInt(raw any) (n int64, ok bool, err error) {
+	switch r := raw.(type) {
+	case int:
+		return int64(r), true, nil
+	case int64:
+		return r, true, nil
+	case uint64:
+		if r > math.MaxInt64 {
+			return 0, true, strconv.ErrRange
+		}
+		return int64(r), true, nil
+	case float64:
+		if r != math.Trunc(r) || r < math.MinInt64 || r > math.MaxInt64 {
+			return 0, true, strconv.ErrRange
+		}
+		return int64(r), true, nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(r), 0, 64)
+		return n, true, err
+	default:
+		return 0, false, nil
+	}
+}
+
+// toFloat converts raw to a float. ok is false if raw is not a number or
+// a string.
+func toFloat(raw any) (f float64, ok bool, err error) {
+	switch r := raw.(type) {
+	case int:
+		return float64(r), true, nil
+	case int64:
+		return float64(r), true, nil
+	case uint64:
+		return float64(r), true, nil
+	case float64:
+		return r, true, nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(r), 64)
+		return f, true, err
+	default:
+		return 0, false, nil
+	}
+}
+
+// toOptions converts a nested option map. YAML decoders may produce maps
+// keyed by any.
+func toOptions(raw any) (map[string]any, bool) {
+	switch r := raw.(type) {
+	case map[string]any:
+		return r, true
+	case map[string]string:
+		options := make(map[string]any, len(r))
+		for key, value := range r {
+			options[key] = value
+		}
+		return options, true
+	case map[any]any:
+		options := make(map[string]any, len(r))
+		for key, value := range r {
+			options[fmt.Sprint(key)] = value
+		}
+		return options, true
+	default:
+		return nil, false
+	}
+}
+
+// configurable reports whether values of t can be decoded from options.
+func configurable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Interface, reflect.Chan, reflect.UnsafePointer, reflect.Uintptr, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Struct:
+		return hasExportedField(t)
+	case reflect.Pointer:
+		return t.Elem().Kind() == reflect.Struct && hasExportedField(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && configurable(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return configurable(t.Elem())
+	default:
+		return true
+	}
+}
+
+func hasExportedField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeOptionName folds an option or field name for matching.
+func normalizeOptionName(name string) string {
+	name = strings.ReplaceAll(name, "_", "")
+	name = strings.ReplaceAll(name, "-", "")
+	return strings.ToLower(name)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
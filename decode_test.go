@@ -0,0 +1,175 @@
+package omnicache
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+type decodeTLS struct {
+	ServerName string
+	CAPEM      []byte
+}
+
+type decodeConfig struct {
+	Addr        string
+	Addrs       []string
+	Shards      map[string]string
+	DB          int
+	ScanCount   int64
+	FileMode    os.FileMode
+	Ratio       float64
+	NoSync      bool
+	DialTimeout time.Duration
+	TLS         *decodeTLS
+	Logger      *slog.Logger
+	OnError     func(error)
+}
+
+func TestDecodeOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		options     map[string]any
+		expected    decodeConfig
+		expectedErr string
+	}{
+		{
+			name: "should decode typed values as read from JSON or YAML",
+			options: map[string]any{
+				"addr":         "localhost:6379",
+				"addrs":        []any{"a:1", "b:2"},
+				"shards":       map[string]any{"s1": "a:1"},
+				"db":           float64(2),
+				"scan_count":   500,
+				"file_mode":    384,
+				"ratio":        0.5,
+				"no_sync":      true,
+				"dial_timeout": "2s",
+				"tls":          map[any]any{"server_name": "cache.internal"},
+			},
+			expected: decodeConfig{
+				Addr:        "localhost:6379",
+				Addrs:       []string{"a:1", "b:2"},
+				Shards:      map[string]string{"s1": "a:1"},
+				DB:          2,
+				ScanCount:   500,
+				FileMode:    0o600,
+				Ratio:       0.5,
+				NoSync:      true,
+				DialTimeout: 2 * time.Second,
+				TLS:         &decodeTLS{ServerName: "cache.internal"},
+			},
+		},
+		{
+			name: "should decode strings as read from environment variables",
+			options: map[string]any{
+				"ADDRS":     "a:1, b:2",
+				"shards":    "s1=a:1,s2=b:2",
+				"db":        "2",
+				"fileMode":  "0o600",
+				"ratio":     "0.5",
+				"no-sync":   "true",
+				"tls":       map[string]any{"capem": "pem"},
+				"scancount": "0x10",
+			},
+			expected: decodeConfig{
+				Addrs:     []string{"a:1", "b:2"},
+				Shards:    map[string]string{"s1": "a:1", "s2": "b:2"},
+				DB:        2,
+				ScanCount: 16,
+				FileMode:  0o600,
+				Ratio:     0.5,
+				NoSync:    true,
+				TLS:       &decodeTLS{CAPEM: []byte("pem")},
+			},
+		},
+		{
+			name:        "should reject unknown options",
+			options:     map[string]any{"adress": "localhost"},
+			expectedErr: "cache: invalid config: adress: unknown option",
+		},
+		{
+			name:        "should reject invalid durations",
+			options:     map[string]any{"dial_timeout": "2 seconds"},
+			expectedErr: `cache: invalid config: dial_timeout: invalid duration "2 seconds"`,
+		},
+		{
+			name:        "should reject durations given as numbers",
+			options:     map[string]any{"dial_timeout": 2},
+			expectedErr: `cache: invalid config: dial_timeout: expected a duration such as "30s", got int`,
+		},
+		{
+			name:        "should reject fractional integers",
+			options:     map[string]any{"db": 1.5},
+			expectedErr: "cache: invalid config: db: invalid integer 1.5",
+		},
+		{
+			name:        "should reject negative unsigned integers",
+			options:     map[string]any{"file_mode": -1},
+			expectedErr: "cache: invalid config: file_mode: invalid unsigned integer -1",
+		},
+		{
+			name:        "should name the path of nested options",
+			options:     map[string]any{"tls": map[string]any{"server": "x"}},
+			expectedErr: "cache: invalid config: tls.server: unknown option",
+		},
+		{
+			name:        "should name the index of slice elements",
+			options:     map[string]any{"addrs": []any{"a:1", map[string]any{}}},
+			expectedErr: "cache: invalid config: addrs[1]: cannot use map[string]interface {} as string",
+		},
+		{
+			name:        "should reject malformed map strings",
+			options:     map[string]any{"shards": "s1"},
+			expectedErr: `cache: invalid config: shards: expected key=value pairs, got "s1"`,
+		},
+		{
+			name:        "should reject fields that cannot be configured",
+			options:     map[string]any{"on_error": "log"},
+			expectedErr: "cache: invalid config: on_error: option cannot be set from configuration",
+		},
+		{
+			name:        "should reject structs without exported fields",
+			options:     map[string]any{"logger": map[string]any{}},
+			expectedErr: "cache: invalid config: logger: option cannot be set from configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			var config decodeConfig
+			err := DecodeOptions(tt.options, &config)
+
+			// --- Assert ---
+			if tt.expectedErr != "" {
+				assert.Error(t, err, "expected an error")
+				assert.Equal(t, tt.expectedErr, err.Error(), "expected the error to name the option")
+				assert.True(t, errors.Is(err, ErrInvalidConfig), "expected ErrInvalidConfig")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, config, "expected options to be decoded")
+		})
+	}
+}
+
+func TestDecodeOptions_invalidTarget(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	err := DecodeOptions(map[string]any{}, decodeConfig{})
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, ErrInvalidConfig), "expected ErrInvalidConfig for a non-pointer target")
+}
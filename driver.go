@@ -0,0 +1,64 @@
+package omnicache
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverFactory creates a store from the options configured for it,
+// usually by decoding them into the driver's config with DecodeOptions.
+type DriverFactory func(options map[string]any) (contract.Store, error)
+
+var drivers = struct {
+	sync.RWMutex
+	byName map[string]DriverFactory
+}{
+	byName: make(map[string]DriverFactory),
+}
+
+// RegisterDriver makes a driver available to NewManagerFromConfig under
+// name. Drivers register themselves when their package is imported, so
+// import the drivers a configuration may use, if only for side effects:
+//
+//	import _ "github.com/shoraid/omnicache/drivers/memory"
+//
+// It panics if factory is nil or name is already registered.
+func RegisterDriver(name string, factory DriverFactory) {
+	if factory == nil {
+		panic(fmt.Sprintf("cache: driver %q factory is nil", name))
+	}
+
+	drivers.Lock()
+	defer drivers.Unlock()
+
+	if _, exists := drivers.byName[name]; exists {
+		panic(fmt.Sprintf("cache: driver %q already registered", name))
+	}
+
+	drivers.byName[name] = factory
+}
+
+// Drivers returns the names of the registered drivers, sorted.
+func Drivers() []string {
+	drivers.RLock()
+	defer drivers.RUnlock()
+
+	names := make([]string, 0, len(drivers.byName))
+	for name := range drivers.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func lookupDriver(name string) (DriverFactory, bool) {
+	drivers.RLock()
+	defer drivers.RUnlock()
+
+	factory, ok := drivers.byName[name]
+	return factory, ok
+}
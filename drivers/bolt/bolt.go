@@ -0,0 +1,492 @@
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/glob"
+	"github.com/shoraid/omnicache/internal/storevalue"
+	bolt "go.etcd.io/bbolt"
+)
+
+// expirationSize is the size of the expiration prefix stored before
+// every value (Unix nanoseconds, big-endian, 0 = no expiration).
+const expirationSize = 8
+
+type BoltStore struct {
+	mu            sync.Mutex
+	db            *bolt.DB
+	bucket        []byte
+	ownsDB        bool
+	closed        bool
+	batchSize     int
+	logger        *slog.Logger
+	codec         codec.Codec
+	cancelCleanup context.CancelFunc
+	doneCh        chan struct{}
+}
+
+// NewBoltStore opens (or creates) the database file at config.Path and
+// returns a persistent store backed by it. Entries survive restarts.
+// It starts a background goroutine to periodically purge expired entries.
+// Returns ErrInvalidConfig if Path is empty.
+func NewBoltStore(config BoltConfig) (contract.Store, error) {
+	if config.Path == "" {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	fileMode := DefaultFileMode
+	if config.FileMode != 0 {
+		fileMode = config.FileMode
+	}
+
+	openTimeout := DefaultOpenTimeout
+	if config.OpenTimeout > 0 {
+		openTimeout = config.OpenTimeout
+	}
+
+	db, err := bolt.Open(config.Path, fileMode, &bolt.Options{
+		Timeout: openTimeout,
+		NoSync:  config.NoSync,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := newBoltStore(db, config)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store.ownsDB = true
+
+	return store, nil
+}
+
+// NewBoltWithDB creates a new BoltStore using a pre-opened database.
+// The database is not closed by Close; its lifecycle is managed by the caller.
+// Only the Bucket, CleanupInterval, CleanupBatchSize, Logger and Codec fields of config are used.
+func NewBoltWithDB(db *bolt.DB, config BoltConfig) (contract.Store, error) {
+	if db == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	return newBoltStore(db, config)
+}
+
+func newBoltStore(db *bolt.DB, config BoltConfig) (*BoltStore, error) {
+	bucket := DefaultBucket
+	if config.Bucket != "" {
+		bucket = config.Bucket
+	}
+
+	batchSize := DefaultCleanupBatchSize
+	if config.CleanupBatchSize > 0 {
+		batchSize = config.CleanupBatchSize
+	}
+
+	store := &BoltStore{
+		db:        db,
+		bucket:    []byte(bucket),
+		batchSize: batchSize,
+		logger:    config.Logger,
+		codec:     config.Codec,
+		doneCh:    make(chan struct{}),
+	}
+
+	if store.logger == nil {
+		store.logger = slog.Default()
+	}
+
+	if store.codec == nil {
+		store.codec = codec.Default
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(store.bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Set the cleanup interval from config, or use default
+	cleanupInterval := DefaultCleanupInterval
+	if config.CleanupInterval > 0 {
+		cleanupInterval = config.CleanupInterval
+	}
+
+	// Start the background goroutine for cleaning up expired keys
+	ctx, cancel := context.WithCancel(context.Background())
+	store.cancelCleanup = cancel
+
+	go store.cleanupExpiredKeys(ctx, cleanupInterval)
+
+	return store, nil
+}
+
+// cleanupExpiredKeys runs in a background goroutine to remove expired keys at regular intervals.
+func (b *BoltStore) cleanupExpiredKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() {
+		// Notify that the cleanup goroutine has exited.
+		// This is used for deterministic shutdowns in tests.
+		if b.doneCh != nil {
+			close(b.doneCh)
+		}
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.deleteExpiredKeys(ctx); err != nil && ctx.Err() == nil {
+				b.logger.Warn("omnicache: purging expired keys failed", slog.String("driver", "bolt"), slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deleteExpiredKeys finds and removes all expired keys from the store.
+// Keys are collected in read transactions, which don't block writers,
+// and removed in write transactions of at most batchSize keys each.
+func (b *BoltStore) deleteExpiredKeys(ctx context.Context) error {
+	now := time.Now()
+	var start []byte
+
+	for {
+		var expired [][]byte
+		var next []byte
+
+		err := b.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(b.bucket).Cursor()
+
+			k, v := c.First()
+			if start != nil {
+				k, v = c.Seek(start)
+			}
+
+			for ; k != nil; k, v = c.Next() {
+				if len(expired) >= b.batchSize {
+					next = cloneBytes(k)
+					return nil
+				}
+				if isExpired(v, now) {
+					expired = append(expired, cloneBytes(k))
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(expired) > 0 {
+			err := b.db.Update(func(tx *bolt.Tx) error {
+				bkt := tx.Bucket(b.bucket)
+				for _, k := range expired {
+					// Re-check: the key may have been overwritten since the scan
+					if v := bkt.Get(k); v != nil && isExpired(v, now) {
+						if err := bkt.Delete(k); err != nil {
+							return err
+						}
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if next == nil {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		start = next
+	}
+}
+
+// Clear removes all entries from the bucket.
+func (b *BoltStore) Clear(ctx context.Context) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(b.bucket); err != nil && err != bolt.ErrBucketNotFound {
+			return err
+		}
+		_, err := tx.CreateBucket(b.bucket)
+		return err
+	})
+}
+
+// Close stops the background cleanup goroutine and closes the database
+// if it was opened by NewBoltStore. It is safe to call Close multiple times.
+func (b *BoltStore) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	if b.cancelCleanup != nil {
+		b.cancelCleanup()
+		b.cancelCleanup = nil // Prevent calling cancel multiple times
+
+		// Wait for an in-flight purge before closing the database under it
+		if b.doneCh != nil {
+			<-b.doneCh
+		}
+	}
+
+	if b.ownsDB {
+		return b.db.Close()
+	}
+
+	return nil
+}
+
+// Delete removes the entry associated with the given key.
+// If the key does not exist, the operation is a no-op.
+func (b *BoltStore) Delete(ctx context.Context, key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Delete([]byte(key))
+	})
+}
+
+// DeleteByPattern removes all entries whose keys match the given glob pattern.
+//
+// Pattern rules:
+//   - Redis-style glob syntax: '*', '?', '[abc]', '[^a-z]' and '\' escapes.
+//
+// Returns ErrInvalidValue if the pattern is malformed.
+//
+// Performance note:
+//   - Patterns of the form "prefix*" are served by an ordered cursor seek
+//     and only visit matching keys.
+//   - Any other pattern visits every key in the bucket.
+func (b *BoltStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	// Fast path: clear all
+	if pattern == "*" {
+		return b.Clear(ctx)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(b.bucket)
+		c := bkt.Cursor()
+
+		var keysToDelete [][]byte
+
+		if prefix, ok := glob.LiteralPrefix(pattern); ok {
+			p := []byte(prefix)
+			for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				keysToDelete = append(keysToDelete, cloneBytes(k))
+			}
+		} else {
+			re, err := glob.Compile(pattern)
+			if err != nil {
+				return omnicache.ErrInvalidValue
+			}
+
+			for k, _ := c.First(); k != nil; k, _ = c.Next() {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if re.Match(k) {
+					keysToDelete = append(keysToDelete, cloneBytes(k))
+				}
+			}
+		}
+
+		// Deleting while iterating a bbolt cursor can skip keys,
+		// so matches are removed after the scan.
+		for _, k := range keysToDelete {
+			if err := bkt.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteMany removes multiple keys in a single transaction.
+// Keys that do not exist are skipped without error.
+func (b *BoltStore) DeleteMany(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bkt := tx.Bucket(b.bucket)
+		for _, key := range keys {
+			if err := bkt.Delete([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get retrieves a value from the cache by key.
+//
+// Behavior:
+//   - If the key does not exist, returns (nil, ErrCacheMiss).
+//   - If the key exists but is expired, deletes it and returns (nil, ErrCacheMiss).
+//   - Otherwise returns the value: strings and []byte values as such,
+//     other values as codec.Raw.
+func (b *BoltStore) Get(ctx context.Context, key string) (any, error) {
+	now := time.Now()
+
+	var value any
+	var expired bool
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(b.bucket).Get([]byte(key))
+		if v == nil {
+			return omnicache.ErrCacheMiss
+		}
+
+		if isExpired(v, now) {
+			expired = true
+			return omnicache.ErrCacheMiss
+		}
+
+		// Decode copies out: bbolt memory is only valid inside the transaction
+		value = storevalue.Decode(v[expirationSize:])
+
+		return nil
+	})
+
+	if expired {
+		// Lazily remove, re-checking in case it was overwritten meanwhile
+		_ = b.db.Update(func(tx *bolt.Tx) error {
+			bkt := tx.Bucket(b.bucket)
+			if v := bkt.Get([]byte(key)); v != nil && isExpired(v, now) {
+				return bkt.Delete([]byte(key))
+			}
+			return nil
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Has checks whether a key exists and is not expired.
+func (b *BoltStore) Has(ctx context.Context, key string) (bool, error) {
+	var exists bool
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(b.bucket).Get([]byte(key))
+		exists = v != nil && !isExpired(v, time.Now())
+		return nil
+	})
+
+	return exists, err
+}
+
+// Ping checks that the database is open and its directory is writable.
+func (b *BoltStore) Ping(ctx context.Context) error {
+	if err := b.db.View(func(tx *bolt.Tx) error { return nil }); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(b.db.Path()), ".omnicache-ping-*")
+	if err != nil {
+		return err
+	}
+
+	closeErr := tmp.Close()
+	if err := os.Remove(tmp.Name()); err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// Set stores a value in the cache with the given key.
+//
+// Behavior:
+//   - TTL > 0: entry expires after duration
+//   - TTL = 0: entry never expires
+//   - TTL < 0: returns ErrInvalidValue
+//
+// Strings and []byte values are stored as-is, other values are encoded
+// with the store's codec, together with the expiration.
+func (b *BoltStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	data, err := storevalue.Encode(b.codec, value)
+	if err != nil {
+		return err
+	}
+
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(key), encodeEntry(expiration, data))
+	})
+}
+
+// encodeEntry prefixes value with its expiration time.
+func encodeEntry(expiration time.Time, value []byte) []byte {
+	buf := make([]byte, expirationSize+len(value))
+
+	if !expiration.IsZero() {
+		binary.BigEndian.PutUint64(buf, uint64(expiration.UnixNano()))
+	}
+	copy(buf[expirationSize:], value)
+
+	return buf
+}
+
+// isExpired reports whether the stored entry has expired at the given time.
+// Malformed entries are reported as expired so that they get purged.
+func isExpired(entry []byte, now time.Time) bool {
+	if len(entry) < expirationSize {
+		return true
+	}
+
+	expiresAt := int64(binary.BigEndian.Uint64(entry))
+
+	return expiresAt != 0 && now.UnixNano() > expiresAt
+}
+
+func cloneBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	copy(out, b)
+	return out
+}
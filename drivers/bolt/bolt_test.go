@@ -0,0 +1,656 @@
+package boltstore
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+// newTestStore opens a BoltStore in a temporary directory without
+// starting the background cleanup goroutine.
+func newTestStore(t *testing.T) *BoltStore {
+	t.Helper()
+
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), DefaultFileMode, nil)
+	assert.NoError(t, err, "expected no error when opening database")
+	t.Cleanup(func() { db.Close() })
+
+	store := &BoltStore{db: db, bucket: []byte(DefaultBucket), batchSize: DefaultCleanupBatchSize, logger: slog.Default()}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(store.bucket)
+		return err
+	})
+	assert.NoError(t, err, "expected no error when creating bucket")
+
+	return store
+}
+
+// putEntry writes a raw entry directly into the bucket.
+func putEntry(t *testing.T, b *BoltStore, key, value string, expiration time.Time) {
+	t.Helper()
+
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(key), encodeEntry(expiration, []byte(value)))
+	})
+	assert.NoError(t, err, "expected no error when writing test entry")
+}
+
+// keys returns all keys currently in the bucket, including expired ones.
+func keys(t *testing.T, b *BoltStore) []string {
+	t.Helper()
+
+	var out []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).ForEach(func(k, _ []byte) error {
+			out = append(out, string(k))
+			return nil
+		})
+	})
+	assert.NoError(t, err, "expected no error when listing keys")
+
+	return out
+}
+
+func TestBoltStore_NewBoltStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		config         func(dir string) BoltConfig
+		expectedBucket string
+		expectedErr    error
+	}{
+		{
+			name: "should open store with default bucket",
+			config: func(dir string) BoltConfig {
+				return BoltConfig{Path: filepath.Join(dir, "cache.db")}
+			},
+			expectedBucket: DefaultBucket,
+		},
+		{
+			name: "should open store with custom bucket",
+			config: func(dir string) BoltConfig {
+				return BoltConfig{Path: filepath.Join(dir, "cache.db"), Bucket: "sessions", NoSync: true}
+			},
+			expectedBucket: "sessions",
+		},
+		{
+			name: "should return ErrInvalidConfig when Path is empty",
+			config: func(dir string) BoltConfig {
+				return BoltConfig{}
+			},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewBoltStore(tt.config(t.TempDir()))
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, store, "store must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating store")
+
+			boltStore, ok := store.(*BoltStore)
+			assert.True(t, ok, "expected store to be of type *BoltStore")
+			assert.Equal(t, tt.expectedBucket, string(boltStore.bucket), "bucket must match")
+			assert.True(t, boltStore.ownsDB, "expected store to own the database")
+
+			assert.NoError(t, store.Close(context.Background()))
+		})
+	}
+}
+
+func TestBoltStore_NewBoltWithDB(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should wrap existing database without taking ownership", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		db, err := bolt.Open(filepath.Join(t.TempDir(), "cache.db"), DefaultFileMode, nil)
+		assert.NoError(t, err)
+		defer db.Close()
+
+		// --- Act ---
+		store, err := NewBoltWithDB(db, BoltConfig{})
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when creating store")
+		assert.False(t, store.(*BoltStore).ownsDB, "expected store not to own the database")
+		assert.NoError(t, store.Close(context.Background()))
+
+		// Database must still be usable after the store is closed
+		err = db.View(func(tx *bolt.Tx) error { return nil })
+		assert.NoError(t, err, "expected database to remain open")
+	})
+
+	t.Run("should return ErrInvalidConfig when db is nil", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		store, err := NewBoltWithDB(nil, BoltConfig{})
+
+		// --- Assert ---
+		assert.EqualError(t, omnicache.ErrInvalidConfig, err)
+		assert.Nil(t, store)
+	})
+}
+
+func TestBoltStore_Close(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should stop cleanup goroutine and close database", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, err := NewBoltStore(BoltConfig{
+			Path:            filepath.Join(t.TempDir(), "cache.db"),
+			CleanupInterval: 10 * time.Millisecond,
+		})
+		assert.NoError(t, err)
+
+		boltStore := store.(*BoltStore)
+
+		// --- Act ---
+		err = boltStore.Close(context.Background())
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when calling Close")
+
+		select {
+		case <-boltStore.doneCh:
+			// success — cleanup goroutine stopped
+		default:
+			t.Fatal("expected cleanup goroutine to have stopped")
+		}
+
+		err = boltStore.Close(context.Background())
+		assert.NoError(t, err, "expected no error when calling Close multiple times")
+	})
+
+	t.Run("should persist entries across reopen", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		cfg := BoltConfig{Path: filepath.Join(t.TempDir(), "cache.db")}
+
+		store, err := NewBoltStore(cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, store.Set(ctx, "key", "value", time.Hour))
+		assert.NoError(t, store.Close(ctx))
+
+		// --- Act ---
+		reopened, err := NewBoltStore(cfg)
+		assert.NoError(t, err)
+		defer reopened.Close(ctx)
+
+		val, err := reopened.Get(ctx, "key")
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected value to survive restart")
+		assert.Equal(t, "value", val)
+	})
+}
+
+func TestBoltStore_cleanupExpiredKeys(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putEntry(t, store, "expired", `"a"`, time.Now().Add(-time.Minute))
+	putEntry(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+	store.doneCh = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// --- Act ---
+	go store.cleanupExpiredKeys(ctx, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-store.doneCh
+
+	// --- Assert ---
+	assert.Equal(t, []string{"valid"}, keys(t, store), "expected only the valid key to remain")
+}
+
+func TestBoltStore_deleteExpiredKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		batchSize    int
+		expectedKeys []string
+	}{
+		{
+			name:         "should remove all expired keys in a single batch",
+			batchSize:    DefaultCleanupBatchSize,
+			expectedKeys: []string{"forever", "valid"},
+		},
+		{
+			name:         "should remove all expired keys across many small batches",
+			batchSize:    1,
+			expectedKeys: []string{"forever", "valid"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			store.batchSize = tt.batchSize
+			for i := 0; i < 5; i++ {
+				putEntry(t, store, fmt.Sprintf("expired:%d", i), `"x"`, time.Now().Add(-time.Minute))
+			}
+			putEntry(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+			putEntry(t, store, "forever", `"c"`, time.Time{})
+
+			// --- Act ---
+			err := store.deleteExpiredKeys(context.Background())
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when purging expired keys")
+			assert.Equal(t, tt.expectedKeys, keys(t, store), "remaining keys must match")
+		})
+	}
+}
+
+func TestBoltStore_Clear(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putEntry(t, store, "user:1", `"John"`, time.Time{})
+	putEntry(t, store, "user:2", `"Jane"`, time.Time{})
+
+	// --- Act ---
+	err := store.Clear(context.Background())
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when clearing store")
+	assert.Empty(t, keys(t, store), "expected store to be empty after Clear()")
+}
+
+func TestBoltStore_Delete(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+	putEntry(t, store, "key", `"value"`, time.Time{})
+
+	// --- Act ---
+	err := store.Delete(ctx, "key")
+	errMissing := store.Delete(ctx, "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting existing key")
+	assert.NoError(t, errMissing, "expected no error when deleting missing key")
+	assert.Empty(t, keys(t, store), "expected key to be removed")
+}
+
+func TestBoltStore_DeleteByPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		keys                  []string
+		pattern               string
+		cancelCtx             bool
+		expectedRemainingKeys []string
+		expectedErr           error
+	}{
+		{
+			name:                  "should delete keys using prefix scan when pattern is prefix*",
+			keys:                  []string{"user:1", "user:2", "users", "product:1"},
+			pattern:               "user:*",
+			expectedRemainingKeys: []string{"product:1", "users"},
+		},
+		{
+			name:                  "should delete all keys when using global '*' pattern",
+			keys:                  []string{"user:1", "product:1"},
+			pattern:               "*",
+			expectedRemainingKeys: []string{},
+		},
+		{
+			name:                  "should delete keys matching a general glob",
+			keys:                  []string{"user:1:profile", "user:2:profile", "user:1:settings"},
+			pattern:               "user:*:profile",
+			expectedRemainingKeys: []string{"user:1:settings"},
+		},
+		{
+			name:                  "should delete exact key when pattern has no wildcard",
+			keys:                  []string{"exact", "exact_suffix"},
+			pattern:               "exact",
+			expectedRemainingKeys: []string{"exact_suffix"},
+		},
+		{
+			name:                  "should do nothing when pattern is empty",
+			keys:                  []string{"user:1"},
+			pattern:               "",
+			expectedRemainingKeys: []string{"user:1"},
+		},
+		{
+			name:                  "should return ErrInvalidValue when pattern is malformed",
+			keys:                  []string{"user:1"},
+			pattern:               "user:[1",
+			expectedRemainingKeys: []string{"user:1"},
+			expectedErr:           omnicache.ErrInvalidValue,
+		},
+		{
+			name:                  "should abort without deleting when context is cancelled",
+			keys:                  []string{"user:1", "user:2"},
+			pattern:               "user:*",
+			cancelCtx:             true,
+			expectedRemainingKeys: []string{"user:1", "user:2"},
+			expectedErr:           context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			for _, key := range tt.keys {
+				putEntry(t, store, key, `"v"`, time.Time{})
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelCtx {
+				cancel()
+			}
+
+			// --- Act ---
+			err := store.DeleteByPattern(ctx, tt.pattern)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+			} else {
+				assert.NoError(t, err, "expected no error when deleting by pattern")
+			}
+
+			assert.Equal(t, tt.expectedRemainingKeys, keys(t, store), "remaining keys must match")
+		})
+	}
+}
+
+func TestBoltStore_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putEntry(t, store, "key1", `"a"`, time.Time{})
+	putEntry(t, store, "key2", `"b"`, time.Time{})
+	putEntry(t, store, "key3", `"c"`, time.Time{})
+
+	// --- Act ---
+	err := store.DeleteMany(context.Background(), "key1", "key2", "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting many keys")
+	assert.Equal(t, []string{"key3"}, keys(t, store), "expected only key3 to remain")
+}
+
+func TestBoltStore_Get(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := codec.Encode(codec.Default, map[string]int{"id": 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		setup        func(t *testing.T, b *BoltStore)
+		expectedVal  any
+		expectedErr  error
+		expectedKeys []string
+	}{
+		{
+			name: "should return value when key exists and is not expired",
+			setup: func(t *testing.T, b *BoltStore) {
+				assert.NoError(t, b.Set(context.Background(), "key", "value", time.Hour))
+			},
+			expectedVal:  "value",
+			expectedKeys: []string{"key"},
+		},
+		{
+			name: "should return other values as codec.Raw when key has no expiration",
+			setup: func(t *testing.T, b *BoltStore) {
+				assert.NoError(t, b.Set(context.Background(), "key", map[string]int{"id": 1}, 0))
+			},
+			expectedVal:  codec.Raw(encoded),
+			expectedKeys: []string{"key"},
+		},
+		{
+			name:         "should return ErrCacheMiss when key does not exist",
+			setup:        func(t *testing.T, b *BoltStore) {},
+			expectedErr:  omnicache.ErrCacheMiss,
+			expectedKeys: []string{},
+		},
+		{
+			name: "should return ErrCacheMiss and remove key when expired",
+			setup: func(t *testing.T, b *BoltStore) {
+				putEntry(t, b, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expectedErr:  omnicache.ErrCacheMiss,
+			expectedKeys: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			tt.setup(t, store)
+
+			// --- Act ---
+			val, err := store.Get(context.Background(), "key")
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedKeys, keys(t, store), "stored keys must match after Get")
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, val, "value must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when getting key")
+			assert.Equal(t, tt.expectedVal, val, "value must match the stored value")
+		})
+	}
+}
+
+func TestBoltStore_Has(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, b *BoltStore)
+		expected bool
+	}{
+		{
+			name: "should return true when key exists and is not expired",
+			setup: func(t *testing.T, b *BoltStore) {
+				putEntry(t, b, "key", `"value"`, time.Now().Add(time.Hour))
+			},
+			expected: true,
+		},
+		{
+			name:     "should return false when key does not exist",
+			setup:    func(t *testing.T, b *BoltStore) {},
+			expected: false,
+		},
+		{
+			name: "should return false when key is expired",
+			setup: func(t *testing.T, b *BoltStore) {
+				putEntry(t, b, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			tt.setup(t, store)
+
+			// --- Act ---
+			ok, err := store.Has(context.Background(), "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when checking key")
+			assert.Equal(t, tt.expected, ok, "existence must match the expected value")
+		})
+	}
+}
+
+func TestBoltStore_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		value       any
+		ttl         time.Duration
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name:        "should store string value as is",
+			value:       "hello",
+			ttl:         time.Minute,
+			expectedVal: "hello",
+		},
+		{
+			name:        "should store struct value with the codec without expiration",
+			value:       map[string]int{"id": 1},
+			ttl:         0,
+			expectedVal: codec.Raw("\x01{\"id\":1}"),
+		},
+		{
+			name:        "should return ErrInvalidValue when ttl is negative",
+			value:       "hello",
+			ttl:         -time.Second,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			// --- Act ---
+			err := store.Set(ctx, "key", tt.value, tt.ttl)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Empty(t, keys(t, store), "expected nothing to be written")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when setting key")
+
+			val, err := store.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVal, val, "stored value must round-trip")
+		})
+	}
+}
+
+func TestBoltStore_codec(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id" cbor:"id"`
+		Name string `json:"name" cbor:"name"`
+	}
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+	assert.NoError(t, store.Set(ctx, "before", user{ID: 1, Name: "a"}, 0), "expected no error when setting key")
+	store.codec = codec.CBOR
+
+	// --- Act ---
+	err := store.Set(ctx, "after", user{ID: 2, Name: "b"}, 0)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when setting key")
+
+	after, err := store.Get(ctx, "after")
+	assert.NoError(t, err, "expected no error when getting key")
+	raw, ok := after.(codec.Raw)
+	assert.True(t, ok, "expected the value to be returned as codec.Raw")
+	assert.Equal(t, codec.IDCBOR, raw[0], "expected the value to be encoded with the configured codec")
+
+	var got user
+	assert.NoError(t, codec.Decode(raw, nil, &got), "expected the value to decode")
+	assert.Equal(t, user{ID: 2, Name: "b"}, got)
+
+	before, err := store.Get(ctx, "before")
+	assert.NoError(t, err, "expected no error when getting key")
+	assert.NoError(t, codec.Decode(before.(codec.Raw), nil, &got), "expected values of the previous codec to remain readable")
+	assert.Equal(t, user{ID: 1, Name: "a"}, got)
+
+}
+
+func TestBoltStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// --- Act ---
+	errOpen := store.Ping(ctx)
+	assert.NoError(t, store.db.Close())
+	errClosed := store.Ping(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, errOpen, "expected no error while the database is open")
+	assert.Error(t, errClosed, "expected an error once the database is closed")
+}
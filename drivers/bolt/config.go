@@ -0,0 +1,69 @@
+package boltstore
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+type BoltConfig struct {
+	// Path is the database file path. It is created if it does not exist.
+	// Required.
+	Path string
+
+	// Bucket is the name of the bucket holding cache entries.
+	//
+	// default: "omnicache"
+	Bucket string
+
+	// FileMode is the permission used when creating the database file.
+	//
+	// default: 0o600
+	FileMode os.FileMode
+
+	// OpenTimeout is how long to wait for the file lock when another
+	// process holds the database open. 0 waits indefinitely.
+	//
+	// default: 1 second
+	OpenTimeout time.Duration
+
+	// CleanupInterval is how often expired entries are purged in the background.
+	//
+	// default: 10 minutes
+	CleanupInterval time.Duration
+
+	// CleanupBatchSize is the maximum number of expired keys removed per
+	// write transaction during background cleanup, so that long purges
+	// don't block writers.
+	//
+	// default: 1000
+	CleanupBatchSize int
+
+	// NoSync skips fsync after each commit. Faster, but recent writes
+	// may be lost on power failure.
+	//
+	// default: false
+	NoSync bool
+
+	// Logger receives errors of the background cleanup.
+	//
+	// default: slog.Default()
+	Logger *slog.Logger
+
+	// Codec serializes values other than strings and []byte values.
+	// Values written with another registered codec remain readable, which
+	// allows switching codecs on a live cache.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
+
+const (
+	DefaultBucket           = "omnicache"
+	DefaultFileMode         = os.FileMode(0o600)
+	DefaultOpenTimeout      = 1 * time.Second
+	DefaultCleanupInterval  = 10 * time.Minute
+	DefaultCleanupBatchSize = 1000
+)
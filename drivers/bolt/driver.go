@@ -0,0 +1,25 @@
+package boltstore
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the bolt driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "bolt"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of BoltConfig.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config BoltConfig
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return NewBoltStore(config)
+}
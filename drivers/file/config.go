@@ -0,0 +1,55 @@
+package filestore
+
+import (
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+type FileConfig struct {
+	// Dir is the root directory under which cache entries are stored.
+	// It is created if it does not exist. Required.
+	Dir string
+
+	// MaxSize is the maximum total size in bytes of all cache files.
+	// When exceeded, the least recently accessed entries are removed.
+	//
+	// default: 0 (no limit)
+	MaxSize int64
+
+	// CleanupInterval is how often expired entries are removed and the
+	// size limit is enforced in the background.
+	//
+	// default: 10 minutes
+	CleanupInterval time.Duration
+
+	// DirPerm is the permission used when creating directories.
+	//
+	// default: 0o755
+	DirPerm os.FileMode
+
+	// FilePerm is the permission used when creating cache files.
+	//
+	// default: 0o644
+	FilePerm os.FileMode
+
+	// Logger receives errors of the background cleanup.
+	//
+	// default: slog.Default()
+	Logger *slog.Logger
+
+	// Codec serializes values other than strings and []byte values.
+	// Values written with another registered codec remain readable, which
+	// allows switching codecs on a live cache.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
+
+const (
+	DefaultCleanupInterval = 10 * time.Minute
+	DefaultDirPerm         = os.FileMode(0o755)
+	DefaultFilePerm        = os.FileMode(0o644)
+)
@@ -0,0 +1,25 @@
+package filestore
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the file driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "file"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of FileConfig.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config FileConfig
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return NewFileStore(config)
+}
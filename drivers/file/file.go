@@ -0,0 +1,764 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/glob"
+	"github.com/shoraid/omnicache/internal/storevalue"
+)
+
+const (
+	// headerMagic identifies files written by this driver.
+	headerMagic = "OMFC"
+
+	// headerVersion is the current on-disk format version.
+	headerVersion byte = 1
+
+	// headerVersionRaw marks entries written by SetFromReader, whose value
+	// holds raw bytes instead of JSON.
+	headerVersionRaw byte = 2
+
+	// headerSize is the fixed part of the header:
+	// magic (4) + version (1) + expiration (8) + key length (4).
+	headerSize = len(headerMagic) + 1 + 8 + 4
+
+	// tempPrefix marks files that are still being written.
+	// They are ignored by reads and scans.
+	tempPrefix = ".tmp-"
+
+	// staleTempAge is how old a temporary file must be before the
+	// background cleanup assumes its writer crashed and removes it.
+	staleTempAge = time.Hour
+)
+
+var errCorruptEntry = errors.New("filestore: corrupt cache entry")
+
+type FileStore struct {
+	dir           string
+	maxSize       int64
+	dirPerm       os.FileMode
+	filePerm      os.FileMode
+	logger        *slog.Logger
+	codec         codec.Codec
+	size          int64  // approximate total size in bytes, accessed atomically
+	evictions     uint64 // entries removed to enforce MaxSize, accessed atomically
+	evictMu       sync.Mutex
+	cancelCleanup context.CancelFunc
+	doneCh        chan struct{}
+}
+
+type fileHeader struct {
+	key        string
+	expiration time.Time
+	raw        bool
+}
+
+type fileEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// NewFileStore creates a new file-system cache store rooted at config.Dir.
+// Each entry is kept in its own file under hashed sub-directories, written
+// atomically (write to a temporary file, then rename) so several processes
+// can safely share the same directory.
+// It starts a background goroutine to periodically remove expired entries
+// and enforce MaxSize. Returns ErrInvalidConfig if Dir is empty.
+func NewFileStore(config FileConfig) (contract.Store, error) {
+	if config.Dir == "" {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	store := &FileStore{
+		dir:      config.Dir,
+		maxSize:  config.MaxSize,
+		dirPerm:  DefaultDirPerm,
+		filePerm: DefaultFilePerm,
+		logger:   config.Logger,
+		codec:    config.Codec,
+		doneCh:   make(chan struct{}),
+	}
+
+	if store.logger == nil {
+		store.logger = slog.Default()
+	}
+
+	if store.codec == nil {
+		store.codec = codec.Default
+	}
+
+	if config.DirPerm != 0 {
+		store.dirPerm = config.DirPerm
+	}
+
+	if config.FilePerm != 0 {
+		store.filePerm = config.FilePerm
+	}
+
+	if err := os.MkdirAll(store.dir, store.dirPerm); err != nil {
+		return nil, err
+	}
+
+	// Seed the size counter from whatever is already on disk
+	if store.maxSize > 0 {
+		var total int64
+		err := store.walkEntries(context.Background(), func(e fileEntry) error {
+			total += e.size
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		atomic.StoreInt64(&store.size, total)
+	}
+
+	// Set the cleanup interval from config, or use default
+	cleanupInterval := DefaultCleanupInterval
+	if config.CleanupInterval > 0 {
+		cleanupInterval = config.CleanupInterval
+	}
+
+	// Start the background goroutine for cleaning up expired entries
+	ctx, cancel := context.WithCancel(context.Background())
+	store.cancelCleanup = cancel
+
+	go store.cleanupExpiredEntries(ctx, cleanupInterval)
+
+	return store, nil
+}
+
+// cleanupExpiredEntries runs in a background goroutine to remove expired
+// entries and enforce the size limit at regular intervals.
+func (f *FileStore) cleanupExpiredEntries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() {
+		// Notify that the cleanup goroutine has exited.
+		// This is used for deterministic shutdowns in tests.
+		if f.doneCh != nil {
+			close(f.doneCh)
+		}
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.deleteExpiredEntries(ctx)
+			f.enforceMaxSize(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deleteExpiredEntries removes all expired entries and stale temporary
+// files left behind by writers that crashed mid-write. Directories that
+// cannot be read are skipped and logged.
+func (f *FileStore) deleteExpiredEntries(ctx context.Context) {
+	now := time.Now()
+
+	var walkErrs []error
+	err := filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories may disappear while other processes clean up.
+			// Others that cannot be read are skipped and reported below.
+			if !errors.Is(err, fs.ErrNotExist) {
+				walkErrs = append(walkErrs, err)
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if strings.HasPrefix(d.Name(), tempPrefix) {
+			if info, err := d.Info(); err == nil && now.Sub(info.ModTime()) > staleTempAge {
+				_ = os.Remove(path)
+			}
+			return nil
+		}
+
+		header, err := readHeader(path)
+		if errors.Is(err, errCorruptEntry) || (err == nil && header.isExpired(now)) {
+			f.removeFile(path)
+		}
+
+		return nil
+	})
+	if err == nil {
+		err = errors.Join(walkErrs...)
+	}
+	if err != nil && ctx.Err() == nil {
+		f.logger.Warn("omnicache: deleting expired entries failed", slog.String("driver", "file"), slog.Any("error", err))
+	}
+}
+
+// enforceMaxSize removes expired entries first and then the least recently
+// accessed ones until the total size drops to MaxSize. Only the latter are
+// counted as evictions. Access time is
+// tracked through the file modification time, which Get refreshes.
+func (f *FileStore) enforceMaxSize(ctx context.Context) {
+	if f.maxSize <= 0 {
+		return
+	}
+
+	f.evictMu.Lock()
+	defer f.evictMu.Unlock()
+
+	var entries []fileEntry
+	var total int64
+	err := f.walkEntries(ctx, func(e fileEntry) error {
+		entries = append(entries, e)
+		total += e.size
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			f.logger.Warn("omnicache: enforcing MaxSize failed", slog.String("driver", "file"), slog.Any("error", err))
+		}
+		return
+	}
+
+	if total > f.maxSize {
+		// Expired and corrupt entries go first; they are not evictions
+		now := time.Now()
+		live := entries[:0]
+		for _, e := range entries {
+			header, err := readHeader(e.path)
+			if errors.Is(err, errCorruptEntry) || (err == nil && header.isExpired(now)) {
+				if err := os.Remove(e.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+					total -= e.size
+					continue
+				}
+			}
+			live = append(live, e)
+		}
+		entries = live
+
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].modTime.Before(entries[j].modTime)
+		})
+
+		for _, e := range entries {
+			if total <= f.maxSize {
+				break
+			}
+			if err := os.Remove(e.path); err == nil {
+				total -= e.size
+				atomic.AddUint64(&f.evictions, 1)
+			} else if errors.Is(err, fs.ErrNotExist) {
+				total -= e.size
+			}
+		}
+	}
+
+	atomic.StoreInt64(&f.size, total)
+}
+
+// Clear removes all entries from the cache directory.
+// Files that are still being written by other processes are left alone.
+func (f *FileStore) Clear(ctx context.Context) error {
+	err := f.walkEntries(ctx, func(e fileEntry) error {
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+
+	atomic.StoreInt64(&f.size, 0)
+
+	return err
+}
+
+// Close stops the background cleanup goroutine.
+// It is safe to call Close multiple times.
+func (f *FileStore) Close(ctx context.Context) error {
+	if f.cancelCleanup != nil {
+		f.cancelCleanup()
+		f.cancelCleanup = nil // Prevent calling cancel multiple times
+	}
+
+	return nil
+}
+
+// Evictions returns the number of entries removed to enforce MaxSize.
+func (f *FileStore) Evictions() uint64 {
+	return atomic.LoadUint64(&f.evictions)
+}
+
+// Delete removes the entry associated with the given key.
+// If the key does not exist, the operation is a no-op.
+func (f *FileStore) Delete(ctx context.Context, key string) error {
+	return f.removeFile(f.pathFor(key))
+}
+
+// DeleteByPattern removes all entries whose keys match the given glob pattern.
+//
+// Pattern rules:
+//   - Redis-style glob syntax: '*', '?', '[abc]', '[^a-z]' and '\' escapes.
+//   - '*' also matches '/', so "build/*" covers nested keys.
+//
+// Returns ErrInvalidValue if the pattern is malformed.
+//
+// Performance note:
+//   - Keys are stored hashed, so every entry header has to be read to find
+//     matches. Use sparingly on large caches.
+func (f *FileStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	// Fast path: clear all
+	if pattern == "*" {
+		return f.Clear(ctx)
+	}
+
+	re, err := glob.Compile(pattern)
+	if err != nil {
+		return omnicache.ErrInvalidValue
+	}
+
+	return f.walkEntries(ctx, func(e fileEntry) error {
+		header, err := readHeader(e.path)
+		if err != nil {
+			return nil
+		}
+
+		if re.MatchString(header.key) {
+			return f.removeFile(e.path)
+		}
+
+		return nil
+	})
+}
+
+// DeleteMany removes multiple keys from the cache.
+// Keys that do not exist are skipped without error.
+func (f *FileStore) DeleteMany(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := f.removeFile(f.pathFor(key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get retrieves a value from the cache by key.
+//
+// Behavior:
+//   - If the key does not exist, returns (nil, ErrCacheMiss).
+//   - If the entry is expired or unreadable, removes it and returns (nil, ErrCacheMiss).
+//   - Otherwise returns the value and refreshes the entry's access time
+//     used for size-based eviction. Strings and []byte values, including
+//     those written by SetFromReader, are returned as such; other values
+//     are returned as codec.Raw.
+func (f *FileStore) Get(ctx context.Context, key string) (any, error) {
+	path := f.pathFor(key)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, omnicache.ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+
+	header, value, err := decodeEntry(data)
+	if err != nil || header.key != key {
+		return nil, omnicache.ErrCacheMiss
+	}
+
+	now := time.Now()
+	if header.isExpired(now) {
+		f.removeFile(path)
+		return nil, omnicache.ErrCacheMiss
+	}
+
+	// Best-effort: mark as recently used for LRU eviction
+	_ = os.Chtimes(path, now, now)
+
+	if header.raw {
+		return value, nil
+	}
+
+	return storevalue.Decode(value), nil
+}
+
+// GetReader returns a reader over the []byte or string value stored under
+// key. The caller must close it.
+//
+// The value is streamed from disk.
+// Returns ErrCacheMiss if the key is missing or expired, and
+// ErrTypeMismatch if the value is neither a []byte nor a string.
+func (f *FileStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	path := f.pathFor(key)
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, omnicache.ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+
+	// decodeHeader leaves the file positioned at the start of the value
+	header, _, err := decodeFileHeader(file)
+	if err != nil || header.key != key {
+		file.Close()
+		return nil, omnicache.ErrCacheMiss
+	}
+
+	now := time.Now()
+	if header.isExpired(now) {
+		file.Close()
+		f.removeFile(path)
+		return nil, omnicache.ErrCacheMiss
+	}
+
+	// Best-effort: mark as recently used for LRU eviction
+	_ = os.Chtimes(path, now, now)
+
+	if header.raw {
+		return file, nil
+	}
+
+	// A []byte or string value leaves the file positioned at its bytes
+	var marker [1]byte
+	_, err = io.ReadFull(file, marker[:])
+	if err == nil && storevalue.HoldsBytes(marker[0]) {
+		return file, nil
+	}
+
+	file.Close()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return nil, omnicache.ErrTypeMismatch
+}
+
+// Has checks whether a key exists and is not expired.
+// Only the entry header is read.
+func (f *FileStore) Has(ctx context.Context, key string) (bool, error) {
+	header, err := readHeader(f.pathFor(key))
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errCorruptEntry) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if header.key != key || header.isExpired(time.Now()) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// Ping checks that the cache directory is writable by creating and
+// removing a temporary file in it.
+func (f *FileStore) Ping(ctx context.Context) error {
+	tmp, err := os.CreateTemp(f.dir, tempPrefix+"ping-*")
+	if err != nil {
+		return err
+	}
+
+	closeErr := tmp.Close()
+	if err := os.Remove(tmp.Name()); err != nil {
+		return err
+	}
+
+	return closeErr
+}
+
+// Set stores a value in the cache with the given key.
+//
+// Behavior:
+//   - TTL > 0: entry expires after duration
+//   - TTL = 0: entry never expires
+//   - TTL < 0: returns ErrInvalidValue
+//
+// Strings and []byte values are stored as-is, other values are encoded
+// with the store's codec. The entry is written atomically. If MaxSize is set
+// and exceeded, least recently accessed entries are evicted.
+func (f *FileStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	data, err := storevalue.Encode(f.codec, value)
+	if err != nil {
+		return err
+	}
+
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl)
+	}
+
+	if err := f.writeFile(f.pathFor(key), encodeEntry(key, expiration, data)); err != nil {
+		return err
+	}
+
+	if f.maxSize > 0 && atomic.LoadInt64(&f.size) > f.maxSize {
+		f.enforceMaxSize(ctx)
+	}
+
+	return nil
+}
+
+// SetFromReader streams r into the entry for key, so the value is never
+// held in memory as a whole. Like Set, the entry is written atomically:
+// if reading r fails, the previous value is left untouched.
+func (f *FileStore) SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	var expiration time.Time
+	if ttl > 0 {
+		expiration = time.Now().Add(ttl)
+	}
+
+	header := encodeHeader(fileHeader{key: key, expiration: expiration, raw: true})
+	if err := f.writeFileFrom(f.pathFor(key), io.MultiReader(bytes.NewReader(header), r)); err != nil {
+		return err
+	}
+
+	if f.maxSize > 0 && atomic.LoadInt64(&f.size) > f.maxSize {
+		f.enforceMaxSize(ctx)
+	}
+
+	return nil
+}
+
+// pathFor returns the file path for a key. Keys are hashed so that any
+// string is a valid file name, and spread over two directory levels to
+// keep directories small.
+func (f *FileStore) pathFor(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(f.dir, name[:2], name[2:4], name)
+}
+
+// writeFile atomically replaces the file at path with data.
+func (f *FileStore) writeFile(path string, data []byte) error {
+	return f.writeFileFrom(path, bytes.NewReader(data))
+}
+
+// writeFileFrom atomically replaces the file at path with everything read from r.
+func (f *FileStore) writeFileFrom(path string, r io.Reader) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, f.dirPerm); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := tmp.Name()
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmp.Chmod(f.filePerm); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	var oldSize int64
+	if info, err := os.Stat(path); err == nil {
+		oldSize = info.Size()
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	atomic.AddInt64(&f.size, n-oldSize)
+
+	return nil
+}
+
+// removeFile deletes the file at path, ignoring missing files.
+func (f *FileStore) removeFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	atomic.AddInt64(&f.size, -info.Size())
+
+	return nil
+}
+
+// walkEntries calls fn for every cache file under the root directory,
+// skipping temporary files. It stops early if ctx is cancelled.
+func (f *FileStore) walkEntries(ctx context.Context, fn func(e fileEntry) error) error {
+	return filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Directories may disappear while other processes clean up
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempPrefix) {
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return fn(fileEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+	})
+}
+
+// isExpired reports whether the entry has expired at the given time.
+func (h fileHeader) isExpired(now time.Time) bool {
+	return !h.expiration.IsZero() && now.After(h.expiration)
+}
+
+// encodeEntry serializes the header and value into the on-disk format.
+func encodeEntry(key string, expiration time.Time, value []byte) []byte {
+	buf := encodeHeader(fileHeader{key: key, expiration: expiration})
+
+	return append(buf, value...)
+}
+
+// encodeHeader serializes a header into the on-disk format.
+func encodeHeader(header fileHeader) []byte {
+	buf := make([]byte, headerSize, headerSize+len(header.key))
+	copy(buf, headerMagic)
+	buf[len(headerMagic)] = headerVersion
+	if header.raw {
+		buf[len(headerMagic)] = headerVersionRaw
+	}
+
+	var expiresAt int64
+	if !header.expiration.IsZero() {
+		expiresAt = header.expiration.UnixNano()
+	}
+
+	binary.BigEndian.PutUint64(buf[len(headerMagic)+1:], uint64(expiresAt))
+	binary.BigEndian.PutUint32(buf[len(headerMagic)+9:], uint32(len(header.key)))
+
+	return append(buf, header.key...)
+}
+
+// decodeEntry parses a full cache file into its header and value.
+func decodeEntry(data []byte) (fileHeader, []byte, error) {
+	header, n, err := decodeHeader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fileHeader{}, nil, err
+	}
+
+	return header, data[n:], nil
+}
+
+// readHeader reads only the header of the cache file at path.
+func readHeader(path string) (fileHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return fileHeader{}, err
+	}
+	defer file.Close()
+
+	header, _, err := decodeFileHeader(file)
+
+	return header, err
+}
+
+// decodeFileHeader reads the header of an open cache file.
+func decodeFileHeader(file *os.File) (fileHeader, int, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return fileHeader{}, 0, err
+	}
+
+	return decodeHeader(file, info.Size())
+}
+
+// decodeHeader reads a header from r, an entry of size bytes, and returns
+// it along with the number of bytes consumed.
+func decodeHeader(r io.Reader, size int64) (fileHeader, int, error) {
+	fixed := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return fileHeader{}, 0, errCorruptEntry
+	}
+
+	version := fixed[len(headerMagic)]
+	if string(fixed[:len(headerMagic)]) != headerMagic || (version != headerVersion && version != headerVersionRaw) {
+		return fileHeader{}, 0, errCorruptEntry
+	}
+
+	expiresAt := int64(binary.BigEndian.Uint64(fixed[len(headerMagic)+1:]))
+	keyLen := binary.BigEndian.Uint32(fixed[len(headerMagic)+9:])
+
+	// A corrupt or foreign file must not make us allocate gigabytes
+	if int64(keyLen) > size-int64(headerSize) {
+		return fileHeader{}, 0, errCorruptEntry
+	}
+
+	key := make([]byte, keyLen)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return fileHeader{}, 0, errCorruptEntry
+	}
+
+	header := fileHeader{key: string(key), raw: version == headerVersionRaw}
+	if expiresAt != 0 {
+		header.expiration = time.Unix(0, expiresAt)
+	}
+
+	return header, headerSize + int(keyLen), nil
+}
@@ -0,0 +1,1083 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// newTestStore creates a FileStore in a temporary directory without
+// starting the background cleanup goroutine.
+func newTestStore(t *testing.T, maxSize int64) *FileStore {
+	t.Helper()
+
+	return &FileStore{
+		dir:      t.TempDir(),
+		maxSize:  maxSize,
+		dirPerm:  DefaultDirPerm,
+		filePerm: DefaultFilePerm,
+		logger:   slog.Default(),
+	}
+}
+
+// putEntry writes a raw entry for key directly to disk.
+func putEntry(t *testing.T, f *FileStore, key, value string, expiration time.Time) string {
+	t.Helper()
+
+	path := f.pathFor(key)
+	err := f.writeFile(path, encodeEntry(key, expiration, []byte(value)))
+	assert.NoError(t, err, "expected no error when writing test entry")
+
+	return path
+}
+
+// countEntries returns the number of cache files on disk.
+func countEntries(t *testing.T, f *FileStore) int {
+	t.Helper()
+
+	count := 0
+	err := f.walkEntries(context.Background(), func(e fileEntry) error {
+		count++
+		return nil
+	})
+	assert.NoError(t, err, "expected no error when walking entries")
+
+	return count
+}
+
+func TestFileStore_NewFileStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      func(dir string) FileConfig
+		expectedErr error
+	}{
+		{
+			name: "should create store with defaults when only Dir is provided",
+			config: func(dir string) FileConfig {
+				return FileConfig{Dir: dir}
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "should create missing root directory",
+			config: func(dir string) FileConfig {
+				return FileConfig{Dir: filepath.Join(dir, "nested", "cache"), MaxSize: 1024}
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "should return ErrInvalidConfig when Dir is empty",
+			config: func(dir string) FileConfig {
+				return FileConfig{}
+			},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			cfg := tt.config(t.TempDir())
+
+			// --- Act ---
+			store, err := NewFileStore(cfg)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, store, "store must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating store")
+
+			fileStore, ok := store.(*FileStore)
+			assert.True(t, ok, "expected store to be of type *FileStore")
+			assert.Equal(t, DefaultDirPerm, fileStore.dirPerm, "expected default dir permission")
+			assert.Equal(t, DefaultFilePerm, fileStore.filePerm, "expected default file permission")
+
+			info, err := os.Stat(cfg.Dir)
+			assert.NoError(t, err, "expected root directory to exist")
+			assert.True(t, info.IsDir(), "expected root to be a directory")
+
+			assert.NoError(t, store.Close(context.Background()))
+		})
+	}
+}
+
+func TestFileStore_Close(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should stop cleanup goroutine when Close is called", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, err := NewFileStore(FileConfig{Dir: t.TempDir(), CleanupInterval: 50 * time.Millisecond})
+		assert.NoError(t, err)
+
+		fileStore := store.(*FileStore)
+
+		// --- Act ---
+		err = fileStore.Close(context.Background())
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when calling Close")
+
+		select {
+		case <-fileStore.doneCh:
+			// success — cleanup goroutine stopped
+		case <-time.After(200 * time.Millisecond):
+			t.Fatal("expected cleanup goroutine to stop quickly")
+		}
+
+		err = fileStore.Close(context.Background())
+		assert.NoError(t, err, "expected no error when calling Close multiple times")
+	})
+}
+
+func TestFileStore_cleanupExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t, 0)
+	putEntry(t, store, "expired", `"a"`, time.Now().Add(-time.Minute))
+	putEntry(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// --- Act ---
+	go store.cleanupExpiredEntries(ctx, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	// --- Assert ---
+	assert.Equal(t, 1, countEntries(t, store), "expected only the valid entry to remain")
+}
+
+func TestFileStore_deleteExpiredEntries(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t, 0)
+	putEntry(t, store, "expired", `"a"`, time.Now().Add(-time.Minute))
+	putEntry(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+	putEntry(t, store, "forever", `"c"`, time.Time{})
+
+	corrupt := filepath.Join(store.dir, "zz", "zz", "corrupt")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(corrupt), DefaultDirPerm))
+	assert.NoError(t, os.WriteFile(corrupt, []byte("garbage"), DefaultFilePerm))
+
+	staleTemp := filepath.Join(store.dir, "zz", "zz", tempPrefix+"stale")
+	assert.NoError(t, os.WriteFile(staleTemp, []byte("partial"), DefaultFilePerm))
+	old := time.Now().Add(-2 * staleTempAge)
+	assert.NoError(t, os.Chtimes(staleTemp, old, old))
+
+	freshTemp := filepath.Join(store.dir, "zz", "zz", tempPrefix+"fresh")
+	assert.NoError(t, os.WriteFile(freshTemp, []byte("partial"), DefaultFilePerm))
+
+	// --- Act ---
+	store.deleteExpiredEntries(context.Background())
+
+	// --- Assert ---
+	assert.Equal(t, 2, countEntries(t, store), "expected valid and non-expiring entries to remain")
+
+	_, err := os.Stat(staleTemp)
+	assert.True(t, os.IsNotExist(err), "expected stale temporary file to be removed")
+
+	_, err = os.Stat(freshTemp)
+	assert.NoError(t, err, "expected fresh temporary file to be kept")
+}
+
+func TestFileStore_deleteExpiredEntries_logsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		setup           func(t *testing.T, f *FileStore)
+		expectedRemoved string
+	}{
+		{
+			name: "should log when the cache directory cannot be walked",
+			setup: func(t *testing.T, f *FileStore) {
+				file := filepath.Join(f.dir, "file")
+				assert.NoError(t, os.WriteFile(file, nil, DefaultFilePerm))
+				f.dir = filepath.Join(file, "cache")
+			},
+		},
+		{
+			name: "should log unreadable shard directories and clean up the others",
+			setup: func(t *testing.T, f *FileStore) {
+				if os.Geteuid() == 0 {
+					t.Skip("directory permissions are not enforced for root")
+				}
+
+				putEntry(t, f, "expired", `"a"`, time.Now().Add(-time.Minute))
+
+				shard := filepath.Join(f.dir, "zz")
+				assert.NoError(t, os.MkdirAll(filepath.Join(shard, "zz"), DefaultDirPerm))
+				assert.NoError(t, os.Chmod(shard, 0))
+				t.Cleanup(func() { os.Chmod(shard, DefaultDirPerm) })
+			},
+			expectedRemoved: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			var buf bytes.Buffer
+			store := newTestStore(t, 0)
+			store.logger = slog.New(slog.NewTextHandler(&buf, nil))
+			tt.setup(t, store)
+
+			// --- Act ---
+			store.deleteExpiredEntries(context.Background())
+
+			// --- Assert ---
+			assert.True(t, strings.Contains(buf.String(), "deleting expired entries failed"), "expected the walk error to be logged")
+			if tt.expectedRemoved != "" {
+				_, err := os.Stat(store.pathFor(tt.expectedRemoved))
+				assert.True(t, os.IsNotExist(err), "expected the expired entry to be removed")
+			}
+		})
+	}
+}
+
+func TestFileStore_enforceMaxSize(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t, 0)
+	oldest := putEntry(t, store, "oldest", `"1111111111"`, time.Time{})
+	middle := putEntry(t, store, "middle", `"2222222222"`, time.Time{})
+	newest := putEntry(t, store, "newest", `"3333333333"`, time.Time{})
+
+	now := time.Now()
+	assert.NoError(t, os.Chtimes(oldest, now.Add(-3*time.Minute), now.Add(-3*time.Minute)))
+	assert.NoError(t, os.Chtimes(middle, now.Add(-2*time.Minute), now.Add(-2*time.Minute)))
+	assert.NoError(t, os.Chtimes(newest, now.Add(-1*time.Minute), now.Add(-1*time.Minute)))
+
+	info, err := os.Stat(newest)
+	assert.NoError(t, err)
+	store.maxSize = 2 * info.Size()
+
+	// --- Act ---
+	store.enforceMaxSize(context.Background())
+
+	// --- Assert ---
+	_, err = os.Stat(oldest)
+	assert.True(t, os.IsNotExist(err), "expected least recently used entry to be evicted")
+
+	_, err = os.Stat(middle)
+	assert.NoError(t, err, "expected middle entry to remain")
+
+	_, err = os.Stat(newest)
+	assert.NoError(t, err, "expected newest entry to remain")
+
+	assert.Equal(t, 2*info.Size(), store.size, "expected size counter to be recomputed")
+	assert.Equal(t, uint64(1), store.Evictions(), "expected one eviction to be counted")
+}
+
+func TestFileStore_enforceMaxSize_expiredFirst(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t, 0)
+	oldest := putEntry(t, store, "oldest", `"1111111111"`, time.Time{})
+	expired := putEntry(t, store, "expired", `"2222222222"`, time.Now().Add(-time.Minute))
+	newest := putEntry(t, store, "newest", `"3333333333"`, time.Time{})
+
+	now := time.Now()
+	assert.NoError(t, os.Chtimes(oldest, now.Add(-3*time.Minute), now.Add(-3*time.Minute)))
+	assert.NoError(t, os.Chtimes(expired, now.Add(-1*time.Minute), now.Add(-1*time.Minute)))
+	assert.NoError(t, os.Chtimes(newest, now.Add(-2*time.Minute), now.Add(-2*time.Minute)))
+
+	info, err := os.Stat(newest)
+	assert.NoError(t, err)
+	store.maxSize = 2 * info.Size()
+
+	// --- Act ---
+	store.enforceMaxSize(context.Background())
+
+	// --- Assert ---
+	_, err = os.Stat(expired)
+	assert.True(t, os.IsNotExist(err), "expected the expired entry to be removed")
+
+	_, err = os.Stat(oldest)
+	assert.NoError(t, err, "expected the least recently used live entry to remain")
+
+	_, err = os.Stat(newest)
+	assert.NoError(t, err, "expected newest entry to remain")
+
+	assert.Equal(t, uint64(0), store.Evictions(), "expected expired entries not to be counted as evictions")
+}
+
+func TestFileStore_Clear(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t, 0)
+	putEntry(t, store, "user:1", `"John"`, time.Time{})
+	putEntry(t, store, "user:2", `"Jane"`, time.Time{})
+
+	// --- Act ---
+	err := store.Clear(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when clearing store")
+	assert.Equal(t, 0, countEntries(t, store), "expected store to be empty after Clear()")
+	assert.Equal(t, int64(0), store.size, "expected size counter to be reset")
+}
+
+func TestFileStore_Delete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, f *FileStore)
+	}{
+		{
+			name: "should delete existing key",
+			setup: func(t *testing.T, f *FileStore) {
+				putEntry(t, f, "key", `"value"`, time.Time{})
+			},
+		},
+		{
+			name:  "should not return error when key does not exist",
+			setup: func(t *testing.T, f *FileStore) {},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t, 0)
+			tt.setup(t, store)
+
+			// --- Act ---
+			err := store.Delete(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when deleting key")
+			_, err = os.Stat(store.pathFor("key"))
+			assert.True(t, os.IsNotExist(err), "expected entry file to be removed")
+		})
+	}
+}
+
+func TestFileStore_DeleteByPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		keys                  []string
+		pattern               string
+		expectedRemainingKeys []string
+		expectedErr           error
+	}{
+		{
+			name:                  "should delete matching keys when using prefix pattern",
+			keys:                  []string{"user:1", "user:2", "product:1"},
+			pattern:               "user:*",
+			expectedRemainingKeys: []string{"product:1"},
+		},
+		{
+			name:                  "should delete all keys when using global '*' pattern",
+			keys:                  []string{"user:1", "product:1"},
+			pattern:               "*",
+			expectedRemainingKeys: []string{},
+		},
+		{
+			name:                  "should match keys containing slashes with '*'",
+			keys:                  []string{"build/linux/amd64", "build/darwin/arm64", "src/main"},
+			pattern:               "build/*",
+			expectedRemainingKeys: []string{"src/main"},
+		},
+		{
+			name:                  "should support '?' and character classes",
+			keys:                  []string{"item:1", "item:2", "item:3", "item:10"},
+			pattern:               "item:[12]",
+			expectedRemainingKeys: []string{"item:3", "item:10"},
+		},
+		{
+			name:                  "should do nothing when pattern is empty",
+			keys:                  []string{"user:1"},
+			pattern:               "",
+			expectedRemainingKeys: []string{"user:1"},
+		},
+		{
+			name:                  "should return ErrInvalidValue when pattern is malformed",
+			keys:                  []string{"user:1"},
+			pattern:               "user:[1",
+			expectedRemainingKeys: []string{"user:1"},
+			expectedErr:           omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t, 0)
+			for _, key := range tt.keys {
+				putEntry(t, store, key, `"v"`, time.Time{})
+			}
+
+			// --- Act ---
+			err := store.DeleteByPattern(ctx, tt.pattern)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+			} else {
+				assert.NoError(t, err, "expected no error when deleting by pattern")
+			}
+
+			for _, key := range tt.expectedRemainingKeys {
+				ok, err := store.Has(ctx, key)
+				assert.NoError(t, err)
+				assert.True(t, ok, fmt.Sprintf("expected key %q to remain", key))
+			}
+			assert.Equal(t, len(tt.expectedRemainingKeys), countEntries(t, store), "unexpected number of remaining entries")
+		})
+	}
+
+	t.Run("should stop and return error when context is cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := newTestStore(t, 0)
+		putEntry(t, store, "user:1", `"v"`, time.Time{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// --- Act ---
+		err := store.DeleteByPattern(ctx, "user:*")
+
+		// --- Assert ---
+		assert.EqualError(t, context.Canceled, err, "expected context cancellation error")
+		assert.Equal(t, 1, countEntries(t, store), "expected entry to remain")
+	})
+}
+
+func TestFileStore_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t, 0)
+	putEntry(t, store, "key1", `"a"`, time.Time{})
+	putEntry(t, store, "key2", `"b"`, time.Time{})
+	putEntry(t, store, "key3", `"c"`, time.Time{})
+
+	// --- Act ---
+	err := store.DeleteMany(ctx, "key1", "key2", "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting many keys")
+	assert.Equal(t, 1, countEntries(t, store), "expected only key3 to remain")
+}
+
+func TestFileStore_Get(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := codec.Encode(codec.Default, map[string]int{"id": 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, f *FileStore)
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name: "should return value when key exists and is not expired",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", "value", time.Hour))
+			},
+			expectedVal: "value",
+		},
+		{
+			name: "should return other values as codec.Raw when key has no expiration",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", map[string]int{"id": 1}, 0))
+			},
+			expectedVal: codec.Raw(encoded),
+		},
+		{
+			name:        "should return ErrCacheMiss when key does not exist",
+			setup:       func(t *testing.T, f *FileStore) {},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrCacheMiss and remove file when key is expired",
+			setup: func(t *testing.T, f *FileStore) {
+				putEntry(t, f, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrCacheMiss when the key length exceeds the file",
+			setup: func(t *testing.T, f *FileStore) {
+				header := encodeHeader(fileHeader{key: "key"})
+				binary.BigEndian.PutUint32(header[len(headerMagic)+9:], math.MaxUint32)
+
+				path := f.pathFor("key")
+				_ = os.MkdirAll(filepath.Dir(path), DefaultDirPerm)
+				_ = os.WriteFile(path, header, DefaultFilePerm)
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrCacheMiss when file is corrupt",
+			setup: func(t *testing.T, f *FileStore) {
+				path := f.pathFor("key")
+				_ = os.MkdirAll(filepath.Dir(path), DefaultDirPerm)
+				_ = os.WriteFile(path, []byte("garbage"), DefaultFilePerm)
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t, 0)
+			tt.setup(t, store)
+
+			// --- Act ---
+			val, err := store.Get(ctx, "key")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, val, "value must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when getting key")
+			assert.Equal(t, tt.expectedVal, val, "value must match the stored value")
+		})
+	}
+
+	t.Run("should refresh access time on hit", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := newTestStore(t, 0)
+		path := putEntry(t, store, "key", `"value"`, time.Time{})
+		old := time.Now().Add(-time.Hour)
+		assert.NoError(t, os.Chtimes(path, old, old))
+
+		// --- Act ---
+		_, err := store.Get(context.Background(), "key")
+
+		// --- Assert ---
+		assert.NoError(t, err)
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), info.ModTime(), time.Minute, "expected access time to be refreshed")
+	})
+}
+
+func TestFileStore_Has(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f *FileStore)
+		expected bool
+	}{
+		{
+			name: "should return true when key exists and is not expired",
+			setup: func(t *testing.T, f *FileStore) {
+				putEntry(t, f, "key", `"value"`, time.Now().Add(time.Hour))
+			},
+			expected: true,
+		},
+		{
+			name:     "should return false when key does not exist",
+			setup:    func(t *testing.T, f *FileStore) {},
+			expected: false,
+		},
+		{
+			name: "should return false when key is expired",
+			setup: func(t *testing.T, f *FileStore) {
+				putEntry(t, f, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t, 0)
+			tt.setup(t, store)
+
+			// --- Act ---
+			ok, err := store.Has(context.Background(), "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when checking key")
+			assert.Equal(t, tt.expected, ok, "existence must match the expected value")
+		})
+	}
+}
+
+func TestFileStore_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		value       any
+		ttl         time.Duration
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name:        "should store string value as is",
+			value:       "hello",
+			ttl:         time.Minute,
+			expectedVal: "hello",
+		},
+		{
+			name:        "should store struct value with the codec without expiration",
+			value:       map[string]int{"id": 1},
+			ttl:         0,
+			expectedVal: codec.Raw("\x01{\"id\":1}"),
+		},
+		{
+			name:        "should return ErrInvalidValue when ttl is negative",
+			value:       "hello",
+			ttl:         -time.Second,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t, 0)
+
+			// --- Act ---
+			err := store.Set(ctx, "key", tt.value, tt.ttl)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Equal(t, 0, countEntries(t, store), "expected nothing to be written")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when setting key")
+
+			val, err := store.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVal, val, "stored value must round-trip")
+		})
+	}
+
+	t.Run("should evict least recently used entries when MaxSize is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := newTestStore(t, 0)
+		first := putEntry(t, store, "first", `"xxxxxxxxxx"`, time.Time{})
+		old := time.Now().Add(-time.Hour)
+		assert.NoError(t, os.Chtimes(first, old, old))
+
+		info, err := os.Stat(first)
+		assert.NoError(t, err)
+		store.maxSize = info.Size() + info.Size()/2
+
+		// --- Act ---
+		err = store.Set(ctx, "second", "xxxxxxxxx", 0)
+
+		// --- Assert ---
+		assert.NoError(t, err)
+
+		ok, _ := store.Has(ctx, "first")
+		assert.False(t, ok, "expected oldest entry to be evicted")
+
+		ok, _ = store.Has(ctx, "second")
+		assert.True(t, ok, "expected newest entry to remain")
+	})
+
+	t.Run("should be safe for concurrent writers sharing the same directory", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		dir := t.TempDir()
+
+		storeA, err := NewFileStore(FileConfig{Dir: dir})
+		assert.NoError(t, err)
+		defer storeA.Close(ctx)
+
+		storeB, err := NewFileStore(FileConfig{Dir: dir})
+		assert.NoError(t, err)
+		defer storeB.Close(ctx)
+
+		// --- Act ---
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				_ = storeA.Set(ctx, "shared", fmt.Sprintf("a-%d", i), 0)
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				_ = storeB.Set(ctx, "shared", fmt.Sprintf("b-%d", i), 0)
+			}(i)
+		}
+		wg.Wait()
+
+		// --- Assert ---
+		val, err := storeA.Get(ctx, "shared")
+		assert.NoError(t, err, "expected a complete value to be readable")
+		assert.NotNil(t, val)
+
+		val2, err := storeB.Get(ctx, "shared")
+		assert.NoError(t, err)
+		assert.Equal(t, val, val2, "both stores must see the same value")
+	})
+}
+
+func TestFileStore_GetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, f *FileStore)
+		expectedVal string
+		expectedErr error
+	}{
+		{
+			name: "should stream values written by SetFromReader",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.SetFromReader(context.Background(), "key", strings.NewReader("raw content"), 0))
+			},
+			expectedVal: "raw content",
+		},
+		{
+			name: "should stream []byte values written by Set",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", []byte("bytes"), 0))
+			},
+			expectedVal: "bytes",
+		},
+		{
+			name: "should stream string values written by Set",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", "<p>hello</p>", 0))
+			},
+			expectedVal: "<p>hello</p>",
+		},
+		{
+			name: "should stream strings that look like base64 as is",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", "test", 0))
+			},
+			expectedVal: "test",
+		},
+		{
+			name: "should stream []byte values that look like base64 as is",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", []byte("dGVzdA=="), 0))
+			},
+			expectedVal: "dGVzdA==",
+		},
+		{
+			name: "should return ErrTypeMismatch for other values",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, f.Set(context.Background(), "key", map[string]int{"id": 1}, 0))
+			},
+			expectedErr: omnicache.ErrTypeMismatch,
+		},
+		{
+			name:        "should return ErrCacheMiss when key does not exist",
+			setup:       func(t *testing.T, f *FileStore) {},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrCacheMiss when key is expired",
+			setup: func(t *testing.T, f *FileStore) {
+				putEntry(t, f, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t, 0)
+			tt.setup(t, store)
+
+			// --- Act ---
+			reader, err := store.GetReader(context.Background(), "key")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when opening reader")
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "expected no error when reading")
+			assert.NoError(t, reader.Close(), "expected no error when closing")
+			assert.Equal(t, tt.expectedVal, string(data), "value must match the stored value")
+		})
+	}
+}
+
+func TestFileStore_SetFromReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should store content readable by Get as a []byte value", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := newTestStore(t, 0)
+
+		// --- Act ---
+		err := store.SetFromReader(ctx, "key", strings.NewReader("hi"), time.Hour)
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when streaming value")
+		val, err := store.Get(ctx, "key")
+		assert.NoError(t, err, "expected no error when getting key")
+		assert.Equal(t, []byte("hi"), val, "expected a []byte value")
+	})
+
+	t.Run("should keep previous value when reading fails", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := newTestStore(t, 0)
+		assert.NoError(t, store.Set(ctx, "key", "previous", 0))
+		readErr := errors.New("read failed")
+
+		// --- Act ---
+		err := store.SetFromReader(ctx, "key", io.MultiReader(strings.NewReader("partial"), failingReader{readErr}), 0)
+
+		// --- Assert ---
+		assert.EqualError(t, readErr, err, "expected the read error")
+		val, _ := store.Get(ctx, "key")
+		assert.Equal(t, "previous", val, "expected previous value untouched")
+		assert.Equal(t, 1, countEntries(t, store), "expected no temporary files left")
+	})
+
+	t.Run("should track size and enforce MaxSize", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := newTestStore(t, 1024)
+
+		// --- Act ---
+		assert.NoError(t, store.SetFromReader(ctx, "a", strings.NewReader(strings.Repeat("x", 800)), 0))
+		assert.NoError(t, store.SetFromReader(ctx, "b", strings.NewReader(strings.Repeat("y", 800)), 0))
+
+		// --- Assert ---
+		assert.Equal(t, 1, countEntries(t, store), "expected the older entry to be evicted")
+	})
+
+	t.Run("should return ErrInvalidValue when TTL is negative", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		err := newTestStore(t, 0).SetFromReader(context.Background(), "key", strings.NewReader("x"), -time.Second)
+
+		// --- Assert ---
+		assert.EqualError(t, omnicache.ErrInvalidValue, err, "error must match the expected error")
+	})
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestFileStore_codec(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id" cbor:"id"`
+		Name string `json:"name" cbor:"name"`
+	}
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t, 0)
+	assert.NoError(t, store.Set(ctx, "before", user{ID: 1, Name: "a"}, 0), "expected no error when setting key")
+	store.codec = codec.CBOR
+
+	// --- Act ---
+	err := store.Set(ctx, "after", user{ID: 2, Name: "b"}, 0)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when setting key")
+
+	after, err := store.Get(ctx, "after")
+	assert.NoError(t, err, "expected no error when getting key")
+	raw, ok := after.(codec.Raw)
+	assert.True(t, ok, "expected the value to be returned as codec.Raw")
+	assert.Equal(t, codec.IDCBOR, raw[0], "expected the value to be encoded with the configured codec")
+
+	var got user
+	assert.NoError(t, codec.Decode(raw, nil, &got), "expected the value to decode")
+	assert.Equal(t, user{ID: 2, Name: "b"}, got)
+
+	before, err := store.Get(ctx, "before")
+	assert.NoError(t, err, "expected no error when getting key")
+	assert.NoError(t, codec.Decode(before.(codec.Raw), nil, &got), "expected values of the previous codec to remain readable")
+	assert.Equal(t, user{ID: 1, Name: "a"}, got)
+
+}
+
+func TestFileStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, f *FileStore)
+		expectErr bool
+	}{
+		{
+			name:  "should succeed when the directory is writable",
+			setup: func(t *testing.T, f *FileStore) {},
+		},
+		{
+			name: "should fail when the directory is gone",
+			setup: func(t *testing.T, f *FileStore) {
+				assert.NoError(t, os.RemoveAll(f.dir))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t, 0)
+			tt.setup(t, store)
+
+			// --- Act ---
+			err := store.Ping(context.Background())
+
+			// --- Assert ---
+			if tt.expectErr {
+				assert.Error(t, err, "expected an error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, 0, countEntries(t, store), "expected no file to be left behind")
+		})
+	}
+}
+
+func TestFileStore_decodeHeader(t *testing.T) {
+	t.Parallel()
+
+	valid := encodeEntry("key", time.Time{}, []byte(`"value"`))
+
+	oversized := append([]byte{}, valid...)
+	binary.BigEndian.PutUint32(oversized[len(headerMagic)+9:], math.MaxUint32)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expectedKey string
+		expectedErr error
+	}{
+		{name: "should decode a valid header", data: valid, expectedKey: "key"},
+		{name: "should reject a truncated header", data: valid[:headerSize-1], expectedErr: errCorruptEntry},
+		{name: "should reject a truncated key", data: valid[:headerSize+1], expectedErr: errCorruptEntry},
+		{name: "should reject garbage", data: []byte("garbage that is long enough"), expectedErr: errCorruptEntry},
+		{name: "should reject a key length beyond the entry", data: oversized, expectedErr: errCorruptEntry},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			header, _, err := decodeHeader(bytes.NewReader(tt.data), int64(len(tt.data)))
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when decoding the header")
+			assert.Equal(t, tt.expectedKey, header.key, "key must match")
+		})
+	}
+}
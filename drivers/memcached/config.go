@@ -0,0 +1,42 @@
+package memcachedstore
+
+import (
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+type MemcachedConfig struct {
+	// Addrs lists the memcached servers as host:port. Keys are spread
+	// across them with consistent hashing. Required.
+	Addrs []string
+
+	// DialTimeout for establishing new connections.
+	//
+	// default: 1 second
+	DialTimeout time.Duration
+
+	// Timeout for each operation (write request and read reply).
+	// A shorter context deadline takes precedence.
+	//
+	// default: 1 second
+	Timeout time.Duration
+
+	// MaxIdleConns is the maximum number of idle connections kept per server.
+	//
+	// default: 8
+	MaxIdleConns int
+
+	// Codec serializes values other than strings and []byte values.
+	// Values written with another registered codec remain readable, which
+	// allows switching codecs on a live cache.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
+
+const (
+	DefaultDialTimeout  = 1 * time.Second
+	DefaultTimeout      = 1 * time.Second
+	DefaultMaxIdleConns = 8
+)
@@ -0,0 +1,25 @@
+package memcachedstore
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the memcached driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "memcached"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of MemcachedConfig.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config MemcachedConfig
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return NewMemcachedStore(config)
+}
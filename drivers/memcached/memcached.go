@@ -0,0 +1,517 @@
+package memcachedstore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/storevalue"
+)
+
+const (
+	// maxKeyLength is the longest key memcached accepts.
+	maxKeyLength = 250
+
+	// maxRelativeTTL is the largest TTL memcached treats as relative.
+	// Larger values are interpreted as absolute Unix timestamps.
+	maxRelativeTTL = 30 * 24 * time.Hour
+)
+
+var errNotStored = errors.New("memcached: item not stored")
+
+type MemcachedStore struct {
+	servers []*server
+	ring    *ring
+	timeout time.Duration
+	codec   codec.Codec
+}
+
+// NewMemcachedStore creates a new MemcachedStore for the servers in config.Addrs.
+// Keys are distributed across servers with consistent hashing and each
+// server keeps its own pool of connections speaking the meta text protocol.
+// Returns ErrInvalidConfig if no addresses are given.
+func NewMemcachedStore(config MemcachedConfig) (contract.Store, error) {
+	if len(config.Addrs) == 0 {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = DefaultDialTimeout
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = DefaultMaxIdleConns
+	}
+
+	if config.Codec == nil {
+		config.Codec = codec.Default
+	}
+
+	store := &MemcachedStore{
+		ring:    newRing(config.Addrs),
+		timeout: config.Timeout,
+		codec:   config.Codec,
+	}
+
+	for _, addr := range config.Addrs {
+		if addr == "" {
+			return nil, omnicache.ErrInvalidConfig
+		}
+		store.servers = append(store.servers, newServer(addr, config))
+	}
+
+	return store, nil
+}
+
+// Clear flushes every server.
+func (m *MemcachedStore) Clear(ctx context.Context) error {
+	return m.forEachServer(ctx, func(srv *server) error {
+		return m.withConn(ctx, srv, func(c *conn) error {
+			if _, err := c.rw.WriteString("flush_all\r\n"); err != nil {
+				return err
+			}
+			if err := c.rw.Flush(); err != nil {
+				return err
+			}
+
+			line, err := readLine(c)
+			if err != nil {
+				return err
+			}
+			if line != "OK" {
+				return responseError(line)
+			}
+
+			return nil
+		})
+	})
+}
+
+// Close closes all pooled connections; later operations fail instead of
+// dialing new ones. It is safe to call Close multiple times.
+func (m *MemcachedStore) Close(ctx context.Context) error {
+	for _, srv := range m.servers {
+		srv.close()
+	}
+
+	return nil
+}
+
+// Delete removes the entry associated with the given key.
+// If the key does not exist, the operation is a no-op.
+func (m *MemcachedStore) Delete(ctx context.Context, key string) error {
+	wireKey, flags, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	return m.withConn(ctx, m.serverFor(key), func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "md %s%s\r\n", wireKey, flags); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+		if line != "HD" && line != "NF" {
+			return responseError(line)
+		}
+
+		return nil
+	})
+}
+
+// DeleteByPattern is not supported: memcached has no way to enumerate keys.
+// It always returns ErrPatternNotSupported.
+func (m *MemcachedStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	return omnicache.ErrPatternNotSupported
+}
+
+// DeleteMany removes multiple keys. Keys are grouped by server and each
+// group is sent as one pipeline of quiet deletes terminated by a no-op,
+// so every server costs a single round-trip.
+func (m *MemcachedStore) DeleteMany(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	groups := make(map[*server][]string)
+	for _, key := range keys {
+		srv := m.serverFor(key)
+		groups[srv] = append(groups[srv], key)
+	}
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(groups))
+
+	for srv, group := range groups {
+		srv, group := srv, group
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.deletePipelined(ctx, srv, group); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+// deletePipelined sends quiet deletes for keys followed by a no-op and
+// reads replies until the no-op's MN. Quiet mode suppresses HD and NF,
+// so any other line before MN is an error.
+func (m *MemcachedStore) deletePipelined(ctx context.Context, srv *server, keys []string) error {
+	return m.withConn(ctx, srv, func(c *conn) error {
+		for _, key := range keys {
+			wireKey, flags, err := encodeKey(key)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(c.rw, "md %s q%s\r\n", wireKey, flags); err != nil {
+				return err
+			}
+		}
+
+		if _, err := c.rw.WriteString("mn\r\n"); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+
+		var firstErr error
+		for {
+			line, err := readLine(c)
+			if err != nil {
+				return err
+			}
+			if line == "MN" {
+				return firstErr
+			}
+			if firstErr == nil {
+				firstErr = responseError(line)
+			}
+		}
+	})
+}
+
+// Get retrieves a value from the cache by key.
+//
+// Behavior:
+//   - If the key does not exist or is expired, returns (nil, ErrCacheMiss).
+//   - Otherwise returns the value: strings and []byte values as such,
+//     other values as codec.Raw.
+func (m *MemcachedStore) Get(ctx context.Context, key string) (any, error) {
+	wireKey, flags, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	err = m.withConn(ctx, m.serverFor(key), func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "mg %s v%s\r\n", wireKey, flags); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+
+		if line == "EN" {
+			return omnicache.ErrCacheMiss
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "VA" {
+			return responseError(line)
+		}
+
+		size, err := strconv.Atoi(fields[1])
+		if err != nil || size < 0 {
+			return responseError(line)
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.rw, data); err != nil {
+			return err
+		}
+		value = storevalue.Decode(data[:size])
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Has checks whether a key exists and is not expired.
+func (m *MemcachedStore) Has(ctx context.Context, key string) (bool, error) {
+	wireKey, flags, err := encodeKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = m.withConn(ctx, m.serverFor(key), func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "mg %s%s\r\n", wireKey, flags); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+
+		switch line {
+		case "HD":
+			exists = true
+		case "EN":
+			exists = false
+		default:
+			return responseError(line)
+		}
+
+		return nil
+	})
+
+	return exists, err
+}
+
+// Ping sends a no-op to every server.
+func (m *MemcachedStore) Ping(ctx context.Context) error {
+	return m.forEachServer(ctx, func(srv *server) error {
+		return m.withConn(ctx, srv, func(c *conn) error {
+			if _, err := c.rw.WriteString("mn\r\n"); err != nil {
+				return err
+			}
+			if err := c.rw.Flush(); err != nil {
+				return err
+			}
+
+			line, err := readLine(c)
+			if err != nil {
+				return err
+			}
+			if line != "MN" {
+				return responseError(line)
+			}
+
+			return nil
+		})
+	})
+}
+
+// Set stores a value in the cache with the given key.
+//
+// Behavior:
+//   - TTL > 0: entry expires after duration, rounded up to whole seconds
+//   - TTL = 0: entry never expires
+//   - TTL < 0: returns ErrInvalidValue
+//
+// Strings and []byte values are stored as-is, other values are encoded
+// with the store's codec.
+func (m *MemcachedStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	wireKey, flags, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := storevalue.Encode(m.codec, value)
+	if err != nil {
+		return err
+	}
+
+	return m.withConn(ctx, m.serverFor(key), func(c *conn) error {
+		if _, err := fmt.Fprintf(c.rw, "ms %s %d T%d%s\r\n", wireKey, len(data), expiration(ttl, time.Now()), flags); err != nil {
+			return err
+		}
+		if _, err := c.rw.Write(data); err != nil {
+			return err
+		}
+		if _, err := c.rw.WriteString("\r\n"); err != nil {
+			return err
+		}
+		if err := c.rw.Flush(); err != nil {
+			return err
+		}
+
+		line, err := readLine(c)
+		if err != nil {
+			return err
+		}
+
+		switch line {
+		case "HD":
+			return nil
+		case "NS":
+			return errNotStored
+		default:
+			return responseError(line)
+		}
+	})
+}
+
+// serverFor returns the server owning key.
+func (m *MemcachedStore) serverFor(key string) *server {
+	return m.servers[m.ring.pick(key)]
+}
+
+// withConn runs fn on a pooled connection to srv with a deadline derived
+// from the store timeout and ctx. The connection is returned to the pool
+// only if the exchange completed cleanly; on any other error its state
+// is unknown, so it is closed.
+func (m *MemcachedStore) withConn(ctx context.Context, srv *server, fn func(c *conn) error) error {
+	c, err := srv.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := c.nc.SetDeadline(deadline); err != nil {
+		c.nc.Close()
+		return err
+	}
+
+	err = fn(c)
+	if err != nil && !errors.Is(err, omnicache.ErrCacheMiss) && !errors.Is(err, errNotStored) {
+		c.nc.Close()
+		return err
+	}
+
+	srv.put(c)
+
+	return err
+}
+
+// forEachServer runs fn against every server concurrently and returns the first error.
+func (m *MemcachedStore) forEachServer(ctx context.Context, fn func(srv *server) error) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, len(m.servers))
+
+	for _, srv := range m.servers {
+		srv := srv
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(srv); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	return <-errCh
+}
+
+// readLine reads a single protocol line without its trailing CRLF.
+func readLine(c *conn) (string, error) {
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// responseError converts an unexpected reply line into an error.
+func responseError(line string) error {
+	switch {
+	case line == "ERROR":
+		return errors.New("memcached: unknown command")
+	case strings.HasPrefix(line, "CLIENT_ERROR "), strings.HasPrefix(line, "SERVER_ERROR "):
+		return fmt.Errorf("memcached: %s", line)
+	default:
+		return fmt.Errorf("memcached: unexpected response %q", line)
+	}
+}
+
+// encodeKey returns the key as sent on the wire plus the flag suffix to
+// use. Keys memcached can't accept verbatim (whitespace, control
+// characters, over 250 bytes) are base64-encoded with the 'b' flag.
+// Returns ErrInvalidValue if the key is empty or too long even encoded.
+func encodeKey(key string) (string, string, error) {
+	if key == "" {
+		return "", "", omnicache.ErrInvalidValue
+	}
+
+	if len(key) <= maxKeyLength && isPlainKey(key) {
+		return key, "", nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(key))
+	if len(encoded) > maxKeyLength {
+		return "", "", omnicache.ErrInvalidValue
+	}
+
+	return encoded, " b", nil
+}
+
+func isPlainKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if key[i] <= ' ' || key[i] == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+
+// expiration converts a TTL into memcached's exptime: 0 for no expiry,
+// whole seconds (rounded up) for short TTLs, and an absolute Unix time
+// for TTLs beyond memcached's 30 day relative limit.
+func expiration(ttl time.Duration, now time.Time) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+
+	if ttl > maxRelativeTTL {
+		return now.Add(ttl).Unix()
+	}
+
+	seconds := int64(ttl / time.Second)
+	if ttl%time.Second != 0 {
+		seconds++
+	}
+
+	return seconds
+}
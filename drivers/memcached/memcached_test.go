@@ -0,0 +1,529 @@
+package memcachedstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil"
+)
+
+// newTestStore starts n fake memcached servers and returns a store using them.
+func newTestStore(t *testing.T, n int) (*MemcachedStore, []*testutil.FakeMemcached) {
+	t.Helper()
+
+	var fakes []*testutil.FakeMemcached
+	var addrs []string
+	for i := 0; i < n; i++ {
+		fake := testutil.NewFakeMemcached(t)
+		fakes = append(fakes, fake)
+		addrs = append(addrs, fake.Addr())
+	}
+
+	store, err := NewMemcachedStore(MemcachedConfig{Addrs: addrs})
+	assert.NoError(t, err, "expected no error when creating store")
+	t.Cleanup(func() { store.Close(context.Background()) })
+
+	return store.(*MemcachedStore), fakes
+}
+
+// totalLen returns the number of live items across all fake servers.
+func totalLen(fakes []*testutil.FakeMemcached) int {
+	total := 0
+	for _, f := range fakes {
+		total += f.Len()
+	}
+	return total
+}
+
+func TestMemcachedStore_NewMemcachedStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      MemcachedConfig
+		expectedErr error
+	}{
+		{
+			name:   "should create store with defaults when addresses are provided",
+			config: MemcachedConfig{Addrs: []string{"127.0.0.1:11211", "127.0.0.1:11212"}},
+		},
+		{
+			name:        "should return ErrInvalidConfig when no addresses are provided",
+			config:      MemcachedConfig{},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name:        "should return ErrInvalidConfig when an address is empty",
+			config:      MemcachedConfig{Addrs: []string{"127.0.0.1:11211", ""}},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewMemcachedStore(tt.config)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, store, "store must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating store")
+
+			mcStore, ok := store.(*MemcachedStore)
+			assert.True(t, ok, "expected store to be of type *MemcachedStore")
+			assert.Equal(t, len(tt.config.Addrs), len(mcStore.servers), "one pool per server expected")
+			assert.Equal(t, DefaultTimeout, mcStore.timeout, "expected default timeout")
+			assert.Equal(t, DefaultMaxIdleConns, mcStore.servers[0].max, "expected default idle pool size")
+		})
+	}
+}
+
+func TestMemcachedStore_Clear(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, fakes := newTestStore(t, 3)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, store.Set(ctx, fmt.Sprintf("key:%d", i), i, 0))
+	}
+
+	// --- Act ---
+	err := store.Clear(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when clearing store")
+	assert.Equal(t, 0, totalLen(fakes), "expected every server to be flushed")
+}
+
+func TestMemcachedStore_Close(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, _ := newTestStore(t, 1)
+	assert.NoError(t, store.Set(ctx, "key", "value", 0))
+	assert.Equal(t, 1, len(store.servers[0].idle), "expected connection to be pooled")
+
+	// --- Act ---
+	err := store.Close(ctx)
+	errAgain := store.Close(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when calling Close")
+	assert.NoError(t, errAgain, "expected no error when calling Close multiple times")
+	assert.Empty(t, store.servers[0].idle, "expected idle connections to be released")
+}
+
+func TestMemcachedStore_Delete(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, fakes := newTestStore(t, 1)
+	assert.NoError(t, store.Set(ctx, "key", "value", 0))
+
+	// --- Act ---
+	err := store.Delete(ctx, "key")
+	errMissing := store.Delete(ctx, "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting existing key")
+	assert.NoError(t, errMissing, "expected no error when deleting missing key")
+	assert.False(t, fakes[0].Has("key"), "expected key to be removed")
+}
+
+func TestMemcachedStore_DeleteByPattern(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store, _ := newTestStore(t, 1)
+
+	// --- Act ---
+	err := store.DeleteByPattern(context.Background(), "user:*")
+
+	// --- Assert ---
+	assert.EqualError(t, omnicache.ErrPatternNotSupported, err, "expected unsupported pattern error")
+}
+
+func TestMemcachedStore_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should delete keys across servers with one pipeline each", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store, fakes := newTestStore(t, 3)
+
+		var keys []string
+		for i := 0; i < 30; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			keys = append(keys, key)
+			assert.NoError(t, store.Set(ctx, key, i, 0))
+		}
+		assert.NoError(t, store.Set(ctx, "keep", "me", 0))
+
+		// --- Act ---
+		err := store.DeleteMany(ctx, append(keys, "missing")...)
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when deleting many keys")
+		assert.Equal(t, 1, totalLen(fakes), "expected only the kept key to remain")
+
+		for _, f := range fakes {
+			noops := 0
+			for _, cmd := range f.Commands() {
+				if cmd == "mn" {
+					noops++
+				}
+			}
+			assert.True(t, noops <= 1, "expected at most one pipeline per server")
+		}
+	})
+
+	t.Run("should do nothing when no keys are provided", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, fakes := newTestStore(t, 1)
+
+		// --- Act ---
+		err := store.DeleteMany(context.Background())
+
+		// --- Assert ---
+		assert.NoError(t, err)
+		assert.Empty(t, fakes[0].Commands(), "expected no commands to be sent")
+	})
+
+	t.Run("should return ErrInvalidValue for an empty key", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, _ := newTestStore(t, 1)
+
+		// --- Act ---
+		err := store.DeleteMany(context.Background(), "ok", "")
+
+		// --- Assert ---
+		assert.EqualError(t, omnicache.ErrInvalidValue, err)
+	})
+}
+
+func TestMemcachedStore_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		key         string
+		setup       func(t *testing.T, s *MemcachedStore)
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name: "should return value when key exists",
+			key:  "key",
+			setup: func(t *testing.T, s *MemcachedStore) {
+				assert.NoError(t, s.Set(context.Background(), "key", "value", time.Minute))
+			},
+			expectedVal: "value",
+		},
+		{
+			name: "should round-trip keys with spaces using base64 keys",
+			key:  "user name with spaces",
+			setup: func(t *testing.T, s *MemcachedStore) {
+				assert.NoError(t, s.Set(context.Background(), "user name with spaces", map[string]int{"id": 1}, 0))
+			},
+			expectedVal: codec.Raw("\x01{\"id\":1}"),
+		},
+		{
+			name:        "should return ErrCacheMiss when key does not exist",
+			key:         "key",
+			setup:       func(t *testing.T, s *MemcachedStore) {},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name:        "should return ErrInvalidValue when key is empty",
+			key:         "",
+			setup:       func(t *testing.T, s *MemcachedStore) {},
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store, _ := newTestStore(t, 2)
+			tt.setup(t, store)
+
+			// --- Act ---
+			val, err := store.Get(context.Background(), tt.key)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, val, "value must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when getting key")
+			assert.Equal(t, tt.expectedVal, val, "value must match the stored value")
+		})
+	}
+
+	t.Run("should reuse pooled connection after a miss", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, _ := newTestStore(t, 1)
+
+		// --- Act ---
+		_, err := store.Get(context.Background(), "missing")
+
+		// --- Assert ---
+		assert.EqualError(t, omnicache.ErrCacheMiss, err)
+		assert.Equal(t, 1, len(store.servers[0].idle), "expected connection to return to the pool")
+	})
+
+	t.Run("should return network error when server is down", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store, fakes := newTestStore(t, 1)
+		fakes[0].Close()
+
+		// --- Act ---
+		_, err := store.Get(context.Background(), "key")
+
+		// --- Assert ---
+		assert.Error(t, err, "expected an error when server is unreachable")
+		assert.False(t, errors.Is(err, omnicache.ErrCacheMiss), "network errors must not be reported as misses")
+	})
+}
+
+func TestMemcachedStore_Has(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, _ := newTestStore(t, 2)
+	assert.NoError(t, store.Set(ctx, "key", "value", 0))
+
+	// --- Act ---
+	exists, err := store.Has(ctx, "key")
+	missing, errMissing := store.Has(ctx, "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err)
+	assert.True(t, exists, "expected existing key to be reported")
+	assert.NoError(t, errMissing)
+	assert.False(t, missing, "expected missing key not to be reported")
+}
+
+func TestMemcachedStore_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		key         string
+		ttl         time.Duration
+		expectedErr error
+	}{
+		{
+			name: "should store value with relative ttl",
+			key:  "key",
+			ttl:  time.Minute,
+		},
+		{
+			name: "should store value without expiration",
+			key:  "key",
+			ttl:  0,
+		},
+		{
+			name:        "should return ErrInvalidValue when ttl is negative",
+			key:         "key",
+			ttl:         -time.Second,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+		{
+			name:        "should return ErrInvalidValue when key is too long even encoded",
+			key:         strings.Repeat("k ", 200),
+			ttl:         0,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store, fakes := newTestStore(t, 1)
+
+			// --- Act ---
+			err := store.Set(context.Background(), tt.key, "value", tt.ttl)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Equal(t, 0, fakes[0].Len(), "expected nothing to be stored")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when setting key")
+			assert.True(t, fakes[0].Has(tt.key), "expected key to be stored")
+
+			if tt.ttl > 0 {
+				assert.WithinDuration(t, time.Now().Add(tt.ttl), fakes[0].Expiration(tt.key), 2*time.Second, "expiration must match ttl")
+			} else {
+				assert.True(t, fakes[0].Expiration(tt.key).IsZero(), "expected no expiration")
+			}
+		})
+	}
+}
+
+func TestMemcachedStore_expiration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		expected int64
+	}{
+		{name: "should return 0 for no expiration", ttl: 0, expected: 0},
+		{name: "should round sub-second ttl up to one second", ttl: 200 * time.Millisecond, expected: 1},
+		{name: "should keep whole seconds", ttl: 90 * time.Second, expected: 90},
+		{name: "should use absolute time beyond 30 days", ttl: 31 * 24 * time.Hour, expected: now.Add(31 * 24 * time.Hour).Unix()},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			got := expiration(tt.ttl, now)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expected, got, "exptime must match")
+		})
+	}
+}
+
+func TestMemcachedStore_encodeKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		key           string
+		expectedKey   string
+		expectedFlags string
+		expectedErr   error
+	}{
+		{name: "should keep plain keys verbatim", key: "user:1", expectedKey: "user:1", expectedFlags: ""},
+		{name: "should base64 encode keys with whitespace", key: "a b", expectedKey: "YSBi", expectedFlags: " b"},
+		{name: "should reject keys longer than 250 bytes", key: strings.Repeat("k", 251), expectedErr: omnicache.ErrInvalidValue},
+		{name: "should reject empty keys", key: "", expectedErr: omnicache.ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			key, flags, err := encodeKey(tt.key)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedKey, key, "wire key must match")
+			assert.Equal(t, tt.expectedFlags, flags, "flags must match")
+		})
+	}
+}
+
+func TestMemcachedStore_codec(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id" cbor:"id"`
+		Name string `json:"name" cbor:"name"`
+	}
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, _ := newTestStore(t, 1)
+	assert.NoError(t, store.Set(ctx, "before", user{ID: 1, Name: "a"}, 0), "expected no error when setting key")
+	store.codec = codec.CBOR
+
+	// --- Act ---
+	err := store.Set(ctx, "after", user{ID: 2, Name: "b"}, 0)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when setting key")
+
+	after, err := store.Get(ctx, "after")
+	assert.NoError(t, err, "expected no error when getting key")
+	raw, ok := after.(codec.Raw)
+	assert.True(t, ok, "expected the value to be returned as codec.Raw")
+	assert.Equal(t, codec.IDCBOR, raw[0], "expected the value to be encoded with the configured codec")
+
+	var got user
+	assert.NoError(t, codec.Decode(raw, nil, &got), "expected the value to decode")
+	assert.Equal(t, user{ID: 2, Name: "b"}, got)
+
+	before, err := store.Get(ctx, "before")
+	assert.NoError(t, err, "expected no error when getting key")
+	assert.NoError(t, codec.Decode(before.(codec.Raw), nil, &got), "expected values of the previous codec to remain readable")
+	assert.Equal(t, user{ID: 1, Name: "a"}, got)
+
+}
+
+func TestMemcachedStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store, fakes := newTestStore(t, 2)
+
+	// --- Act ---
+	errUp := store.Ping(ctx)
+	fakes[1].Close()
+	store.servers[1].close()
+	errDown := store.Ping(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, errUp, "expected no error while every server is up")
+	assert.Error(t, errDown, "expected an error when a server is down")
+	assert.Contains(t, strings.Join(fakes[0].Commands(), " "), "mn", "expected a no-op to be sent")
+}
@@ -0,0 +1,135 @@
+package memcachedstore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"hash/crc32"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// virtualNodes is the number of points each server gets on the hash ring.
+// More points give a more even key distribution.
+const virtualNodes = 160
+
+var errClosed = errors.New("memcached: client is closed")
+
+// server holds the connection pool for a single memcached node.
+type server struct {
+	addr   string
+	dialer net.Dialer
+	mu     sync.Mutex
+	idle   []*conn
+	max    int
+	closed bool
+}
+
+type conn struct {
+	nc net.Conn
+	rw *bufio.ReadWriter
+}
+
+func newServer(addr string, config MemcachedConfig) *server {
+	return &server{
+		addr:   addr,
+		dialer: net.Dialer{Timeout: config.DialTimeout},
+		max:    config.MaxIdleConns,
+	}
+}
+
+// get returns an idle connection or dials a new one.
+// Returns errClosed once the server has been closed.
+func (s *server) get(ctx context.Context) (*conn, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, errClosed
+	}
+	if n := len(s.idle); n > 0 {
+		c := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return c, nil
+	}
+	s.mu.Unlock()
+
+	nc, err := s.dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &conn{
+		nc: nc,
+		rw: bufio.NewReadWriter(bufio.NewReader(nc), bufio.NewWriter(nc)),
+	}, nil
+}
+
+// put returns a healthy connection to the pool, closing it if the pool
+// is full or the server has been closed.
+func (s *server) put(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || len(s.idle) >= s.max {
+		c.nc.Close()
+		return
+	}
+
+	s.idle = append(s.idle, c)
+}
+
+// close closes all idle connections and stops pooling new ones.
+func (s *server) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for _, c := range s.idle {
+		c.nc.Close()
+	}
+	s.idle = nil
+}
+
+// ring maps keys to servers using consistent hashing, so adding or
+// removing a server only remaps the keys that belonged to it.
+type ring struct {
+	points []uint32
+	owners map[uint32]int
+}
+
+func newRing(addrs []string) *ring {
+	r := &ring{owners: make(map[uint32]int, len(addrs)*virtualNodes)}
+
+	for i, addr := range addrs {
+		for v := 0; v < virtualNodes; v++ {
+			h := crc32.ChecksumIEEE([]byte(addr + "-" + strconv.Itoa(v)))
+			if _, taken := r.owners[h]; taken {
+				continue
+			}
+			r.owners[h] = i
+			r.points = append(r.points, h)
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+
+	return r
+}
+
+// pick returns the index of the server owning key.
+func (r *ring) pick(key string) int {
+	if len(r.points) == 0 {
+		return 0
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+	idx := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if idx == len(r.points) {
+		idx = 0
+	}
+
+	return r.owners[r.points[idx]]
+}
@@ -0,0 +1,125 @@
+package memcachedstore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil"
+)
+
+func TestPool_server(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should reuse idle connections and cap the pool size", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		fake := testutil.NewFakeMemcached(t)
+		srv := newServer(fake.Addr(), MemcachedConfig{MaxIdleConns: 1})
+
+		c1, err := srv.get(ctx)
+		assert.NoError(t, err)
+		c2, err := srv.get(ctx)
+		assert.NoError(t, err)
+
+		// --- Act ---
+		srv.put(c1)
+		srv.put(c2)
+		reused, err := srv.get(ctx)
+
+		// --- Assert ---
+		assert.NoError(t, err)
+		assert.True(t, reused == c1, "expected the pooled connection to be reused")
+		assert.Empty(t, srv.idle, "expected pool to be empty after reuse")
+	})
+
+	t.Run("should close returned connections after close", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		fake := testutil.NewFakeMemcached(t)
+		srv := newServer(fake.Addr(), MemcachedConfig{MaxIdleConns: 4})
+
+		c, err := srv.get(ctx)
+		assert.NoError(t, err)
+
+		// --- Act ---
+		srv.close()
+		srv.put(c)
+
+		// --- Assert ---
+		assert.Empty(t, srv.idle, "expected no connections to be pooled after close")
+	})
+
+	t.Run("should refuse to dial after close", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		fake := testutil.NewFakeMemcached(t)
+		srv := newServer(fake.Addr(), MemcachedConfig{MaxIdleConns: 4})
+		srv.close()
+
+		// --- Act ---
+		c, err := srv.get(ctx)
+
+		// --- Assert ---
+		assert.EqualError(t, errClosed, err, "expected get to fail after close")
+		assert.True(t, c == nil, "expected no connection after close")
+	})
+}
+
+func TestPool_ring(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should spread keys across all servers", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		r := newRing([]string{"a:11211", "b:11211", "c:11211"})
+		counts := make(map[int]int)
+
+		// --- Act ---
+		for i := 0; i < 3000; i++ {
+			counts[r.pick(fmt.Sprintf("key:%d", i))]++
+		}
+
+		// --- Assert ---
+		assert.Equal(t, 3, len(counts), "expected every server to own keys")
+		for idx, n := range counts {
+			assert.True(t, n > 500, fmt.Sprintf("expected server %d to own a fair share, got %d", idx, n))
+		}
+	})
+
+	t.Run("should only remap keys of a removed server", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		full := newRing([]string{"a:11211", "b:11211", "c:11211"})
+		reduced := newRing([]string{"a:11211", "b:11211"})
+
+		// --- Act & Assert ---
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key:%d", i)
+			before := full.pick(key)
+			if before == 2 {
+				continue // owned by the removed server
+			}
+			assert.Equal(t, before, reduced.pick(key), fmt.Sprintf("expected %q to stay on server %d", key, before))
+		}
+	})
+
+	t.Run("should return the only server for an empty ring", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Act ---
+		idx := newRing(nil).pick("key")
+
+		// --- Assert ---
+		assert.Equal(t, 0, idx)
+	})
+}
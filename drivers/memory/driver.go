@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the memory driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "memory"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of MemoryConfig.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config MemoryConfig
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return NewMemoryStore(config)
+}
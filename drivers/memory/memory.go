@@ -1,10 +1,13 @@
 package memory
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shoraid/omnicache"
@@ -13,6 +16,8 @@ import (
 
 type MemoryStore struct {
 	data          sync.Map
+	entries       int64 // number of entries, accessed atomically
+	bytes         int64 // approximate size of the entries, accessed atomically
 	cancelCleanup context.CancelFunc
 	doneCh        chan struct{}
 }
@@ -22,6 +27,21 @@ type memoryItem struct {
 	expiration time.Time
 }
 
+// size returns the approximate size of an entry: the length of its key
+// plus the length of []byte and string values.
+func (item memoryItem) size(key string) int64 {
+	size := int64(len(key))
+
+	switch val := item.value.(type) {
+	case []byte:
+		size += int64(len(val))
+	case string:
+		size += int64(len(val))
+	}
+
+	return size
+}
+
 // NewMemoryStore creates a new in-memory cache store.
 // It starts a background goroutine to periodically clean up expired keys.
 // The cleanup interval can be provided via the config map under "cleanup_interval".
@@ -74,12 +94,29 @@ func (m *MemoryStore) deleteExpiredKeys() {
 	m.data.Range(func(k, v any) bool {
 		item := v.(memoryItem)
 		if !item.expiration.IsZero() && now.After(item.expiration) {
-			m.data.Delete(k)
+			m.remove(k.(string))
 		}
 		return true
 	})
 }
 
+// remove deletes key and updates the size counters.
+func (m *MemoryStore) remove(key string) {
+	if value, loaded := m.data.LoadAndDelete(key); loaded {
+		atomic.AddInt64(&m.entries, -1)
+		atomic.AddInt64(&m.bytes, -value.(memoryItem).size(key))
+	}
+}
+
+// removeAll deletes every entry. Entries are removed one by one, so that
+// the size counters stay accurate while Set runs concurrently.
+func (m *MemoryStore) removeAll() {
+	m.data.Range(func(k, _ any) bool {
+		m.remove(k.(string))
+		return true
+	})
+}
+
 // Clear removes all entries from the cache.
 //
 // Behavior:
@@ -90,7 +127,7 @@ func (m *MemoryStore) deleteExpiredKeys() {
 //   - Useful for testing, resetting state, or administrative cleanup.
 //   - Should be used carefully in production as it clears all data.
 func (m *MemoryStore) Clear(ctx context.Context) error {
-	m.data.Clear()
+	m.removeAll()
 
 	return nil
 }
@@ -116,7 +153,7 @@ func (m *MemoryStore) Close(ctx context.Context) error {
 //   - Explicit cache invalidation for a single key.
 //   - Useful when data becomes stale or needs to be refreshed.
 func (m *MemoryStore) Delete(ctx context.Context, key string) error {
-	m.data.Delete(key)
+	m.remove(key)
 
 	return nil
 }
@@ -145,7 +182,7 @@ func (m *MemoryStore) DeleteByPattern(ctx context.Context, pattern string) error
 
 	// Fast path: clear all
 	if pattern == "*" {
-		m.data = sync.Map{}
+		m.removeAll()
 		return nil
 	}
 
@@ -184,7 +221,7 @@ func (m *MemoryStore) DeleteByPattern(ctx context.Context, pattern string) error
 	})
 
 	for _, key := range keysToDelete {
-		m.data.Delete(key)
+		m.remove(key)
 	}
 
 	return nil
@@ -198,7 +235,7 @@ func (m *MemoryStore) DeleteByPattern(ctx context.Context, pattern string) error
 //   - Returns nil always, since deletion is best-effort and non-critical.
 func (m *MemoryStore) DeleteMany(ctx context.Context, keys ...string) error {
 	for _, key := range keys {
-		m.data.Delete(key)
+		m.remove(key)
 	}
 
 	return nil
@@ -224,7 +261,7 @@ func (m *MemoryStore) Get(ctx context.Context, key string) (any, error) {
 
 	// Key exists but expired
 	if !item.expiration.IsZero() && time.Now().After(item.expiration) {
-		m.data.Delete(key)
+		m.remove(key)
 		return nil, omnicache.ErrCacheMiss
 	}
 
@@ -246,6 +283,21 @@ func (m *MemoryStore) Has(ctx context.Context, key string) (bool, error) {
 	return true, nil
 }
 
+// Ping always succeeds: the store lives in the process.
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Size returns the number of entries, including expired entries not yet
+// cleaned up, and their approximate size in bytes: the length of the keys
+// plus the length of []byte and string values. Other values are not sized.
+//
+// The counts are maintained as entries are written and removed, so Size
+// does not walk the store.
+func (m *MemoryStore) Size() (entries int64, bytes int64) {
+	return atomic.LoadInt64(&m.entries), atomic.LoadInt64(&m.bytes)
+}
+
 // Set stores a value in the cache with the given key.
 //
 // Behavior:
@@ -268,10 +320,54 @@ func (m *MemoryStore) Set(ctx context.Context, key string, value any, ttl time.D
 	}
 
 	// Store the value with expiration
-	m.data.Store(key, memoryItem{
+	item := memoryItem{
 		value,
 		expiration,
-	})
+	}
+
+	size := item.size(key)
+	if previous, loaded := m.data.Swap(key, item); loaded {
+		size -= previous.(memoryItem).size(key)
+	} else {
+		atomic.AddInt64(&m.entries, 1)
+	}
+	atomic.AddInt64(&m.bytes, size)
 
 	return nil
 }
+
+// GetReader returns a reader over the []byte or string value stored under
+// key. The value is read in place, without copying.
+//
+// Returns ErrCacheMiss if the key is missing or expired, and
+// ErrTypeMismatch if the value is neither a []byte nor a string.
+func (m *MemoryStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	value, err := m.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch val := value.(type) {
+	case []byte:
+		return io.NopCloser(bytes.NewReader(val)), nil
+	case string:
+		return io.NopCloser(strings.NewReader(val)), nil
+	default:
+		return nil, omnicache.ErrTypeMismatch
+	}
+}
+
+// SetFromReader reads r to the end and stores the result as a []byte value.
+// Nothing is stored if reading fails.
+func (m *MemoryStore) SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return m.Set(ctx, key, data, ttl)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -1271,3 +1273,274 @@ func BenchmarkMemoryStore_Set(b *testing.B) {
 		})
 	}
 }
+
+func TestMemoryStore_GetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		setup         func(*MemoryStore)
+		expectedValue string
+		expectedError error
+	}{
+		{
+			name: "should stream []byte values",
+			setup: func(m *MemoryStore) {
+				m.data.Store("key", memoryItem{value: []byte("bytes")})
+			},
+			expectedValue: "bytes",
+		},
+		{
+			name: "should stream string values",
+			setup: func(m *MemoryStore) {
+				m.data.Store("key", memoryItem{value: "text"})
+			},
+			expectedValue: "text",
+		},
+		{
+			name: "should return ErrTypeMismatch for other values",
+			setup: func(m *MemoryStore) {
+				m.data.Store("key", memoryItem{value: 123})
+			},
+			expectedError: omnicache.ErrTypeMismatch,
+		},
+		{
+			name: "should return ErrCacheMiss when key is expired",
+			setup: func(m *MemoryStore) {
+				m.data.Store("key", memoryItem{value: "text", expiration: time.Now().Add(-time.Second)})
+			},
+			expectedError: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := &MemoryStore{}
+			tt.setup(store)
+
+			// --- Act ---
+			reader, err := store.GetReader(context.Background(), "key")
+
+			// --- Assert ---
+			if tt.expectedError != nil {
+				assert.EqualError(t, tt.expectedError, err, "expected error mismatch")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "expected no error when reading")
+			assert.Equal(t, tt.expectedValue, string(data), "expected value mismatch")
+			assert.NoError(t, reader.Close(), "expected no error when closing")
+		})
+	}
+}
+
+func TestMemoryStore_SetFromReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		reader        io.Reader
+		ttl           time.Duration
+		expectedValue any
+		expectedError error
+	}{
+		{
+			name:          "should store the content as []byte",
+			reader:        strings.NewReader("content"),
+			expectedValue: []byte("content"),
+		},
+		{
+			name:          "should return ErrInvalidValue when TTL is negative",
+			reader:        strings.NewReader("content"),
+			ttl:           -time.Second,
+			expectedError: omnicache.ErrInvalidValue,
+		},
+		{
+			name:          "should not store anything when reading fails",
+			reader:        io.MultiReader(strings.NewReader("partial"), errReader{}),
+			expectedError: errRead,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := &MemoryStore{}
+
+			// --- Act ---
+			err := store.SetFromReader(ctx, "key", tt.reader, tt.ttl)
+
+			// --- Assert ---
+			value, getErr := store.Get(ctx, "key")
+			if tt.expectedError != nil {
+				assert.EqualError(t, tt.expectedError, err, "expected error mismatch")
+				assert.EqualError(t, omnicache.ErrCacheMiss, getErr, "expected nothing stored")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expectedValue, value, "expected value mismatch")
+		})
+	}
+}
+
+var errRead = errors.New("read failed")
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errRead }
+
+func TestMemoryStore_Size(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		setup           func(ctx context.Context, m *MemoryStore)
+		expectedEntries int64
+		expectedBytes   int64
+	}{
+		{
+			name:            "should report an empty store",
+			setup:           func(ctx context.Context, m *MemoryStore) {},
+			expectedEntries: 0,
+			expectedBytes:   0,
+		},
+		{
+			name: "should count keys and []byte and string values",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "k1", []byte("abc"), 0)
+				_ = m.Set(ctx, "k2", "hello", 0)
+			},
+			expectedEntries: 2,
+			expectedBytes:   2 + 3 + 2 + 5,
+		},
+		{
+			name: "should count only the key of other values",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "key", 123, 0)
+			},
+			expectedEntries: 1,
+			expectedBytes:   3,
+		},
+		{
+			name: "should count overwritten keys once",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "key", "hello", 0)
+				_ = m.Set(ctx, "key", "hi", 0)
+			},
+			expectedEntries: 1,
+			expectedBytes:   3 + 2,
+		},
+		{
+			name: "should not count deleted keys",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "k1", "abc", 0)
+				_ = m.Set(ctx, "k2", "abc", 0)
+				_ = m.Set(ctx, "k3", "abc", 0)
+				_ = m.Delete(ctx, "k1")
+				_ = m.Delete(ctx, "k1")
+				_ = m.DeleteMany(ctx, "k2", "missing")
+			},
+			expectedEntries: 1,
+			expectedBytes:   2 + 3,
+		},
+		{
+			name: "should not count keys deleted by pattern",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "user:1", "abc", 0)
+				_ = m.Set(ctx, "post:1", "abc", 0)
+				_ = m.DeleteByPattern(ctx, "user:*")
+			},
+			expectedEntries: 1,
+			expectedBytes:   6 + 3,
+		},
+		{
+			name: "should not count expired keys once removed",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "k1", "abc", time.Nanosecond)
+				_ = m.Set(ctx, "k2", "abc", time.Nanosecond)
+				time.Sleep(time.Millisecond)
+				_, _ = m.Get(ctx, "k1")
+				m.deleteExpiredKeys()
+			},
+			expectedEntries: 0,
+			expectedBytes:   0,
+		},
+		{
+			name: "should report an empty store after Clear",
+			setup: func(ctx context.Context, m *MemoryStore) {
+				_ = m.Set(ctx, "k1", "abc", 0)
+				_ = m.Clear(ctx)
+			},
+			expectedEntries: 0,
+			expectedBytes:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := &MemoryStore{}
+			tt.setup(context.Background(), store)
+
+			// --- Act ---
+			entries, bytes := store.Size()
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedEntries, entries, "expected entries mismatch")
+			assert.Equal(t, tt.expectedBytes, bytes, "expected bytes mismatch")
+		})
+	}
+}
+
+func TestMemoryStore_newFromOptions(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	m, err := omnicache.NewManagerFromConfig(map[string]any{
+		"stores": map[string]any{
+			"local": map[string]any{
+				"driver":  DriverName,
+				"options": map[string]any{"cleanup_interval": "5m"},
+			},
+		},
+	})
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when creating the manager")
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+
+	assert.NoError(t, m.Set(context.Background(), "k", "v", time.Minute), "expected the memory store to be registered")
+	value, err := m.Get(context.Background(), "k")
+	assert.NoError(t, err, "expected no error from the memory store")
+	assert.Equal(t, "v", value, "expected the stored value")
+}
+
+func TestMemoryStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := &MemoryStore{}
+
+	// --- Act ---
+	err := store.Ping(context.Background())
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected ping to always succeed")
+}
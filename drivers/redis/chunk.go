@@ -0,0 +1,214 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+)
+
+// chunkKeySep separates a key from the index in the keys of its chunks.
+const chunkKeySep = "#chunk:"
+
+// manifestSize is the size of an encoded manifest: envelope header, chunk
+// count, total size and CRC-32 of the reassembled value.
+const manifestSize = 2 + 4 + 8 + 4
+
+// headUnlinkScript unlinks a key and returns the head of its value, as
+// getManifestHead does, so deleting a large value does not fetch it.
+// ARGV: manifestSize.
+const headUnlinkScript = `
+local head = redis.call('GETRANGE', KEYS[1], 0, ARGV[1])
+redis.call('UNLINK', KEYS[1])
+return head
+`
+
+// manifest describes a value stored in chunks. It is stored at the key
+// itself, in place of the value.
+type manifest struct {
+	count    int
+	size     int
+	checksum uint32
+}
+
+func (m manifest) encode() []byte {
+	buf := make([]byte, manifestSize)
+	buf[0], buf[1] = envelopeMagic, tagChunked
+	binary.BigEndian.PutUint32(buf[2:], uint32(m.count))
+	binary.BigEndian.PutUint64(buf[6:], uint64(m.size))
+	binary.BigEndian.PutUint32(buf[14:], m.checksum)
+
+	return buf
+}
+
+// parseManifest reports whether data is a manifest and decodes it.
+func parseManifest(data []byte) (manifest, bool) {
+	if len(data) != manifestSize || data[0] != envelopeMagic || data[1] != tagChunked {
+		return manifest{}, false
+	}
+
+	return manifest{
+		count:    int(binary.BigEndian.Uint32(data[2:])),
+		size:     int(binary.BigEndian.Uint64(data[6:])),
+		checksum: binary.BigEndian.Uint32(data[14:]),
+	}, true
+}
+
+// chunkKeys returns the keys of chunks from to to-1 of key.
+func chunkKeys(key string, from, to int) []string {
+	keys := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		keys = append(keys, key+chunkKeySep+strconv.Itoa(i))
+	}
+
+	return keys
+}
+
+// setChunked stores data at key, split into chunks if it exceeds chunkSize.
+//
+// The chunks and the manifest are written in one transaction with the same
+// TTL. The transaction reads the head of the previous value with GETRANGE,
+// so chunks left over from a larger previous value can be removed
+// afterwards without fetching the whole value.
+func (r *RedisStore) setChunked(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	if len(data) <= r.chunkSize {
+		var prev *redis.StringCmd
+		_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			prev = getManifestHead(ctx, pipe, key)
+			pipe.Set(ctx, key, data, ttl)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		return r.unlinkStaleChunks(ctx, key, prev.Val(), 0)
+	}
+
+	m := manifest{
+		count:    (len(data) + r.chunkSize - 1) / r.chunkSize,
+		size:     len(data),
+		checksum: crc32.ChecksumIEEE(data),
+	}
+	keys := chunkKeys(key, 0, m.count)
+
+	var prev *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, chunkKey := range keys {
+			end := (i + 1) * r.chunkSize
+			if end > len(data) {
+				end = len(data)
+			}
+			pipe.Set(ctx, chunkKey, data[i*r.chunkSize:end], ttl)
+		}
+
+		// The manifest goes last, so it never points at missing chunks.
+		prev = getManifestHead(ctx, pipe, key)
+		pipe.Set(ctx, key, m.encode(), ttl)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.unlinkStaleChunks(ctx, key, prev.Val(), m.count)
+}
+
+// getManifestHead queues a GETRANGE of the first manifestSize+1 bytes of
+// key: enough to recognize a manifest, which is exactly manifestSize bytes
+// long, without fetching a large value. Missing keys yield "".
+func getManifestHead(ctx context.Context, pipe redis.Pipeliner, key string) *redis.StringCmd {
+	return pipe.GetRange(ctx, key, 0, manifestSize)
+}
+
+// unlinkStaleChunks removes the chunks of the previous value prev of key
+// that the new value, made of keep chunks, did not overwrite.
+func (r *RedisStore) unlinkStaleChunks(ctx context.Context, key, prev string, keep int) error {
+	m, ok := parseManifest([]byte(prev))
+	if !ok || m.count <= keep {
+		return nil
+	}
+
+	return r.unlink(ctx, chunkKeys(key, keep, m.count))
+}
+
+// getChunks reassembles the value described by m.
+// A missing or inconsistent chunk, e.g. one that expired early or was
+// evicted, is reported as a cache miss.
+func (r *RedisStore) getChunks(ctx context.Context, key string, m manifest) ([]byte, error) {
+	keys := chunkKeys(key, 0, m.count)
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, chunkKey := range keys {
+			cmds[i] = pipe.Get(ctx, chunkKey)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, m.size)
+	for _, cmd := range cmds {
+		chunk, err := cmd.Bytes()
+		if err == redis.Nil {
+			return nil, omnicache.ErrCacheMiss
+		} else if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+
+	if len(data) != m.size || crc32.ChecksumIEEE(data) != m.checksum {
+		return nil, omnicache.ErrCacheMiss
+	}
+
+	return data, nil
+}
+
+// deleteChunked removes keys with headUnlinkScript and then the chunks of
+// any chunked values among them.
+func (r *RedisStore) deleteChunked(ctx context.Context, keys ...string) error {
+	cmds := make([]*redis.Cmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Eval(ctx, headUnlinkScript, []string{key}, manifestSize)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var chunks []string
+	for i, cmd := range cmds {
+		head, _ := cmd.Text()
+		if m, ok := parseManifest([]byte(head)); ok {
+			chunks = append(chunks, chunkKeys(keys[i], 0, m.count)...)
+		}
+	}
+
+	return r.unlink(ctx, chunks)
+}
+
+// unlink removes keys in a single pipeline of one UNLINK per key, so keys
+// never span hash slots.
+func (r *RedisStore) unlink(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Unlink(ctx, key)
+		}
+		return nil
+	})
+
+	return err
+}
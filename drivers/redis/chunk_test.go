@@ -0,0 +1,461 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/glob"
+)
+
+// fakeRedis is a map-backed MockRedisClient for the commands used by
+// chunking and streaming.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]time.Duration
+}
+
+func newFakeRedis() (*fakeRedis, *redismock.MockRedisClient) {
+	f := &fakeRedis{data: map[string]string{}, ttls: map[string]time.Duration{}}
+
+	mock := &redismock.MockRedisClient{
+		GetFunc: func(ctx context.Context, key string) *redis.StringCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			cmd := redis.NewStringCmd(ctx)
+			val, ok := f.data[key]
+			if !ok {
+				cmd.SetErr(redis.Nil)
+				return cmd
+			}
+			cmd.SetVal(val)
+			return cmd
+		},
+		SetFunc: func(ctx context.Context, key string, value any, ttl time.Duration) *redis.StatusCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.data[key] = string(value.([]byte))
+			f.ttls[key] = ttl
+			return redis.NewStatusCmd(ctx)
+		},
+		EvalFunc: func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			cmd := redis.NewCmd(ctx)
+			if script != headUnlinkScript {
+				cmd.SetErr(errors.New("ERR unknown script"))
+				return cmd
+			}
+			head := f.data[keys[0]]
+			if n := args[0].(int) + 1; len(head) > n {
+				head = head[:n]
+			}
+			delete(f.data, keys[0])
+			cmd.SetVal(head)
+			return cmd
+		},
+		UnlinkFunc: func(ctx context.Context, keys ...string) *redis.IntCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			var n int64
+			for _, key := range keys {
+				if _, ok := f.data[key]; ok {
+					delete(f.data, key)
+					n++
+				}
+			}
+			cmd := redis.NewIntCmd(ctx)
+			cmd.SetVal(n)
+			return cmd
+		},
+		GetRangeFunc: func(ctx context.Context, key string, start, end int64) *redis.StringCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			val := f.data[key]
+			if start > int64(len(val)) {
+				start = int64(len(val))
+			}
+			if end >= int64(len(val)) {
+				end = int64(len(val)) - 1
+			}
+			cmd := redis.NewStringCmd(ctx)
+			if end >= start {
+				cmd.SetVal(val[start : end+1])
+			}
+			return cmd
+		},
+		AppendFunc: func(ctx context.Context, key, value string) *redis.IntCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.data[key] += value
+			cmd := redis.NewIntCmd(ctx)
+			cmd.SetVal(int64(len(f.data[key])))
+			return cmd
+		},
+		RenameFunc: func(ctx context.Context, key, newkey string) *redis.StatusCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.data[newkey], f.ttls[newkey] = f.data[key], f.ttls[key]
+			delete(f.data, key)
+			delete(f.ttls, key)
+			return redis.NewStatusCmd(ctx)
+		},
+		PExpireFunc: func(ctx context.Context, key string, ttl time.Duration) *redis.BoolCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.ttls[key] = ttl
+			return redis.NewBoolCmd(ctx)
+		},
+		PersistFunc: func(ctx context.Context, key string) *redis.BoolCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.ttls[key] = 0
+			return redis.NewBoolCmd(ctx)
+		},
+		ExistsFunc: func(ctx context.Context, keys ...string) *redis.IntCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			var n int64
+			for _, key := range keys {
+				if _, ok := f.data[key]; ok {
+					n++
+				}
+			}
+			cmd := redis.NewIntCmd(ctx)
+			cmd.SetVal(n)
+			return cmd
+		},
+		DelFunc: func(ctx context.Context, keys ...string) *redis.IntCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			for _, key := range keys {
+				delete(f.data, key)
+			}
+			return redis.NewIntCmd(ctx)
+		},
+		ScanFunc: func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			re, err := glob.Compile(match)
+			if err != nil {
+				return redis.NewScanCmdResult(nil, 0, err)
+			}
+			var keys []string
+			for key := range f.data {
+				if re.MatchString(key) {
+					keys = append(keys, key)
+				}
+			}
+			return redis.NewScanCmdResult(keys, 0, nil)
+		},
+	}
+
+	return f, mock
+}
+
+func (f *fakeRedis) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.data))
+	for key := range f.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func newChunkedStore(client redisClient, chunkSize int) *RedisStore {
+	return &RedisStore{client: client, scanCount: DefaultScanCount, chunkSize: chunkSize}
+}
+
+func TestRedisStore_SetChunked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		value        any
+		expectedKeys []string
+	}{
+		{
+			name:         "should store small values without chunks",
+			value:        "small",
+			expectedKeys: []string{"key"},
+		},
+		{
+			name:         "should split large values into chunks and a manifest",
+			value:        strings.Repeat("x", 50),
+			expectedKeys: []string{"key", "key#chunk:0", "key#chunk:1", "key#chunk:2"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := newChunkedStore(mock, 20)
+			ctx := context.Background()
+
+			// --- Act ---
+			errSet := store.Set(ctx, "key", tt.value, time.Minute)
+			result, errGet := store.Get(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, errSet, "expected no error when setting value")
+			assert.NoError(t, errGet, "expected no error when getting value")
+			assert.Equal(t, tt.value, result, "value must be reassembled")
+			assert.Equal(t, tt.expectedKeys, fake.keys(), "stored keys must match")
+			for _, key := range tt.expectedKeys {
+				assert.Equal(t, time.Minute, fake.ttls[key], "expected identical TTLs")
+			}
+		})
+	}
+}
+
+func TestRedisStore_SetChunkedOverwrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		next         string
+		expectedKeys []string
+	}{
+		{
+			name:         "should remove stale chunks when the new value has fewer chunks",
+			next:         strings.Repeat("y", 30),
+			expectedKeys: []string{"key", "key#chunk:0", "key#chunk:1"},
+		},
+		{
+			name:         "should remove all chunks when the new value is not chunked",
+			next:         "small",
+			expectedKeys: []string{"key"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := newChunkedStore(mock, 20)
+			ctx := context.Background()
+			assert.NoError(t, store.Set(ctx, "key", strings.Repeat("x", 90), 0))
+
+			// --- Act ---
+			err := store.Set(ctx, "key", tt.next, 0)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when overwriting value")
+			assert.Equal(t, tt.expectedKeys, fake.keys(), "stored keys must match")
+			result, _ := store.Get(ctx, "key")
+			assert.Equal(t, tt.next, result, "expected the new value")
+		})
+	}
+}
+
+func TestRedisStore_GetChunked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		tamper      func(fake *fakeRedis)
+		expectedErr error
+	}{
+		{
+			name:        "should return ErrCacheMiss when a chunk is missing",
+			tamper:      func(fake *fakeRedis) { delete(fake.data, "key#chunk:1") },
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name:        "should return ErrCacheMiss when a chunk does not match the checksum",
+			tamper:      func(fake *fakeRedis) { fake.data["key#chunk:1"] = strings.Repeat("z", 20) },
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			ctx := context.Background()
+			assert.NoError(t, newChunkedStore(mock, 20).Set(ctx, "key", strings.Repeat("x", 50), 0))
+			tt.tamper(fake)
+
+			// Chunked values are reassembled even with chunking disabled.
+			store := newChunkedStore(mock, 0)
+
+			// --- Act ---
+			result, err := store.Get(ctx, "key")
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, tt.expectedErr), "error must match the expected error")
+			assert.Nil(t, result, "expected nil value on error")
+		})
+	}
+}
+
+func TestRedisStore_DeleteChunked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		delete       func(ctx context.Context, store *RedisStore) error
+		expectedKeys []string
+	}{
+		{
+			name:         "should remove chunks on Delete",
+			delete:       func(ctx context.Context, store *RedisStore) error { return store.Delete(ctx, "user:1") },
+			expectedKeys: []string{"other", "other#chunk:0", "other#chunk:1", "user:2"},
+		},
+		{
+			name: "should remove chunks on DeleteMany",
+			delete: func(ctx context.Context, store *RedisStore) error {
+				return store.DeleteMany(ctx, "user:1", "user:2", "missing")
+			},
+			expectedKeys: []string{"other", "other#chunk:0", "other#chunk:1"},
+		},
+		{
+			name:         "should remove chunks on DeleteByPattern",
+			delete:       func(ctx context.Context, store *RedisStore) error { return store.DeleteByPattern(ctx, "user:?") },
+			expectedKeys: []string{"other", "other#chunk:0", "other#chunk:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := newChunkedStore(mock, 20)
+			ctx := context.Background()
+			assert.NoError(t, store.Set(ctx, "user:1", strings.Repeat("x", 30), 0))
+			assert.NoError(t, store.Set(ctx, "user:2", "small", 0))
+			assert.NoError(t, store.Set(ctx, "other", strings.Repeat("x", 30), 0))
+
+			// --- Act ---
+			err := tt.delete(ctx, store)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when deleting")
+			assert.Equal(t, tt.expectedKeys, fake.keys(), "remaining keys must match")
+		})
+	}
+}
+
+func TestRedisStore_chunkedWritesReadOnlyTheHead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		act  func(ctx context.Context, store *RedisStore) error
+	}{
+		{
+			name: "should not fetch the previous value on Set",
+			act:  func(ctx context.Context, store *RedisStore) error { return store.Set(ctx, "key", "small", 0) },
+		},
+		{
+			name: "should not fetch the previous value on Delete",
+			act:  func(ctx context.Context, store *RedisStore) error { return store.Delete(ctx, "key") },
+		},
+		{
+			name: "should not fetch the previous value on SetFromReader",
+			act: func(ctx context.Context, store *RedisStore) error {
+				return store.SetFromReader(ctx, "key", strings.NewReader("small"), 0)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := newChunkedStore(mock, 20)
+			store.streamChunkSize = 4
+			ctx := context.Background()
+			assert.NoError(t, store.Set(ctx, "key", strings.Repeat("x", 90), 0))
+
+			get := mock.GetFunc
+			gets := 0
+			mock.GetFunc = func(ctx context.Context, key string) *redis.StringCmd {
+				gets++
+				return get(ctx, key)
+			}
+
+			// --- Act ---
+			err := tt.act(ctx, store)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, 0, gets, "expected no GET of the previous value")
+			for _, key := range fake.keys() {
+				assert.False(t, strings.Contains(key, chunkKeySep), "expected the stale chunks removed")
+			}
+		})
+	}
+}
+
+func TestChunk_parseManifest(t *testing.T) {
+	t.Parallel()
+
+	m := manifest{count: 3, size: 50, checksum: 0xdeadbeef}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		expected   manifest
+		expectedOk bool
+	}{
+		{name: "should decode an encoded manifest", data: m.encode(), expected: m, expectedOk: true},
+		{name: "should reject other envelopes", data: []byte{envelopeMagic, tagString, 'x'}, expectedOk: false},
+		{name: "should reject truncated manifests", data: m.encode()[:manifestSize-1], expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result, ok := parseManifest(tt.data)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedOk, ok, "ok must match")
+			assert.Equal(t, tt.expected, result, "manifest must match")
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package redisstore
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+)
+
+// multiNodeClient is implemented by clients that spread keys over several
+// nodes. Commands such as SCAN and FLUSHDB only act on the node they are
+// sent to, so they have to be fanned out to every node.
+type multiNodeClient interface {
+	redisClient
+
+	// forEachNode calls fn concurrently for every master/shard node.
+	forEachNode(ctx context.Context, fn func(ctx context.Context, node redisClient) error) error
+}
+
+// clusterClient adapts *redis.ClusterClient to multiNodeClient.
+type clusterClient struct {
+	*redis.ClusterClient
+}
+
+func (c clusterClient) forEachNode(ctx context.Context, fn func(ctx context.Context, node redisClient) error) error {
+	return c.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return fn(ctx, node)
+	})
+}
+
+// ringClient adapts *redis.Ring to multiNodeClient.
+type ringClient struct {
+	*redis.Ring
+}
+
+func (c ringClient) forEachNode(ctx context.Context, fn func(ctx context.Context, node redisClient) error) error {
+	return c.ForEachShard(ctx, func(ctx context.Context, node *redis.Client) error {
+		return fn(ctx, node)
+	})
+}
+
+// newClient builds the go-redis client matching the topology described by cfg.
+// Returns ErrInvalidConfig if MasterName is set without sentinel addresses
+// or the TLS settings are invalid.
+func newClient(cfg RedisConfig) (redisClient, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLS != nil {
+		var err error
+		if tlsConfig, err = cfg.TLS.build(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.Shards) > 0 {
+		return ringClient{redis.NewRing(&redis.RingOptions{
+			Addrs:                      cfg.Shards,
+			ClientName:                 cfg.ClientName,
+			Username:                   cfg.Username,
+			Password:                   cfg.Password,
+			CredentialsProviderContext: cfg.CredentialsProvider,
+			TLSConfig:                  tlsConfig,
+			DB:                         cfg.DB,
+			MaxRetries:                 cfg.MaxRetries,
+			MinRetryBackoff:            cfg.MinRetryBackoff,
+			MaxRetryBackoff:            cfg.MaxRetryBackoff,
+			DialTimeout:                cfg.DialTimeout,
+			ReadTimeout:                cfg.ReadTimeout,
+			WriteTimeout:               cfg.WriteTimeout,
+			PoolSize:                   cfg.PoolSize,
+			PoolTimeout:                cfg.PoolTimeout,
+			MinIdleConns:               cfg.MinIdleConns,
+			ConnMaxIdleTime:            cfg.ConnMaxIdleTime,
+			ConnMaxLifetime:            cfg.ConnMaxLifetime,
+		})}, nil
+	}
+
+	if cfg.MasterName != "" && len(cfg.Addrs) == 0 {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	addrs := cfg.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{cfg.Addr}
+	}
+
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:                      addrs,
+		IsClusterMode:              cfg.ClusterMode,
+		MasterName:                 cfg.MasterName,
+		SentinelUsername:           cfg.SentinelUsername,
+		SentinelPassword:           cfg.SentinelPassword,
+		ReadOnly:                   cfg.ReadOnly,
+		RouteByLatency:             cfg.RouteByLatency,
+		RouteRandomly:              cfg.RouteRandomly,
+		ClientName:                 cfg.ClientName,
+		Username:                   cfg.Username,
+		Password:                   cfg.Password,
+		CredentialsProviderContext: cfg.CredentialsProvider,
+		TLSConfig:                  tlsConfig,
+		DB:                         cfg.DB,
+		MaxRetries:                 cfg.MaxRetries,
+		MinRetryBackoff:            cfg.MinRetryBackoff,
+		MaxRetryBackoff:            cfg.MaxRetryBackoff,
+		DialTimeout:                cfg.DialTimeout,
+		ReadTimeout:                cfg.ReadTimeout,
+		WriteTimeout:               cfg.WriteTimeout,
+		PoolSize:                   cfg.PoolSize,
+		PoolTimeout:                cfg.PoolTimeout,
+		MinIdleConns:               cfg.MinIdleConns,
+		ConnMaxIdleTime:            cfg.ConnMaxIdleTime,
+		ConnMaxLifetime:            cfg.ConnMaxLifetime,
+	})
+
+	if c, ok := client.(*redis.ClusterClient); ok {
+		return clusterClient{c}, nil
+	}
+
+	return client, nil
+}
@@ -0,0 +1,237 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// mockMultiNodeClient simulates a Cluster/Ring client whose nodes are
+// independent mocks. Commands sent to the client itself hit the embedded mock.
+type mockMultiNodeClient struct {
+	*redismock.MockRedisClient
+	nodes []*redismock.MockRedisClient
+}
+
+func (m *mockMultiNodeClient) forEachNode(ctx context.Context, fn func(ctx context.Context, node redisClient) error) error {
+	for _, node := range m.nodes {
+		if err := fn(ctx, node); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestClient_newClient(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cfg          RedisConfig
+		expectedType string
+		expectedErr  error
+	}{
+		{
+			name:         "should create single-node client when only Addr is set",
+			cfg:          RedisConfig{Addr: "localhost:6379"},
+			expectedType: "*redis.Client",
+		},
+		{
+			name:         "should create single-node client when Addrs has one entry",
+			cfg:          RedisConfig{Addrs: []string{"localhost:6379"}},
+			expectedType: "*redis.Client",
+		},
+		{
+			name:         "should create cluster client when Addrs has several entries",
+			cfg:          RedisConfig{Addrs: []string{"localhost:7000", "localhost:7001"}},
+			expectedType: "redisstore.clusterClient",
+		},
+		{
+			name:         "should create cluster client when ClusterMode is forced",
+			cfg:          RedisConfig{Addrs: []string{"cluster.example:6379"}, ClusterMode: true},
+			expectedType: "redisstore.clusterClient",
+		},
+		{
+			name:         "should create sentinel failover client when MasterName is set",
+			cfg:          RedisConfig{Addrs: []string{"localhost:26379"}, MasterName: "mymaster"},
+			expectedType: "*redis.Client",
+		},
+		{
+			name:         "should create ring client when Shards are set",
+			cfg:          RedisConfig{Shards: map[string]string{"a": "localhost:6379", "b": "localhost:6380"}},
+			expectedType: "redisstore.ringClient",
+		},
+		{
+			name:        "should return ErrInvalidConfig when MasterName has no sentinel addresses",
+			cfg:         RedisConfig{Addr: "localhost:6379", MasterName: "mymaster"},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name:         "should create TLS client with credentials provider",
+			cfg:          RedisConfig{Addr: "localhost:6379", TLS: &TLSConfig{ServerName: "localhost"}, CredentialsProvider: func(ctx context.Context) (string, string, error) { return "user", "rotated", nil }},
+			expectedType: "*redis.Client",
+		},
+		{
+			name:        "should return ErrInvalidConfig when TLS settings are invalid",
+			cfg:         RedisConfig{Addr: "localhost:6379", TLS: &TLSConfig{CertFile: "cert.pem"}},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			client, err := newClient(tt.cfg)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating client")
+			assert.Equal(t, tt.expectedType, typeName(client), "client type must match the topology")
+
+			closer, ok := client.(interface{ Close() error })
+			assert.True(t, ok, "expected client to be closable")
+			assert.NoError(t, closer.Close())
+		})
+	}
+}
+
+func TestClient_multiNodeFanOut(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should flush every node when clearing", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		var mu sync.Mutex
+		flushed := 0
+		newNode := func() *redismock.MockRedisClient {
+			return &redismock.MockRedisClient{
+				FlushDBFunc: func(ctx context.Context) *redis.StatusCmd {
+					mu.Lock()
+					flushed++
+					mu.Unlock()
+					return redis.NewStatusCmd(ctx)
+				},
+			}
+		}
+
+		client := &mockMultiNodeClient{
+			MockRedisClient: &redismock.MockRedisClient{},
+			nodes:           []*redismock.MockRedisClient{newNode(), newNode(), newNode()},
+		}
+		store := &RedisStore{client: client}
+
+		// --- Act ---
+		err := store.Clear(context.Background())
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when clearing all nodes")
+		assert.Equal(t, 3, flushed, "expected FLUSHDB on every node")
+	})
+
+	t.Run("should scan and delete on every node when deleting by pattern", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		newNode := func(keys []string, deleted *[]string) *redismock.MockRedisClient {
+			return &redismock.MockRedisClient{
+				ScanFunc: func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					return redis.NewScanCmdResult(keys, 0, nil)
+				},
+				UnlinkFunc: func(ctx context.Context, keys ...string) *redis.IntCmd {
+					*deleted = append(*deleted, keys...)
+					return redis.NewIntCmd(ctx)
+				},
+			}
+		}
+
+		var deletedA, deletedB []string
+		client := &mockMultiNodeClient{
+			MockRedisClient: &redismock.MockRedisClient{},
+			nodes: []*redismock.MockRedisClient{
+				newNode([]string{"user:1"}, &deletedA),
+				newNode([]string{"user:2", "user:3"}, &deletedB),
+			},
+		}
+		store := &RedisStore{client: client}
+
+		// --- Act ---
+		err := store.DeleteByPattern(context.Background(), "user:*")
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when deleting across nodes")
+		assert.Equal(t, []string{"user:1"}, deletedA, "expected node A keys to be deleted on node A")
+		assert.Equal(t, []string{"user:2", "user:3"}, deletedB, "expected node B keys to be deleted on node B")
+	})
+
+	t.Run("should return the first node error when deleting by pattern", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		client := &mockMultiNodeClient{
+			MockRedisClient: &redismock.MockRedisClient{},
+			nodes: []*redismock.MockRedisClient{{
+				ScanFunc: func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					return redis.NewScanCmdResult(nil, 0, errors.New("scan error"))
+				},
+			}},
+		}
+		store := &RedisStore{client: client}
+
+		// --- Act ---
+		err := store.DeleteByPattern(context.Background(), "user:*")
+
+		// --- Assert ---
+		assert.EqualError(t, errors.New("scan error"), err, "error must match the node error")
+	})
+
+	t.Run("should delete keys one by one so they never span slots", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		var calls [][]string
+		client := &mockMultiNodeClient{
+			MockRedisClient: &redismock.MockRedisClient{
+				DelFunc: func(ctx context.Context, keys ...string) *redis.IntCmd {
+					calls = append(calls, keys)
+					return redis.NewIntCmd(ctx)
+				},
+			},
+		}
+		store := &RedisStore{client: client}
+
+		// --- Act ---
+		err := store.DeleteMany(context.Background(), "a", "b", "c")
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when deleting many keys")
+		assert.Equal(t, [][]string{{"a"}, {"b"}, {"c"}}, calls, "expected one DEL per key")
+	})
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case *redis.Client:
+		return "*redis.Client"
+	case clusterClient:
+		return "redisstore.clusterClient"
+	case ringClient:
+		return "redisstore.ringClient"
+	default:
+		return "unknown"
+	}
+}
@@ -1,17 +1,113 @@
 package redisstore
 
 import (
+	"context"
 	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+const (
+	// DefaultScanCount is the SCAN COUNT hint used by DeleteByPattern.
+	DefaultScanCount = 1000
+
+	// DefaultStreamChunkSize is the piece size used by GetReader and SetFromReader.
+	DefaultStreamChunkSize = 1 << 20
 )
 
 // RedisConfig keeps the settings to set up Redis connection.
 // This is a simplified version of redis.Options, keeping only
 // the most useful fields for production workloads.
+//
+// The topology is selected from the fields that are set:
+//   - Shards set: a sharded Ring client.
+//   - MasterName set: a Sentinel-backed failover client using Addrs as sentinels.
+//   - Two or more Addrs, or ClusterMode: a Redis Cluster client using Addrs as seeds.
+//   - Otherwise: a single-node client connecting to Addr (or the only entry in Addrs).
 type RedisConfig struct {
 
 	// Addr is the address formatted as host:port.
 	Addr string
 
+	// Addrs is a seed list of host:port addresses of cluster nodes,
+	// or the sentinel addresses when MasterName is set.
+	Addrs []string
+
+	// ClusterMode forces a Redis Cluster client even when Addrs holds
+	// a single configuration endpoint.
+	ClusterMode bool
+
+	// MasterName is the Sentinel master name. Setting it selects a
+	// Sentinel-backed failover client.
+	MasterName string
+
+	// SentinelUsername and SentinelPassword authenticate against the
+	// sentinels themselves (not the master).
+	SentinelUsername string
+	SentinelPassword string
+
+	// Shards maps shard names to host:port addresses for a client-side
+	// sharded Ring. Keys are distributed across shards with consistent hashing.
+	Shards map[string]string
+
+	// ReadOnly enables read-only commands on replica nodes (cluster only).
+	ReadOnly bool
+
+	// RouteByLatency routes read-only commands to the closest master or
+	// replica node (cluster only). Implies ReadOnly.
+	RouteByLatency bool
+
+	// RouteRandomly routes read-only commands to a random master or
+	// replica node (cluster only). Implies ReadOnly.
+	RouteRandomly bool
+
+	// CredentialsProvider, when set, is called every time a new connection
+	// authenticates and takes precedence over Username and Password.
+	// Use it to pick up rotated passwords without recreating the store.
+	CredentialsProvider func(ctx context.Context) (username string, password string, err error)
+
+	// TLS enables TLS for every connection when non-nil.
+	//
+	// default: nil (plain TCP)
+	TLS *TLSConfig
+
+	// ScanCount is the COUNT hint passed to SCAN by DeleteByPattern, i.e.
+	// roughly how many keys are examined and unlinked per batch.
+	//
+	// default: 1000
+	ScanCount int64
+
+	// ScriptedPatternDelete makes DeleteByPattern scan and unlink each batch
+	// in a server-side Lua script, saving a round-trip per batch. The script
+	// only touches keys served by the node it runs on.
+	ScriptedPatternDelete bool
+
+	// ChunkSize splits values whose encoded size exceeds it into chunks of
+	// at most ChunkSize bytes, stored under "<key>#chunk:<n>" next to a
+	// small manifest at the key itself. Large values then no longer block
+	// Redis for the duration of a single multi-megabyte command. Values
+	// written with SetFromReader are split as they are read.
+	//
+	// On Redis Cluster, give keys a hash tag such as "{user:1}" so the
+	// chunks share the manifest's slot and are written in a single
+	// transaction.
+	//
+	// default: 0 (chunking disabled)
+	ChunkSize int
+
+	// StreamChunkSize is the size of the GETRANGE and APPEND pieces in which
+	// GetReader and SetFromReader transfer values.
+	//
+	// default: 1 MiB
+	StreamChunkSize int
+
+	// Codec serializes values that have no dedicated type tag (structs,
+	// maps, slices). Values written with another registered codec remain
+	// readable, which allows switching codecs on a live cache.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+
 	// ClientName will execute the `CLIENT SETNAME ClientName` command for each conn.
 	ClientName string
 
@@ -101,3 +197,36 @@ type RedisConfig struct {
 	// default: 0 (no limit)
 	ConnMaxLifetime time.Duration
 }
+
+// TLSConfig keeps the TLS settings for Redis connections.
+type TLSConfig struct {
+
+	// CAFile is the path to a PEM bundle of certificate authorities used
+	// to verify the server certificate.
+	//
+	// default: the system root pool
+	CAFile string
+
+	// CAPEM is a PEM bundle of certificate authorities, for when the bundle
+	// is delivered as a secret rather than a file. It is combined with CAFile.
+	CAPEM []byte
+
+	// CertFile and KeyFile are the paths to a PEM client certificate and
+	// its private key for mutual TLS. Both must be set together.
+	CertFile string
+	KeyFile  string
+
+	// ServerName is used to verify the hostname on the server certificate.
+	//
+	// default: the host part of the address being dialed
+	ServerName string
+
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13.
+	//
+	// default: tls.VersionTLS12
+	MinVersion uint16
+
+	// InsecureSkipVerify disables server certificate verification.
+	// Only use it for local development.
+	InsecureSkipVerify bool
+}
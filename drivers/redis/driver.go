@@ -0,0 +1,50 @@
+package redisstore
+
+import (
+	"fmt"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the redis driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "redis"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of RedisConfig. A "url" option is parsed with ParseURL first; the
+// other options then override the settings it yields.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config RedisConfig
+
+	if rawURL, ok := options["url"]; ok {
+		s, isString := rawURL.(string)
+		if !isString {
+			return nil, &omnicache.ConfigError{Path: "url", Err: fmt.Errorf("expected a string, got %T", rawURL)}
+		}
+
+		parsed, err := ParseURL(s)
+		if err != nil {
+			return nil, &omnicache.ConfigError{Path: "url", Err: err}
+		}
+		config = parsed
+
+		rest := make(map[string]any, len(options)-1)
+		for name, value := range options {
+			if name != "url" {
+				rest[name] = value
+			}
+		}
+		options = rest
+	}
+
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return NewRedisStore(config)
+}
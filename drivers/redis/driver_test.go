@@ -0,0 +1,74 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestRedisStore_newFromOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		options       map[string]any
+		expectedAddr  string
+		expectedDB    int
+		expectedScan  int64
+		expectedError string
+	}{
+		{
+			name:         "should decode options",
+			options:      map[string]any{"addr": "cache.internal:6380", "db": "1", "scan_count": 50},
+			expectedAddr: "cache.internal:6380",
+			expectedDB:   1,
+			expectedScan: 50,
+		},
+		{
+			name:         "should apply options over the url",
+			options:      map[string]any{"url": "redis://cache.internal:6380/2", "db": 3},
+			expectedAddr: "cache.internal:6380",
+			expectedDB:   3,
+			expectedScan: DefaultScanCount,
+		},
+		{
+			name:          "should name the url option when it is invalid",
+			options:       map[string]any{"url": "http://cache.internal"},
+			expectedError: `cache: invalid config: url: redis url: unsupported scheme "http"`,
+		},
+		{
+			name:          "should reject options that cannot be configured",
+			options:       map[string]any{"codec": "json"},
+			expectedError: "cache: invalid config: codec: option cannot be set from configuration",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := newFromOptions(tt.options)
+
+			// --- Assert ---
+			if tt.expectedError != "" {
+				assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+				assert.Equal(t, tt.expectedError, err.Error(), "expected the error to name the option")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			redisStore := store.(*RedisStore)
+			client := redisStore.client.(*redis.Client)
+			assert.Equal(t, tt.expectedAddr, client.Options().Addr, "expected the address")
+			assert.Equal(t, tt.expectedDB, client.Options().DB, "expected the database")
+			assert.Equal(t, tt.expectedScan, redisStore.scanCount, "expected the scan count")
+			assert.NoError(t, store.Close(context.Background()), "expected no error when closing")
+		})
+	}
+}
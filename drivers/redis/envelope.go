@@ -0,0 +1,296 @@
+package redisstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+// envelopeMagic starts every value written by Set. JSON text never begins
+// with this byte, so values written before envelopes were introduced are
+// still recognized and returned as their raw JSON string.
+const envelopeMagic byte = 0x1e
+
+// Type tags stored after envelopeMagic.
+const (
+	tagNil        byte = 'N'
+	tagString     byte = 's'
+	tagBytes      byte = 'b'
+	tagBool       byte = 't'
+	tagInt        byte = 'i'
+	tagInt8       byte = '1'
+	tagInt16      byte = '2'
+	tagInt32      byte = '4'
+	tagInt64      byte = '8'
+	tagUint       byte = 'u'
+	tagUint8      byte = 'B'
+	tagUint16     byte = 'W'
+	tagUint32     byte = 'D'
+	tagUint64     byte = 'Q'
+	tagFloat32    byte = 'f'
+	tagFloat64    byte = 'F'
+	tagTime       byte = 'T'
+	tagDuration   byte = 'd'
+	tagRegistered byte = 'R'
+	tagEncoded    byte = 'E'
+	tagChunked    byte = 'C'
+)
+
+var errMalformedEnvelope = errors.New("redis: malformed value envelope")
+
+// registry maps registered names to types and back.
+var registry = struct {
+	sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}{
+	byName: make(map[string]reflect.Type),
+	byType: make(map[reflect.Type]string),
+}
+
+// Register records the concrete type of value so Get returns values of
+// that type instead of a codec.Raw payload. The type is registered under its
+// package path and name; use RegisterName to choose a stable name.
+// Like gob.Register, it panics if the type or name is already registered
+// to something else.
+func Register(value any) {
+	t := reflect.TypeOf(value)
+	RegisterName(registeredName(t), value)
+}
+
+// RegisterName is like Register but uses the given name, which must be
+// shared by every process reading and writing the type.
+func RegisterName(name string, value any) {
+	if name == "" {
+		panic("redisstore: attempt to register empty name")
+	}
+
+	t := reflect.TypeOf(value)
+	if t == nil {
+		panic("redisstore: attempt to register nil value")
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if existing, ok := registry.byName[name]; ok && existing != t {
+		panic(fmt.Sprintf("redisstore: registering duplicate types for %q: %s != %s", name, existing, t))
+	}
+	if existing, ok := registry.byType[t]; ok && existing != name {
+		panic(fmt.Sprintf("redisstore: registering duplicate names for %s: %q != %q", t, existing, name))
+	}
+
+	registry.byName[name] = t
+	registry.byType[t] = name
+}
+
+func registeredName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+
+	if t.Kind() == reflect.Pointer {
+		return "*" + registeredName(t.Elem())
+	}
+
+	if t.Name() == "" || t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
+
+// encodeValue wraps value in a self-describing envelope. Values without a
+// dedicated tag are encoded with c, header byte included, so they can be
+// read back after the store switches to another codec.
+func encodeValue(value any, c codec.Codec) ([]byte, error) {
+	if c == nil {
+		c = codec.Default
+	}
+
+	buf := []byte{envelopeMagic, 0}
+
+	switch v := value.(type) {
+	case nil:
+		buf[1] = tagNil
+	case string:
+		buf[1] = tagString
+		buf = append(buf, v...)
+	case []byte:
+		buf[1] = tagBytes
+		buf = append(buf, v...)
+	case bool:
+		buf[1] = tagBool
+		buf = strconv.AppendBool(buf, v)
+	case int:
+		buf[1] = tagInt
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		buf[1] = tagInt8
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		buf[1] = tagInt16
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		buf[1] = tagInt32
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		buf[1] = tagInt64
+		buf = strconv.AppendInt(buf, v, 10)
+	case uint:
+		buf[1] = tagUint
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		buf[1] = tagUint8
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		buf[1] = tagUint16
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		buf[1] = tagUint32
+		buf = strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		buf[1] = tagUint64
+		buf = strconv.AppendUint(buf, v, 10)
+	case float32:
+		buf[1] = tagFloat32
+		buf = strconv.AppendFloat(buf, float64(v), 'g', -1, 32)
+	case float64:
+		buf[1] = tagFloat64
+		buf = strconv.AppendFloat(buf, v, 'g', -1, 64)
+	case time.Time:
+		buf[1] = tagTime
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+	case time.Duration:
+		buf[1] = tagDuration
+		buf = strconv.AppendInt(buf, int64(v), 10)
+	default:
+		data, err := codec.Encode(c, value)
+		if err != nil {
+			return nil, err
+		}
+
+		registry.RLock()
+		name, ok := registry.byType[reflect.TypeOf(value)]
+		registry.RUnlock()
+
+		if ok {
+			buf[1] = tagRegistered
+			buf = append(buf, name...)
+			buf = append(buf, 0)
+		} else {
+			buf[1] = tagEncoded
+		}
+		buf = append(buf, data...)
+	}
+
+	return buf, nil
+}
+
+// decodeValue reverses encodeValue. Data without an envelope is returned
+// as a string. Unregistered composite values, and registered values whose
+// name is unknown to this process, are returned as codec.Raw.
+func decodeValue(data []byte) (any, error) {
+	if len(data) == 0 || data[0] != envelopeMagic {
+		return string(data), nil
+	}
+
+	if len(data) < 2 {
+		return nil, errMalformedEnvelope
+	}
+
+	tag, payload := data[1], data[2:]
+
+	switch tag {
+	case tagNil:
+		return nil, nil
+	case tagString:
+		return string(payload), nil
+	case tagEncoded:
+		return codec.Raw(append([]byte{}, payload...)), nil
+	case tagBytes:
+		return append([]byte{}, payload...), nil
+	case tagBool:
+		return wrapParse(strconv.ParseBool(string(payload)))
+	case tagInt:
+		n, err := strconv.ParseInt(string(payload), 10, strconv.IntSize)
+		return wrapParse(int(n), err)
+	case tagInt8:
+		n, err := strconv.ParseInt(string(payload), 10, 8)
+		return wrapParse(int8(n), err)
+	case tagInt16:
+		n, err := strconv.ParseInt(string(payload), 10, 16)
+		return wrapParse(int16(n), err)
+	case tagInt32:
+		n, err := strconv.ParseInt(string(payload), 10, 32)
+		return wrapParse(int32(n), err)
+	case tagInt64:
+		return wrapParse(strconv.ParseInt(string(payload), 10, 64))
+	case tagUint:
+		n, err := strconv.ParseUint(string(payload), 10, strconv.IntSize)
+		return wrapParse(uint(n), err)
+	case tagUint8:
+		n, err := strconv.ParseUint(string(payload), 10, 8)
+		return wrapParse(uint8(n), err)
+	case tagUint16:
+		n, err := strconv.ParseUint(string(payload), 10, 16)
+		return wrapParse(uint16(n), err)
+	case tagUint32:
+		n, err := strconv.ParseUint(string(payload), 10, 32)
+		return wrapParse(uint32(n), err)
+	case tagUint64:
+		return wrapParse(strconv.ParseUint(string(payload), 10, 64))
+	case tagFloat32:
+		f, err := strconv.ParseFloat(string(payload), 32)
+		return wrapParse(float32(f), err)
+	case tagFloat64:
+		return wrapParse(strconv.ParseFloat(string(payload), 64))
+	case tagTime:
+		return wrapParse(time.Parse(time.RFC3339Nano, string(payload)))
+	case tagDuration:
+		n, err := strconv.ParseInt(string(payload), 10, 64)
+		return wrapParse(time.Duration(n), err)
+	case tagRegistered:
+		return decodeRegistered(payload)
+	default:
+		return nil, errMalformedEnvelope
+	}
+}
+
+func decodeRegistered(payload []byte) (any, error) {
+	i := bytes.IndexByte(payload, 0)
+	if i < 0 {
+		return nil, errMalformedEnvelope
+	}
+
+	name, data := string(payload[:i]), payload[i+1:]
+
+	registry.RLock()
+	t, ok := registry.byName[name]
+	registry.RUnlock()
+
+	if !ok {
+		return codec.Raw(append([]byte{}, data...)), nil
+	}
+
+	ptr := reflect.New(t)
+	if err := codec.Decode(data, nil, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("redis: decode %s: %w", name, err)
+	}
+
+	return ptr.Elem().Interface(), nil
+}
+
+func wrapParse[T any](v T, err error) (any, error) {
+	if err != nil {
+		return nil, errMalformedEnvelope
+	}
+
+	return v, nil
+}
@@ -0,0 +1,254 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache/codec"
+	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+type envelopeProfile struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type envelopeUnregistered struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	RegisterName("redisstore.envelopeProfile", envelopeProfile{})
+	Register(&envelopeProfile{})
+}
+
+func TestEnvelope_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{name: "should round-trip nil", value: nil, expected: nil},
+		{name: "should round-trip string without JSON quoting", value: "hello", expected: "hello"},
+		{name: "should round-trip empty string", value: "", expected: ""},
+		{name: "should round-trip []byte as bytes", value: []byte{0x00, 0xff, 'a'}, expected: []byte{0x00, 0xff, 'a'}},
+		{name: "should round-trip bool", value: true, expected: true},
+		{name: "should round-trip int", value: -42, expected: -42},
+		{name: "should round-trip int8", value: int8(-8), expected: int8(-8)},
+		{name: "should round-trip int16", value: int16(16), expected: int16(16)},
+		{name: "should round-trip int32", value: int32(32), expected: int32(32)},
+		{name: "should round-trip int64", value: int64(1 << 62), expected: int64(1 << 62)},
+		{name: "should round-trip uint", value: uint(7), expected: uint(7)},
+		{name: "should round-trip uint8", value: uint8(255), expected: uint8(255)},
+		{name: "should round-trip uint16", value: uint16(65535), expected: uint16(65535)},
+		{name: "should round-trip uint32", value: uint32(1 << 31), expected: uint32(1 << 31)},
+		{name: "should round-trip uint64", value: uint64(1 << 63), expected: uint64(1 << 63)},
+		{name: "should round-trip float32", value: float32(1.5), expected: float32(1.5)},
+		{name: "should round-trip float64", value: 3.14159, expected: 3.14159},
+		{name: "should round-trip time.Time", value: now, expected: now},
+		{name: "should round-trip time.Duration", value: 90 * time.Second, expected: 90 * time.Second},
+		{
+			name:     "should restore registered struct type",
+			value:    envelopeProfile{Name: "ada", Age: 36},
+			expected: envelopeProfile{Name: "ada", Age: 36},
+		},
+		{
+			name:     "should restore registered pointer type",
+			value:    &envelopeProfile{Name: "ada", Age: 36},
+			expected: &envelopeProfile{Name: "ada", Age: 36},
+		},
+		{
+			name:     "should return codec.Raw for unregistered struct",
+			value:    envelopeUnregistered{Name: "bob"},
+			expected: codec.Raw("\x01" + `{"name":"bob"}`),
+		},
+		{
+			name:     "should return codec.Raw for maps",
+			value:    map[string]int{"a": 1},
+			expected: codec.Raw("\x01" + `{"a":1}`),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			data, err := encodeValue(tt.value, codec.Default)
+			assert.NoError(t, err, "expected no error when encoding")
+
+			result, err := decodeValue(data)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when decoding")
+			assert.Equal(t, tt.expected, result, "decoded value must match the original")
+		})
+	}
+}
+
+func TestEnvelope_decodeValue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expected    any
+		expectedErr error
+	}{
+		{
+			name:     "should return legacy JSON values as raw string",
+			data:     []byte(`"legacy"`),
+			expected: `"legacy"`,
+		},
+		{
+			name:     "should return empty legacy value as empty string",
+			data:     []byte{},
+			expected: "",
+		},
+		{
+			name:     "should return codec.Raw when registered name is unknown",
+			data:     append([]byte{envelopeMagic, tagRegistered}, "other.Type\x00\x01{\"x\":1}"...),
+			expected: codec.Raw("\x01{\"x\":1}"),
+		},
+		{
+			name:        "should fail on envelope without tag",
+			data:        []byte{envelopeMagic},
+			expectedErr: errMalformedEnvelope,
+		},
+		{
+			name:        "should fail on unknown tag",
+			data:        []byte{envelopeMagic, '?'},
+			expectedErr: errMalformedEnvelope,
+		},
+		{
+			name:        "should fail on invalid number payload",
+			data:        append([]byte{envelopeMagic, tagInt}, "abc"...),
+			expectedErr: errMalformedEnvelope,
+		},
+		{
+			name:        "should fail on registered payload without name separator",
+			data:        append([]byte{envelopeMagic, tagRegistered}, "noseparator"...),
+			expectedErr: errMalformedEnvelope,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result, err := decodeValue(tt.data)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when decoding")
+			assert.Equal(t, tt.expected, result, "decoded value must match")
+		})
+	}
+}
+
+func TestEnvelope_RegisterName(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should panic when name is registered to another type", func(t *testing.T) {
+		t.Parallel()
+
+		defer func() {
+			assert.NotNil(t, recover(), "expected panic on duplicate name")
+		}()
+
+		RegisterName("redisstore.envelopeProfile", envelopeUnregistered{})
+	})
+
+	t.Run("should allow registering the same type and name twice", func(t *testing.T) {
+		t.Parallel()
+
+		RegisterName("redisstore.envelopeProfile", envelopeProfile{})
+	})
+}
+
+func TestRedisStore_SetGetTypePreserving(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	stored := map[string]string{}
+	mock := &redismock.MockRedisClient{
+		SetFunc: func(ctx context.Context, key string, value any, ttl time.Duration) *redis.StatusCmd {
+			stored[key] = string(value.([]byte))
+			return redis.NewStatusCmd(ctx)
+		},
+		GetFunc: func(ctx context.Context, key string) *redis.StringCmd {
+			cmd := redis.NewStringCmd(ctx)
+			cmd.SetVal(stored[key])
+			return cmd
+		},
+	}
+	store := &RedisStore{client: mock}
+	ctx := context.Background()
+
+	// --- Act ---
+	assert.NoError(t, store.Set(ctx, "str", "hello", 0))
+	assert.NoError(t, store.Set(ctx, "bytes", []byte("raw"), 0))
+	assert.NoError(t, store.Set(ctx, "profile", envelopeProfile{Name: "ada", Age: 36}, 0))
+
+	str, errStr := store.Get(ctx, "str")
+	bytesVal, errBytes := store.Get(ctx, "bytes")
+	profile, errProfile := store.Get(ctx, "profile")
+
+	// --- Assert ---
+	assert.NoError(t, errStr)
+	assert.NoError(t, errBytes)
+	assert.NoError(t, errProfile)
+	assert.Equal(t, "hello", str, "expected string without JSON quotes")
+	assert.Equal(t, []byte("raw"), bytesVal, "expected []byte to round-trip as bytes")
+	assert.Equal(t, envelopeProfile{Name: "ada", Age: 36}, profile, "expected registered struct type")
+}
+
+func TestEnvelope_codecs(t *testing.T) {
+	t.Parallel()
+
+	profile := envelopeProfile{Name: "ada", Age: 36}
+
+	tests := []struct {
+		name  string
+		codec codec.Codec
+	}{
+		{name: "should round-trip registered type with sonic", codec: codec.Sonic},
+		{name: "should round-trip registered type with encoding/json", codec: codec.JSON},
+		{name: "should round-trip registered type with gob", codec: codec.Gob},
+		{name: "should round-trip registered type with msgpack", codec: codec.MsgPack},
+		{name: "should round-trip registered type with cbor", codec: codec.CBOR},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			// Written by one codec, read back by a store configured with another.
+			data, err := encodeValue(profile, tt.codec)
+			assert.NoError(t, err, "expected no error when encoding")
+
+			// --- Act ---
+			result, err := decodeValue(data)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when decoding")
+			assert.Equal(t, profile, result, "decoded value must match the original")
+		})
+	}
+}
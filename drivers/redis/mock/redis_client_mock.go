@@ -9,13 +9,87 @@ import (
 
 // MockRedisClient implements a minimal subset of redis.Cmdable for testing.
 type MockRedisClient struct {
-	FlushDBFunc func(ctx context.Context) *redis.StatusCmd
-	DelFunc     func(ctx context.Context, keys ...string) *redis.IntCmd
-	ScanFunc    func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
-	GetFunc     func(ctx context.Context, key string) *redis.StringCmd
-	ExistsFunc  func(ctx context.Context, keys ...string) *redis.IntCmd
-	SetFunc     func(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
-	CloseFunc   func() error
+	FlushDBFunc  func(ctx context.Context) *redis.StatusCmd
+	DelFunc      func(ctx context.Context, keys ...string) *redis.IntCmd
+	ScanFunc     func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	GetFunc      func(ctx context.Context, key string) *redis.StringCmd
+	ExistsFunc   func(ctx context.Context, keys ...string) *redis.IntCmd
+	SetFunc      func(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+	GetRangeFunc func(ctx context.Context, key string, start, end int64) *redis.StringCmd
+	AppendFunc   func(ctx context.Context, key, value string) *redis.IntCmd
+	RenameFunc   func(ctx context.Context, key, newkey string) *redis.StatusCmd
+	PExpireFunc  func(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd
+	PersistFunc  func(ctx context.Context, key string) *redis.BoolCmd
+	UnlinkFunc   func(ctx context.Context, keys ...string) *redis.IntCmd
+	EvalFunc     func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd
+	EvalShaFunc  func(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd
+	PingFunc     func(ctx context.Context) *redis.StatusCmd
+	CloseFunc    func() error
+}
+
+// MockPipeliner queues commands for MockRedisClient.Pipelined. Only the
+// commands the store pipelines are implemented; they are answered by the
+// matching MockRedisClient func when the pipeline is executed.
+type MockPipeliner struct {
+	redis.Pipeliner
+	client *MockRedisClient
+	cmds   []redis.Cmder
+}
+
+func (p *MockPipeliner) Unlink(ctx context.Context, keys ...string) *redis.IntCmd {
+	cmd := p.client.Unlink(ctx, keys...)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) Get(ctx context.Context, key string) *redis.StringCmd {
+	cmd := p.client.Get(ctx, key)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) GetRange(ctx context.Context, key string, start, end int64) *redis.StringCmd {
+	cmd := p.client.GetRange(ctx, key, start, end)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) Set(ctx context.Context, key string, value any, ttl time.Duration) *redis.StatusCmd {
+	cmd := p.client.Set(ctx, key, value, ttl)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	cmd := p.client.Eval(ctx, script, keys, args...)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) Rename(ctx context.Context, key, newkey string) *redis.StatusCmd {
+	cmd := p.client.Rename(ctx, key, newkey)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) PExpire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	cmd := p.client.PExpire(ctx, key, expiration)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
+}
+
+func (p *MockPipeliner) Persist(ctx context.Context, key string) *redis.BoolCmd {
+	cmd := p.client.Persist(ctx, key)
+	p.cmds = append(p.cmds, cmd)
+
+	return cmd
 }
 
 func (m *MockRedisClient) FlushDB(ctx context.Context) *redis.StatusCmd {
@@ -66,6 +140,100 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value any, ttl ti
 	return redis.NewStatusCmd(ctx)
 }
 
+func (m *MockRedisClient) GetRange(ctx context.Context, key string, start, end int64) *redis.StringCmd {
+	if m.GetRangeFunc != nil {
+		return m.GetRangeFunc(ctx, key, start, end)
+	}
+
+	return redis.NewStringCmd(ctx)
+}
+
+func (m *MockRedisClient) Append(ctx context.Context, key, value string) *redis.IntCmd {
+	if m.AppendFunc != nil {
+		return m.AppendFunc(ctx, key, value)
+	}
+
+	return redis.NewIntCmd(ctx)
+}
+
+func (m *MockRedisClient) Rename(ctx context.Context, key, newkey string) *redis.StatusCmd {
+	if m.RenameFunc != nil {
+		return m.RenameFunc(ctx, key, newkey)
+	}
+
+	return redis.NewStatusCmd(ctx)
+}
+
+func (m *MockRedisClient) PExpire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	if m.PExpireFunc != nil {
+		return m.PExpireFunc(ctx, key, expiration)
+	}
+
+	return redis.NewBoolCmd(ctx)
+}
+
+func (m *MockRedisClient) Persist(ctx context.Context, key string) *redis.BoolCmd {
+	if m.PersistFunc != nil {
+		return m.PersistFunc(ctx, key)
+	}
+
+	return redis.NewBoolCmd(ctx)
+}
+
+func (m *MockRedisClient) Unlink(ctx context.Context, keys ...string) *redis.IntCmd {
+	if m.UnlinkFunc != nil {
+		return m.UnlinkFunc(ctx, keys...)
+	}
+
+	return redis.NewIntCmd(ctx)
+}
+
+// Pipelined runs fn against a MockPipeliner and, like go-redis, returns
+// the queued commands together with the first command error.
+func (m *MockRedisClient) Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	pipe := &MockPipeliner{client: m}
+	if err := fn(pipe); err != nil {
+		return nil, err
+	}
+
+	for _, cmd := range pipe.cmds {
+		if err := cmd.Err(); err != nil {
+			return pipe.cmds, err
+		}
+	}
+
+	return pipe.cmds, nil
+}
+
+// TxPipelined behaves like Pipelined; the mock does not model MULTI/EXEC.
+func (m *MockRedisClient) TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error) {
+	return m.Pipelined(ctx, fn)
+}
+
+func (m *MockRedisClient) Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+	if m.EvalFunc != nil {
+		return m.EvalFunc(ctx, script, keys, args...)
+	}
+
+	return redis.NewCmd(ctx)
+}
+
+func (m *MockRedisClient) EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+	if m.EvalShaFunc != nil {
+		return m.EvalShaFunc(ctx, sha1, keys, args...)
+	}
+
+	return redis.NewCmd(ctx)
+}
+
+func (m *MockRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
+	if m.PingFunc != nil {
+		return m.PingFunc(ctx)
+	}
+
+	return redis.NewStatusCmd(ctx)
+}
+
 func (m *MockRedisClient) Close() error {
 	if m.CloseFunc != nil {
 		return m.CloseFunc()
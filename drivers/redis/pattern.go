@@ -0,0 +1,152 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanUnlinkScript runs one SCAN step and unlinks the keys it returns.
+// ARGV: cursor, match pattern, count. Returns {next cursor, keys removed}.
+const scanUnlinkScript = `
+local res = redis.call('SCAN', ARGV[1], 'MATCH', ARGV[2], 'COUNT', ARGV[3])
+local removed = 0
+for _, key in ipairs(res[2]) do
+	removed = removed + redis.call('UNLINK', key)
+end
+return {res[1], removed}
+`
+
+var scanUnlinkSHA = redis.NewScript(scanUnlinkScript).Hash()
+
+// DeleteByPatternCount removes all cache entries whose keys match the given
+// pattern and returns how many were removed.
+//
+// Keys are scanned in batches of ScanCount and each batch is removed with a
+// pipeline of UNLINK commands, so memory is reclaimed in the background and
+// large values never block Redis. With ScriptedPatternDelete each batch is
+// scanned and unlinked by a Lua script instead.
+//
+// The context is checked between batches. On cancellation the number of keys
+// removed so far is returned together with ctx.Err().
+// On Cluster and Ring clients every master/shard is scanned, since SCAN
+// only sees the keys of the node it is sent to.
+// With chunking enabled, the chunks of matching keys are removed by a second
+// scan and included in the count.
+func (r *RedisStore) DeleteByPatternCount(ctx context.Context, pattern string) (int64, error) {
+	var removed int64
+
+	patterns := []string{pattern}
+	if r.chunkSize > 0 {
+		// Chunk keys of matching keys match the pattern followed by the
+		// chunk suffix, whether or not they match the pattern itself.
+		patterns = append(patterns, pattern+chunkKeySep+"*")
+	}
+
+	err := r.forEachNode(ctx, func(ctx context.Context, node redisClient) error {
+		for _, pattern := range patterns {
+			n, err := r.unlinkByPattern(ctx, node, pattern)
+			atomic.AddInt64(&removed, n)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return atomic.LoadInt64(&removed), err
+}
+
+// unlinkByPattern removes the keys matching pattern from a single node.
+func (r *RedisStore) unlinkByPattern(ctx context.Context, node redisClient, pattern string) (int64, error) {
+	count := r.scanCount
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	step := scanUnlinkBatch
+	if r.scriptedPatternDelete {
+		step = scanUnlinkBatchScripted
+	}
+
+	var removed int64
+	var cursor uint64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		next, n, err := step(ctx, node, cursor, pattern, count)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+
+		if next == 0 {
+			return removed, nil
+		}
+		cursor = next
+	}
+}
+
+// scanUnlinkBatch runs one SCAN step and unlinks the returned keys in a
+// single pipeline of one UNLINK per key, so keys never span hash slots.
+func scanUnlinkBatch(ctx context.Context, node redisClient, cursor uint64, pattern string, count int64) (uint64, int64, error) {
+	keys, next, err := node.Scan(ctx, cursor, pattern, count).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(keys) == 0 {
+		return next, 0, nil
+	}
+
+	cmds, err := node.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Unlink(ctx, key)
+		}
+		return nil
+	})
+
+	var removed int64
+	for _, cmd := range cmds {
+		if c, ok := cmd.(*redis.IntCmd); ok && c.Err() == nil {
+			removed += c.Val()
+		}
+	}
+
+	return next, removed, err
+}
+
+// scanUnlinkBatchScripted runs one SCAN step and its UNLINKs server-side.
+// The script is called by SHA and loaded with EVAL on first use.
+func scanUnlinkBatchScripted(ctx context.Context, node redisClient, cursor uint64, pattern string, count int64) (uint64, int64, error) {
+	args := []any{strconv.FormatUint(cursor, 10), pattern, count}
+
+	res, err := node.EvalSha(ctx, scanUnlinkSHA, nil, args...).Slice()
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		res, err = node.Eval(ctx, scanUnlinkScript, nil, args...).Slice()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(res) != 2 {
+		return 0, 0, fmt.Errorf("redis: unexpected script reply %v", res)
+	}
+
+	cursorStr, _ := res[0].(string)
+	next, err := strconv.ParseUint(cursorStr, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("redis: unexpected script cursor %v", res[0])
+	}
+
+	removed, _ := res[1].(int64)
+
+	return next, removed, nil
+}
@@ -0,0 +1,208 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// unlinkCounting answers UNLINK with 1 for every key.
+func unlinkCounting(ctx context.Context, keys ...string) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetVal(int64(len(keys)))
+	return cmd
+}
+
+// evalReply builds a script reply.
+func evalReply(ctx context.Context, val any, err error) *redis.Cmd {
+	cmd := redis.NewCmd(ctx)
+	if err != nil {
+		cmd.SetErr(err)
+	} else {
+		cmd.SetVal(val)
+	}
+	return cmd
+}
+
+func TestRedisStore_DeleteByPatternCount(t *testing.T) {
+	t.Parallel()
+
+	pattern := "user:*"
+
+	tests := []struct {
+		name            string
+		scanCount       int64
+		scripted        bool
+		mock            func(mock *redismock.MockRedisClient, cancel context.CancelFunc)
+		expectedRemoved int64
+		expectedErr     error
+	}{
+		{
+			name:      "should scan in batches and unlink every key",
+			scanCount: 2,
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				pages := map[uint64]struct {
+					keys []string
+					next uint64
+				}{
+					0: {[]string{"user:1", "user:2"}, 7},
+					7: {[]string{"user:3"}, 0},
+				}
+				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					if match != pattern || count != 2 {
+						return redis.NewScanCmdResult(nil, 0, errors.New("unexpected scan arguments"))
+					}
+					page := pages[cursor]
+					return redis.NewScanCmdResult(page.keys, page.next, nil)
+				}
+				mock.UnlinkFunc = unlinkCounting
+			},
+			expectedRemoved: 3,
+		},
+		{
+			name: "should use DefaultScanCount when scan count is not set",
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					if count != DefaultScanCount {
+						return redis.NewScanCmdResult(nil, 0, errors.New("unexpected scan count"))
+					}
+					return redis.NewScanCmdResult([]string{"user:1"}, 0, nil)
+				}
+				mock.UnlinkFunc = unlinkCounting
+			},
+			expectedRemoved: 1,
+		},
+		{
+			name: "should not count keys that were already gone",
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					return redis.NewScanCmdResult([]string{"user:1", "user:2"}, 0, nil)
+				}
+				mock.UnlinkFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
+					cmd := redis.NewIntCmd(ctx)
+					if keys[0] == "user:1" {
+						cmd.SetVal(1)
+					}
+					return cmd
+				}
+			},
+			expectedRemoved: 1,
+		},
+		{
+			name: "should stop between batches when context is cancelled",
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					return redis.NewScanCmdResult([]string{"user:1"}, cursor+1, nil)
+				}
+				mock.UnlinkFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
+					cancel()
+					return unlinkCounting(ctx, keys...)
+				}
+			},
+			expectedRemoved: 1,
+			expectedErr:     context.Canceled,
+		},
+		{
+			name: "should return keys removed so far when Unlink fails",
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+					return redis.NewScanCmdResult([]string{"user:1", "user:2"}, 0, nil)
+				}
+				mock.UnlinkFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
+					cmd := unlinkCounting(ctx, keys...)
+					if keys[0] == "user:2" {
+						cmd.SetErr(errors.New("unlink error"))
+					}
+					return cmd
+				}
+			},
+			expectedRemoved: 1,
+			expectedErr:     errors.New("unlink error"),
+		},
+		{
+			name:     "should run batches as a script when scripted delete is enabled",
+			scripted: true,
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.EvalShaFunc = func(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+					if sha1 != scanUnlinkSHA || args[1] != pattern {
+						return evalReply(ctx, nil, errors.New("unexpected script arguments"))
+					}
+					if args[0] == "0" {
+						return evalReply(ctx, []any{"42", int64(3)}, nil)
+					}
+					return evalReply(ctx, []any{"0", int64(2)}, nil)
+				}
+			},
+			expectedRemoved: 5,
+		},
+		{
+			name:     "should load the script with Eval when it is not cached",
+			scripted: true,
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.EvalShaFunc = func(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+					return evalReply(ctx, nil, errors.New("NOSCRIPT No matching script"))
+				}
+				mock.EvalFunc = func(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd {
+					if script != scanUnlinkScript {
+						return evalReply(ctx, nil, errors.New("unexpected script"))
+					}
+					return evalReply(ctx, []any{"0", int64(4)}, nil)
+				}
+			},
+			expectedRemoved: 4,
+		},
+		{
+			name:     "should return error when script fails",
+			scripted: true,
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.EvalShaFunc = func(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+					return evalReply(ctx, nil, errors.New("script error"))
+				}
+			},
+			expectedErr: errors.New("script error"),
+		},
+		{
+			name:     "should return error when script reply is malformed",
+			scripted: true,
+			mock: func(mock *redismock.MockRedisClient, cancel context.CancelFunc) {
+				mock.EvalShaFunc = func(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd {
+					return evalReply(ctx, []any{"not-a-cursor", int64(1)}, nil)
+				}
+			},
+			expectedErr: errors.New(`redis: unexpected script cursor not-a-cursor`),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			mock := &redismock.MockRedisClient{}
+			store := &RedisStore{client: mock, scanCount: tt.scanCount, scriptedPatternDelete: tt.scripted}
+
+			tt.mock(mock, cancel)
+
+			// --- Act ---
+			removed, err := store.DeleteByPatternCount(ctx, pattern)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedRemoved, removed, "removed count must match")
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when DeleteByPatternCount succeeds")
+		})
+	}
+}
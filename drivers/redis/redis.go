@@ -4,9 +4,9 @@ import (
 	"context"
 	"time"
 
-	"github.com/bytedance/sonic"
 	"github.com/redis/go-redis/v9"
 	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
 	"github.com/shoraid/omnicache/contract"
 )
 
@@ -16,54 +16,84 @@ type redisClient interface {
 	Get(ctx context.Context, key string) *redis.StringCmd
 	Exists(ctx context.Context, keys ...string) *redis.IntCmd
 	Set(ctx context.Context, key string, value any, expiration time.Duration) *redis.StatusCmd
+	GetRange(ctx context.Context, key string, start, end int64) *redis.StringCmd
+	Append(ctx context.Context, key, value string) *redis.IntCmd
 	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	Pipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	TxPipelined(ctx context.Context, fn func(redis.Pipeliner) error) ([]redis.Cmder, error)
+	Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd
+	EvalSha(ctx context.Context, sha1 string, keys []string, args ...any) *redis.Cmd
+	Ping(ctx context.Context) *redis.StatusCmd
 }
 
 type RedisStore struct {
 	client redisClient
+
+	// scanCount is the SCAN COUNT hint used by DeleteByPattern.
+	scanCount int64
+
+	// scriptedPatternDelete runs each DeleteByPattern batch as a Lua script.
+	scriptedPatternDelete bool
+
+	// chunkSize is the size above which values are split into chunks.
+	// Zero disables chunking.
+	chunkSize int
+
+	// streamChunkSize is the piece size used by GetReader and SetFromReader.
+	streamChunkSize int
+
+	// codec encodes values that have no dedicated envelope tag.
+	codec codec.Codec
 }
 
 // NewRedisWithClient creates a new RedisStore instance with a pre-existing redisClient.
 // This is useful for testing or when you want to manage the Redis client lifecycle externally.
 func NewRedisWithClient(client redisClient) (contract.Store, error) {
-	return &RedisStore{client}, nil
+	return &RedisStore{client: client, scanCount: DefaultScanCount, streamChunkSize: DefaultStreamChunkSize, codec: codec.Default}, nil
 }
 
 // NewRedisStore creates a new RedisStore instance with the given RedisConfig.
-// It initializes a new Redis client based on the provided configuration.
+// It initializes a single-node, Sentinel, Cluster or Ring client depending
+// on the topology fields set in the configuration.
 func NewRedisStore(cfg RedisConfig) (contract.Store, error) {
-	opts := &redis.Options{
-		Addr:            cfg.Addr,
-		ClientName:      cfg.ClientName,
-		Username:        cfg.Username,
-		Password:        cfg.Password,
-		DB:              cfg.DB,
-		MaxRetries:      cfg.MaxRetries,
-		MinRetryBackoff: cfg.MinRetryBackoff,
-		MaxRetryBackoff: cfg.MaxRetryBackoff,
-		DialTimeout:     cfg.DialTimeout,
-		ReadTimeout:     cfg.ReadTimeout,
-		WriteTimeout:    cfg.WriteTimeout,
-		PoolSize:        cfg.PoolSize,
-		PoolTimeout:     cfg.PoolTimeout,
-		MinIdleConns:    cfg.MinIdleConns,
-		ConnMaxIdleTime: cfg.ConnMaxIdleTime,
-		ConnMaxLifetime: cfg.ConnMaxLifetime,
-	}
-
-	client := redis.NewClient(opts)
-
-	return &RedisStore{client}, nil
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ScanCount <= 0 {
+		cfg.ScanCount = DefaultScanCount
+	}
+
+	if cfg.StreamChunkSize <= 0 {
+		cfg.StreamChunkSize = DefaultStreamChunkSize
+	}
+
+	if cfg.Codec == nil {
+		cfg.Codec = codec.Default
+	}
+
+	return &RedisStore{
+		client:                client,
+		scanCount:             cfg.ScanCount,
+		scriptedPatternDelete: cfg.ScriptedPatternDelete,
+		chunkSize:             cfg.ChunkSize,
+		streamChunkSize:       cfg.StreamChunkSize,
+		codec:                 cfg.Codec,
+	}, nil
 }
 
 // Clear removes all entries from the cache.
+// On Cluster and Ring clients FLUSHDB is sent to every master/shard.
 func (r *RedisStore) Clear(ctx context.Context) error {
-	return r.client.FlushDB(ctx).Err()
+	return r.forEachNode(ctx, func(ctx context.Context, node redisClient) error {
+		return node.FlushDB(ctx).Err()
+	})
 }
 
 // Close closes the Redis client.
 func (r *RedisStore) Close(ctx context.Context) error {
-	if client, ok := r.client.(*redis.Client); ok {
+	if client, ok := r.client.(interface{ Close() error }); ok {
 		return client.Close()
 	}
 
@@ -71,41 +101,70 @@ func (r *RedisStore) Close(ctx context.Context) error {
 }
 
 // Delete removes the entry associated with the given key from the cache.
+// With chunking enabled, the chunks of a chunked value are removed too.
 func (r *RedisStore) Delete(ctx context.Context, key string) error {
+	if r.chunkSize > 0 {
+		return r.deleteChunked(ctx, key)
+	}
+
 	return r.client.Del(ctx, key).Err()
 }
 
 // DeleteByPattern removes all cache entries whose keys match the given pattern.
+// See DeleteByPatternCount for how keys are scanned and removed.
 func (r *RedisStore) DeleteByPattern(ctx context.Context, pattern string) error {
-	iter := r.client.Scan(ctx, 0, pattern, 0).Iterator()
-	for iter.Next(ctx) {
-		if err := r.client.Del(ctx, iter.Val()).Err(); err != nil {
-			return err
-		}
-	}
-
-	return iter.Err()
+	_, err := r.DeleteByPatternCount(ctx, pattern)
+	return err
 }
 
 // DeleteMany removes multiple keys from the cache in a single call.
+// With chunking enabled, the chunks of chunked values are removed too.
+// On Cluster and Ring clients keys are deleted one by one, because a
+// multi-key DEL must not span hash slots or shards.
 func (r *RedisStore) DeleteMany(ctx context.Context, keys ...string) error {
 	if len(keys) == 0 {
 		return nil
 	}
 
+	if r.chunkSize > 0 {
+		return r.deleteChunked(ctx, keys...)
+	}
+
+	if _, ok := r.client.(multiNodeClient); ok {
+		for _, key := range keys {
+			if err := r.client.Del(ctx, key).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	return r.client.Del(ctx, keys...).Err()
 }
 
 // Get retrieves a value from the cache by key.
+//
+// Values written by Set come back as their original Go type for nil,
+// strings, []byte, booleans, numbers, time.Time and time.Duration, and for
+// struct types recorded with Register. Other values are returned as
+// codec.Raw, and values written before type envelopes were introduced are
+// returned as their raw string.
+// Chunked values are reassembled, whether or not chunking is enabled.
 func (r *RedisStore) Get(ctx context.Context, key string) (any, error) {
-	value, err := r.client.Get(ctx, key).Result()
+	data, err := r.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
 		return nil, omnicache.ErrCacheMiss
 	} else if err != nil {
 		return nil, err
 	}
 
-	return value, nil
+	if m, ok := parseManifest(data); ok {
+		if data, err = r.getChunks(ctx, key, m); err != nil {
+			return nil, err
+		}
+	}
+
+	return decodeValue(data)
 }
 
 // Has checks whether a key exists in the cache.
@@ -119,16 +178,39 @@ func (r *RedisStore) Has(ctx context.Context, key string) (bool, error) {
 }
 
 // Set stores a value in the cache with the given key and an optional time-to-live (TTL).
+// With chunking enabled, values larger than ChunkSize are split into chunks.
 func (r *RedisStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	if ttl < 0 {
 		return omnicache.ErrInvalidValue
 	}
 
-	// Marshal value to JSON to handle all Go types safely
-	data, err := sonic.Marshal(value)
+	// Wrap value in a type-tagged envelope so Get can restore its type
+	data, err := encodeValue(value, r.codec)
 	if err != nil {
 		return err
 	}
 
+	if r.chunkSize > 0 {
+		return r.setChunked(ctx, key, data, ttl)
+	}
+
 	return r.client.Set(ctx, key, data, ttl).Err()
 }
+
+// Ping sends PING to the server.
+// On Cluster and Ring clients PING is sent to every master/shard.
+func (r *RedisStore) Ping(ctx context.Context) error {
+	return r.forEachNode(ctx, func(ctx context.Context, node redisClient) error {
+		return node.Ping(ctx).Err()
+	})
+}
+
+// forEachNode runs fn against every master/shard of a multi-node client,
+// or once against the client itself otherwise.
+func (r *RedisStore) forEachNode(ctx context.Context, fn func(ctx context.Context, node redisClient) error) error {
+	if client, ok := r.client.(multiNodeClient); ok {
+		return client.forEachNode(ctx, fn)
+	}
+
+	return fn(ctx, r.client)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
 	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
 	"github.com/shoraid/omnicache/internal/assert"
 )
@@ -295,12 +296,12 @@ func TestRedisStore_DeleteByPattern(t *testing.T) {
 		expectedErr error
 	}{
 		{
-			name: "should delete keys matching pattern successfully when Scan and Del succeed",
+			name: "should delete keys matching pattern successfully when Scan and Unlink succeed",
 			mock: func(mock *redismock.MockRedisClient) {
 				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
 					return redis.NewScanCmdResult([]string{"user:1", "user:2"}, 0, nil)
 				}
-				mock.DelFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
+				mock.UnlinkFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
 					cmd := redis.NewIntCmd(ctx)
 					cmd.SetVal(int64(len(keys)))
 					return cmd
@@ -327,22 +328,22 @@ func TestRedisStore_DeleteByPattern(t *testing.T) {
 			expectedErr: errors.New("scan error"),
 		},
 		{
-			name: "should return error when Del fails for one of the keys",
+			name: "should return error when Unlink fails for one of the keys",
 			mock: func(mock *redismock.MockRedisClient) {
 				mock.ScanFunc = func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
 					return redis.NewScanCmdResult([]string{"user:1", "user:2"}, 0, nil)
 				}
-				mock.DelFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
+				mock.UnlinkFunc = func(ctx context.Context, keys ...string) *redis.IntCmd {
 					cmd := redis.NewIntCmd(ctx)
 					if keys[0] == "user:2" {
-						cmd.SetErr(errors.New("del error"))
+						cmd.SetErr(errors.New("unlink error"))
 					} else {
 						cmd.SetVal(1)
 					}
 					return cmd
 				}
 			},
-			expectedErr: errors.New("del error"),
+			expectedErr: errors.New("unlink error"),
 		},
 	}
 
@@ -612,7 +613,7 @@ func TestRedisStore_Set(t *testing.T) {
 
 	key := "test-key"
 	value := "test-value"
-	marshaledValue, _ := json.Marshal(value)
+	marshaledValue := append([]byte{envelopeMagic, tagString}, value...)
 	ttl := 5 * time.Minute
 
 	tests := []struct {
@@ -631,7 +632,7 @@ func TestRedisStore_Set(t *testing.T) {
 			mock: func(mock *redismock.MockRedisClient) {
 				mock.SetFunc = func(ctx context.Context, k string, v any, exp time.Duration) *redis.StatusCmd {
 					cmd := redis.NewStatusCmd(ctx)
-					// verify the value matches expected JSON
+					// verify the value matches the expected envelope
 					assert.Equal(t, marshaledValue, v, "expected marshaled value to match")
 					cmd.SetVal("OK")
 					return cmd
@@ -661,6 +662,7 @@ func TestRedisStore_Set(t *testing.T) {
 			ttl:   ttl,
 			mock: func(mock *redismock.MockRedisClient) {
 				data, _ := json.Marshal(struct{ Name string }{Name: "test"})
+				data = append([]byte{envelopeMagic, tagEncoded, codec.IDSonic}, data...)
 				mock.SetFunc = func(ctx context.Context, k string, v any, exp time.Duration) *redis.StatusCmd {
 					cmd := redis.NewStatusCmd(ctx)
 					assert.Equal(t, data, v, "expected marshaled struct value to match")
@@ -729,3 +731,50 @@ func TestRedisStore_Set(t *testing.T) {
 		})
 	}
 }
+
+func TestRedisStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		pingErr     error
+		expectedErr error
+	}{
+		{
+			name: "should succeed when PING succeeds",
+		},
+		{
+			name:        "should return an error when PING fails",
+			pingErr:     errors.New("connection refused"),
+			expectedErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			mock := &redismock.MockRedisClient{
+				PingFunc: func(ctx context.Context) *redis.StatusCmd {
+					cmd := redis.NewStatusCmd(ctx)
+					cmd.SetErr(tt.pingErr)
+					return cmd
+				},
+			}
+			store := &RedisStore{client: mock}
+
+			// --- Act ---
+			err := store.Ping(context.Background())
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when PING succeeds")
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package redisstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+)
+
+// streamKeySep separates a key from the random suffix of the temporary key
+// SetFromReader writes to.
+const streamKeySep = "#stream:"
+
+// streamTempTTL expires temporary keys left behind by writers that failed
+// before renaming them.
+const streamTempTTL = time.Hour
+
+var errTornRead = errors.New("redis: value changed while streaming")
+
+// GetReader returns a reader over the []byte or string value stored under
+// key. The caller must close it.
+//
+// The value is fetched in StreamChunkSize pieces with GETRANGE, or chunk by
+// chunk for chunked values, so it is never held in memory as a whole. A
+// reader racing a concurrent write to the same key fails with an error
+// rather than returning a mix of both values where this can be detected
+// (chunked values); use Get when that matters.
+// Returns ErrCacheMiss if the key is missing, and ErrTypeMismatch if the
+// value is neither a []byte nor a string.
+func (r *RedisStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	// The first piece must be able to hold a whole manifest
+	size := r.streamPieceSize()
+	if size < manifestSize {
+		size = manifestSize
+	}
+
+	head, err := r.client.GetRange(ctx, key, 0, int64(size)-1).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// GETRANGE answers missing keys with an empty string
+	if len(head) == 0 {
+		exists, err := r.Has(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, omnicache.ErrCacheMiss
+		}
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	if m, ok := parseManifest(head); ok {
+		return r.chunkReader(ctx, key, m)
+	}
+
+	if head[0] != envelopeMagic {
+		// Legacy value, stored as-is
+		return r.rangeReader(ctx, key, head, 0, size), nil
+	}
+
+	if len(head) >= 2 && (head[1] == tagBytes || head[1] == tagString) {
+		return r.rangeReader(ctx, key, head, 2, size), nil
+	}
+
+	value, err := r.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return readerFromValue(value)
+}
+
+// SetFromReader stores everything read from r as a []byte value.
+//
+// The value is written in StreamChunkSize pieces to a temporary key, which
+// is then renamed to key and given its TTL in one transaction, so readers
+// never see a partial value and a failed read leaves the previous value in
+// place. The temporary key shares key's Cluster slot; keys containing '}'
+// without a hash tag cannot be given one and are buffered and written with
+// Set instead.
+//
+// With chunking enabled, values larger than ChunkSize are split into chunks
+// as they are read, each written to a temporary key of its own, and the
+// chunks are renamed and the manifest written in one transaction. On
+// Cluster and Ring clients this requires key to have a hash tag; other keys
+// are buffered and written with Set.
+func (r *RedisStore) SetFromReader(ctx context.Context, key string, rd io.Reader, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	tmp, ok := streamTempKey(key)
+	if ok && r.chunkSize > 0 {
+		// Only a hash tag puts the chunks in the slot of their temporary keys
+		_, multiNode := r.client.(multiNodeClient)
+		ok = !multiNode || hasHashTag(key)
+	}
+	if !ok {
+		data, err := io.ReadAll(rd)
+		if err != nil {
+			return err
+		}
+		return r.Set(ctx, key, data, ttl)
+	}
+
+	src := io.MultiReader(bytes.NewReader([]byte{envelopeMagic, tagBytes}), rd)
+	if r.chunkSize > 0 {
+		return r.setChunkedFromReader(ctx, key, tmp, src, ttl)
+	}
+
+	if _, err := r.writePieces(ctx, tmp, src, make([]byte, r.streamPieceSize())); err != nil {
+		r.client.Del(ctx, tmp)
+		return err
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		renameWithTTL(ctx, pipe, tmp, key, ttl)
+		return nil
+	})
+	if err != nil {
+		r.client.Del(ctx, tmp)
+		return err
+	}
+
+	return nil
+}
+
+// setChunkedFromReader stores the encoded value read from src at key like
+// setChunked, but writes each chunk to a temporary key next to tmp as it is
+// read. A value that fits in one chunk is renamed to key as-is.
+func (r *RedisStore) setChunkedFromReader(ctx context.Context, key, tmp string, src io.Reader, ttl time.Duration) error {
+	hash := crc32.NewIEEE()
+	src = io.TeeReader(src, hash)
+	buf := make([]byte, r.streamPieceSize())
+
+	var tmpKeys []string
+	size := 0
+	for {
+		tmpKeys = append(tmpKeys, tmp+chunkKeySep+strconv.Itoa(len(tmpKeys)))
+		n, err := r.writePieces(ctx, tmpKeys[len(tmpKeys)-1], io.LimitReader(src, int64(r.chunkSize)), buf)
+		size += n
+		if err != nil {
+			_ = r.unlink(ctx, tmpKeys)
+			return err
+		}
+
+		if n < r.chunkSize {
+			// The value ended; drop the key of a chunk that got no data
+			if n == 0 {
+				tmpKeys = tmpKeys[:len(tmpKeys)-1]
+			}
+			break
+		}
+	}
+
+	count := len(tmpKeys)
+	if count == 1 {
+		count = 0
+	}
+
+	var prev *redis.StringCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		prev = getManifestHead(ctx, pipe, key)
+		if count == 0 {
+			renameWithTTL(ctx, pipe, tmpKeys[0], key, ttl)
+			return nil
+		}
+
+		for i, chunkKey := range chunkKeys(key, 0, count) {
+			renameWithTTL(ctx, pipe, tmpKeys[i], chunkKey, ttl)
+		}
+
+		// The manifest goes last, so it never points at missing chunks.
+		m := manifest{count: count, size: size, checksum: hash.Sum32()}
+		pipe.Set(ctx, key, m.encode(), ttl)
+		return nil
+	})
+	if err != nil {
+		_ = r.unlink(ctx, tmpKeys)
+		return err
+	}
+
+	return r.unlinkStaleChunks(ctx, key, prev.Val(), count)
+}
+
+// writePieces writes everything read from rd to key in pieces of len(buf)
+// bytes: the first with SET, giving key the temporary TTL, and the rest with
+// APPEND. It returns the number of bytes written; an empty rd writes nothing.
+func (r *RedisStore) writePieces(ctx context.Context, key string, rd io.Reader, buf []byte) (int, error) {
+	written := 0
+
+	for {
+		n, err := io.ReadFull(rd, buf)
+		if n > 0 {
+			var writeErr error
+			if written == 0 {
+				writeErr = r.client.Set(ctx, key, buf[:n], streamTempTTL).Err()
+			} else {
+				writeErr = r.client.Append(ctx, key, string(buf[:n])).Err()
+			}
+			if writeErr != nil {
+				return written, writeErr
+			}
+			written += n
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return written, nil
+		} else if err != nil {
+			return written, err
+		}
+	}
+}
+
+// renameWithTTL queues the rename of src to dst and gives dst ttl. RENAME
+// carries over the temporary TTL of src, so it is always reset.
+func renameWithTTL(ctx context.Context, pipe redis.Pipeliner, src, dst string, ttl time.Duration) {
+	pipe.Rename(ctx, src, dst)
+	if ttl > 0 {
+		pipe.PExpire(ctx, dst, ttl)
+	} else {
+		pipe.Persist(ctx, dst)
+	}
+}
+
+func (r *RedisStore) streamPieceSize() int {
+	if r.streamChunkSize <= 0 {
+		return DefaultStreamChunkSize
+	}
+
+	return r.streamChunkSize
+}
+
+// streamTempKey returns a new temporary key that hashes to the same Cluster
+// slot and Ring shard as key. It reports false if there is none.
+func streamTempKey(key string) (string, bool) {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	suffix := streamKeySep + hex.EncodeToString(id[:])
+
+	// A hash tag in key is kept when appending
+	if hasHashTag(key) {
+		return key + suffix, true
+	}
+
+	// Otherwise the whole key is hashed, so make it the hash tag
+	if strings.IndexByte(key, '}') >= 0 {
+		return "", false
+	}
+
+	return "{" + key + "}" + suffix, true
+}
+
+// hasHashTag reports whether key has a non-empty hash tag, which decides
+// its Cluster slot and Ring shard instead of the whole key.
+func hasHashTag(key string) bool {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		return strings.IndexByte(key[start+1:], '}') > 0
+	}
+
+	return false
+}
+
+// readerFromValue returns a reader over a value returned by Get.
+func readerFromValue(value any) (io.ReadCloser, error) {
+	switch val := value.(type) {
+	case []byte:
+		return io.NopCloser(bytes.NewReader(val)), nil
+	case string:
+		return io.NopCloser(strings.NewReader(val)), nil
+	default:
+		return nil, omnicache.ErrTypeMismatch
+	}
+}
+
+// rangeReader reads a single key with GETRANGE, starting from a first
+// piece head that was already fetched.
+type rangeReader struct {
+	ctx    context.Context
+	client redisClient
+	key    string
+	size   int
+	buf    []byte
+	offset int64
+	eof    bool
+}
+
+func (r *RedisStore) rangeReader(ctx context.Context, key string, head []byte, skip, size int) io.ReadCloser {
+	return &rangeReader{
+		ctx:    ctx,
+		client: r.client,
+		key:    key,
+		size:   size,
+		buf:    head[skip:],
+		offset: int64(len(head)),
+		eof:    len(head) < size,
+	}
+}
+
+func (rr *rangeReader) Read(p []byte) (int, error) {
+	for len(rr.buf) == 0 {
+		if rr.eof {
+			return 0, io.EOF
+		}
+
+		data, err := rr.client.GetRange(rr.ctx, rr.key, rr.offset, rr.offset+int64(rr.size)-1).Bytes()
+		if err != nil {
+			return 0, err
+		}
+
+		rr.buf = data
+		rr.offset += int64(len(data))
+		rr.eof = len(data) < rr.size
+	}
+
+	n := copy(p, rr.buf)
+	rr.buf = rr.buf[n:]
+
+	return n, nil
+}
+
+func (rr *rangeReader) Close() error {
+	return nil
+}
+
+// chunkReader reads a chunked value chunk by chunk and verifies its size
+// and checksum at the end.
+type chunkReader struct {
+	ctx    context.Context
+	client redisClient
+	keys   []string
+	m      manifest
+	buf    []byte
+	next   int
+	read   int
+	crc    uint32
+}
+
+func (r *RedisStore) chunkReader(ctx context.Context, key string, m manifest) (io.ReadCloser, error) {
+	cr := &chunkReader{ctx: ctx, client: r.client, keys: chunkKeys(key, 0, m.count), m: m}
+
+	if err := cr.fetch(); err != nil {
+		if err == errTornRead {
+			return nil, omnicache.ErrCacheMiss
+		}
+		return nil, err
+	}
+
+	// The first chunk starts with the envelope of the whole value
+	if len(cr.buf) < 2 || (cr.buf[1] != tagBytes && cr.buf[1] != tagString) {
+		value, err := r.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return readerFromValue(value)
+	}
+	cr.buf = cr.buf[2:]
+
+	return cr, nil
+}
+
+// fetch loads the next chunk into buf.
+func (cr *chunkReader) fetch() error {
+	if cr.next >= len(cr.keys) {
+		return errTornRead
+	}
+
+	data, err := cr.client.Get(cr.ctx, cr.keys[cr.next]).Bytes()
+	if err == redis.Nil {
+		return errTornRead
+	} else if err != nil {
+		return err
+	}
+
+	cr.next++
+	cr.read += len(data)
+	cr.crc = crc32.Update(cr.crc, crc32.IEEETable, data)
+	cr.buf = data
+
+	if cr.next == len(cr.keys) && (cr.read != cr.m.size || cr.crc != cr.m.checksum) {
+		return errTornRead
+	}
+
+	return nil
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.buf) == 0 {
+		if cr.next == len(cr.keys) {
+			return 0, io.EOF
+		}
+
+		if err := cr.fetch(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, cr.buf)
+	cr.buf = cr.buf[n:]
+
+	return n, nil
+}
+
+func (cr *chunkReader) Close() error {
+	return nil
+}
@@ -0,0 +1,357 @@
+package redisstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+	redismock "github.com/shoraid/omnicache/drivers/redis/mock"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestRedisStore_SetFromReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		key         string
+		ttl         time.Duration
+		expectedTTL time.Duration
+	}{
+		{name: "should stream value and apply TTL", key: "page", ttl: time.Minute, expectedTTL: time.Minute},
+		{name: "should clear temporary TTL when TTL is zero", key: "page", ttl: 0, expectedTTL: 0},
+		{name: "should keep hash tag of the key", key: "{page}:1", ttl: time.Minute, expectedTTL: time.Minute},
+		{name: "should fall back to Set when key cannot be hash tagged", key: "page}", ttl: time.Minute, expectedTTL: time.Minute},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := &RedisStore{client: mock, streamChunkSize: 4}
+			ctx := context.Background()
+			content := strings.Repeat("0123456789", 3)
+
+			// --- Act ---
+			err := store.SetFromReader(ctx, tt.key, strings.NewReader(content), tt.ttl)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when streaming value")
+			assert.Equal(t, []string{tt.key}, fake.keys(), "expected no temporary keys left")
+			assert.Equal(t, tt.expectedTTL, fake.ttls[tt.key], "TTL must match")
+
+			value, err := store.Get(ctx, tt.key)
+			assert.NoError(t, err, "expected no error when getting value")
+			assert.Equal(t, []byte(content), value, "expected a []byte value")
+		})
+	}
+}
+
+func TestRedisStore_SetFromReaderFailure(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	fake, mock := newFakeRedis()
+	store := &RedisStore{client: mock, streamChunkSize: 4}
+	ctx := context.Background()
+	assert.NoError(t, store.Set(ctx, "page", "previous", 0))
+	readErr := errors.New("read failed")
+
+	// --- Act ---
+	err := store.SetFromReader(ctx, "page", io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(readErr)), 0)
+
+	// --- Assert ---
+	assert.EqualError(t, readErr, err, "expected the read error")
+	assert.Equal(t, []string{"page"}, fake.keys(), "expected temporary key removed")
+	value, _ := store.Get(ctx, "page")
+	assert.Equal(t, "previous", value, "expected previous value untouched")
+}
+
+func TestRedisStore_SetFromReaderChunked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		client       func(mock *redismock.MockRedisClient) redisClient
+		key          string
+		content      string
+		expectedKeys []string
+	}{
+		{
+			name:         "should split large values into chunks and a manifest",
+			key:          "page",
+			content:      strings.Repeat("x", 50),
+			expectedKeys: []string{"page", "page#chunk:0", "page#chunk:1", "page#chunk:2"},
+		},
+		{
+			name:         "should split values filling their last chunk exactly",
+			key:          "page",
+			content:      strings.Repeat("x", 38),
+			expectedKeys: []string{"page", "page#chunk:0", "page#chunk:1"},
+		},
+		{
+			name:         "should store small values without chunks",
+			key:          "page",
+			content:      "small",
+			expectedKeys: []string{"page"},
+		},
+		{
+			name: "should split values of hash-tagged keys on multi-node clients",
+			client: func(mock *redismock.MockRedisClient) redisClient {
+				return &mockMultiNodeClient{MockRedisClient: mock}
+			},
+			key:          "{page}",
+			content:      strings.Repeat("x", 50),
+			expectedKeys: []string{"{page}", "{page}#chunk:0", "{page}#chunk:1", "{page}#chunk:2"},
+		},
+		{
+			name: "should buffer values of other keys on multi-node clients",
+			client: func(mock *redismock.MockRedisClient) redisClient {
+				return &mockMultiNodeClient{MockRedisClient: mock}
+			},
+			key:          "page",
+			content:      strings.Repeat("x", 50),
+			expectedKeys: []string{"page", "page#chunk:0", "page#chunk:1", "page#chunk:2"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			var client redisClient = mock
+			if tt.client != nil {
+				client = tt.client(mock)
+			}
+			store := &RedisStore{client: client, chunkSize: 20, streamChunkSize: 6}
+			ctx := context.Background()
+
+			// --- Act ---
+			err := store.SetFromReader(ctx, tt.key, strings.NewReader(tt.content), time.Minute)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when streaming value")
+			assert.Equal(t, tt.expectedKeys, fake.keys(), "stored keys must match")
+			for _, key := range tt.expectedKeys {
+				assert.Equal(t, time.Minute, fake.ttls[key], "expected identical TTLs")
+			}
+
+			value, err := store.Get(ctx, tt.key)
+			assert.NoError(t, err, "expected no error when getting value")
+			assert.Equal(t, []byte(tt.content), value, "expected the streamed value")
+		})
+	}
+}
+
+func TestRedisStore_SetFromReaderChunkedOverwrite(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	fake, mock := newFakeRedis()
+	store := &RedisStore{client: mock, chunkSize: 20, streamChunkSize: 6}
+	ctx := context.Background()
+	assert.NoError(t, store.Set(ctx, "page", strings.Repeat("x", 90), 0))
+
+	// --- Act ---
+	err := store.SetFromReader(ctx, "page", strings.NewReader(strings.Repeat("y", 30)), 0)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when overwriting value")
+	assert.Equal(t, []string{"page", "page#chunk:0", "page#chunk:1"}, fake.keys(), "expected stale chunks removed")
+	value, _ := store.Get(ctx, "page")
+	assert.Equal(t, []byte(strings.Repeat("y", 30)), value, "expected the new value")
+}
+
+func TestRedisStore_SetFromReaderChunkedFailure(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	fake, mock := newFakeRedis()
+	store := &RedisStore{client: mock, chunkSize: 20, streamChunkSize: 6}
+	ctx := context.Background()
+	assert.NoError(t, store.Set(ctx, "page", strings.Repeat("x", 50), 0))
+	readErr := errors.New("read failed")
+
+	// --- Act ---
+	err := store.SetFromReader(ctx, "page", io.MultiReader(strings.NewReader(strings.Repeat("y", 45)), iotest.ErrReader(readErr)), 0)
+
+	// --- Assert ---
+	assert.EqualError(t, readErr, err, "expected the read error")
+	assert.Equal(t, []string{"page", "page#chunk:0", "page#chunk:1", "page#chunk:2"}, fake.keys(), "expected temporary keys removed")
+	value, _ := store.Get(ctx, "page")
+	assert.Equal(t, strings.Repeat("x", 50), value, "expected previous value untouched")
+}
+
+func TestRedisStore_SetFromReaderRenameFailure(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	fake, mock := newFakeRedis()
+	renameErr := errors.New("ERR no such key")
+	mock.RenameFunc = func(ctx context.Context, key, newkey string) *redis.StatusCmd {
+		cmd := redis.NewStatusCmd(ctx)
+		cmd.SetErr(renameErr)
+		return cmd
+	}
+	store := &RedisStore{client: mock, chunkSize: 64, streamChunkSize: 4}
+	ctx := context.Background()
+
+	// --- Act ---
+	err := store.SetFromReader(ctx, "page", strings.NewReader("content"), 0)
+
+	// --- Assert ---
+	assert.EqualError(t, renameErr, err, "expected the rename error behind the missing previous value")
+	assert.Equal(t, []string{}, fake.keys(), "expected temporary key removed")
+}
+
+func TestRedisStore_GetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		chunkSize   int
+		setup       func(ctx context.Context, store *RedisStore, fake *fakeRedis)
+		expected    string
+		expectedErr error
+	}{
+		{
+			name: "should stream []byte values in pieces",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", []byte(strings.Repeat("ab", 10)), 0)
+			},
+			expected: strings.Repeat("ab", 10),
+		},
+		{
+			name: "should stream string values",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", "hello world", 0)
+			},
+			expected: "hello world",
+		},
+		{
+			name: "should stream legacy values as-is",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				fake.data["key"] = `{"legacy":true}`
+			},
+			expected: `{"legacy":true}`,
+		},
+		{
+			name: "should stream empty values",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				fake.data["key"] = ""
+			},
+			expected: "",
+		},
+		{
+			name:      "should stream chunked values chunk by chunk",
+			chunkSize: 8,
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", []byte(strings.Repeat("xy", 20)), 0)
+			},
+			expected: strings.Repeat("xy", 20),
+		},
+		{
+			name: "should return ErrCacheMiss for missing keys",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrTypeMismatch for non-byte values",
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", 42, 0)
+			},
+			expectedErr: omnicache.ErrTypeMismatch,
+		},
+		{
+			name:      "should return ErrTypeMismatch for chunked non-byte values",
+			chunkSize: 8,
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", map[string]string{"a": strings.Repeat("x", 20)}, 0)
+			},
+			expectedErr: omnicache.ErrTypeMismatch,
+		},
+		{
+			name:      "should fail when a chunk changes while streaming",
+			chunkSize: 8,
+			setup: func(ctx context.Context, store *RedisStore, fake *fakeRedis) {
+				store.Set(ctx, "key", []byte(strings.Repeat("xy", 20)), 0)
+				fake.data["key#chunk:2"] = "tampered"
+			},
+			expectedErr: errTornRead,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			fake, mock := newFakeRedis()
+			store := &RedisStore{client: mock, chunkSize: tt.chunkSize, streamChunkSize: 3}
+			ctx := context.Background()
+			tt.setup(ctx, store, fake)
+
+			// --- Act ---
+			var data []byte
+			reader, err := store.GetReader(ctx, "key")
+			if err == nil {
+				data, err = io.ReadAll(reader)
+				assert.NoError(t, reader.Close(), "expected no error when closing reader")
+			}
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when streaming value")
+			assert.Equal(t, tt.expected, string(data), "streamed value must match")
+		})
+	}
+}
+
+func TestStream_streamTempKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		key            string
+		expectedPrefix string
+		expectedOk     bool
+	}{
+		{name: "should wrap plain keys in a hash tag", key: "page:1", expectedPrefix: "{page:1}#stream:", expectedOk: true},
+		{name: "should keep an existing hash tag", key: "{user:1}:page", expectedPrefix: "{user:1}:page#stream:", expectedOk: true},
+		{name: "should wrap keys with an unterminated brace", key: "a{b", expectedPrefix: "{a{b}#stream:", expectedOk: true},
+		{name: "should give up on keys with a stray closing brace", key: "a}b", expectedOk: false},
+		{name: "should give up on keys with an empty hash tag", key: "a{}b", expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			tmp, ok := streamTempKey(tt.key)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedOk, ok, "ok must match")
+			assert.True(t, strings.HasPrefix(tmp, tt.expectedPrefix), "temporary key must keep the slot of the key")
+		})
+	}
+}
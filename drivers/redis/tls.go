@@ -0,0 +1,66 @@
+package redisstore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/shoraid/omnicache"
+)
+
+// build converts the TLSConfig into a *tls.Config.
+// Returns ErrInvalidConfig if a file cannot be loaded, a bundle holds no
+// certificates, only one of CertFile/KeyFile is set, or MinVersion is unknown.
+func (c *TLSConfig) build() (*tls.Config, error) {
+	minVersion := c.MinVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	switch minVersion {
+	case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+	default:
+		return nil, fmt.Errorf("%w: redis tls: unknown min version %#x", omnicache.ErrInvalidConfig, c.MinVersion)
+	}
+
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		MinVersion:         minVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" || len(c.CAPEM) > 0 {
+		pool := x509.NewCertPool()
+
+		if c.CAFile != "" {
+			pem, err := os.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("%w: redis tls: read CA file: %v", omnicache.ErrInvalidConfig, err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("%w: redis tls: no certificates in CA file %s", omnicache.ErrInvalidConfig, c.CAFile)
+			}
+		}
+
+		if len(c.CAPEM) > 0 && !pool.AppendCertsFromPEM(c.CAPEM) {
+			return nil, fmt.Errorf("%w: redis tls: no certificates in CA PEM", omnicache.ErrInvalidConfig)
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, fmt.Errorf("%w: redis tls: CertFile and KeyFile must be set together", omnicache.ErrInvalidConfig)
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: redis tls: load client certificate: %v", omnicache.ErrInvalidConfig, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,161 @@
+package redisstore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// writeTestCert writes a self-signed certificate and its key as PEM files
+// in a temp directory and returns their paths.
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
+
+func TestTLSConfig_build(t *testing.T) {
+	t.Parallel()
+
+	certFile, keyFile := writeTestCert(t)
+	certPEM, err := os.ReadFile(certFile)
+	assert.NoError(t, err)
+
+	missingFile := filepath.Join(t.TempDir(), "missing.pem")
+	notPEMFile := filepath.Join(t.TempDir(), "not.pem")
+	assert.NoError(t, os.WriteFile(notPEMFile, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name        string
+		cfg         TLSConfig
+		expectedErr bool
+		assert      func(t *testing.T, cfg *tls.Config)
+	}{
+		{
+			name: "should default MinVersion to TLS 1.2 and use system roots",
+			cfg:  TLSConfig{ServerName: "redis.example"},
+			assert: func(t *testing.T, cfg *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), cfg.MinVersion, "expected TLS 1.2 by default")
+				assert.Equal(t, "redis.example", cfg.ServerName, "expected ServerName to be kept")
+				assert.True(t, cfg.RootCAs == nil, "expected system roots when no CA is given")
+			},
+		},
+		{
+			name: "should keep an explicit MinVersion",
+			cfg:  TLSConfig{MinVersion: tls.VersionTLS13},
+			assert: func(t *testing.T, cfg *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion, "expected TLS 1.3")
+			},
+		},
+		{
+			name: "should load CA bundle from file",
+			cfg:  TLSConfig{CAFile: certFile},
+			assert: func(t *testing.T, cfg *tls.Config) {
+				assert.NotNil(t, cfg.RootCAs, "expected a custom root pool")
+			},
+		},
+		{
+			name: "should load CA bundle from PEM bytes",
+			cfg:  TLSConfig{CAPEM: certPEM},
+			assert: func(t *testing.T, cfg *tls.Config) {
+				assert.NotNil(t, cfg.RootCAs, "expected a custom root pool")
+			},
+		},
+		{
+			name: "should load client certificate",
+			cfg:  TLSConfig{CertFile: certFile, KeyFile: keyFile},
+			assert: func(t *testing.T, cfg *tls.Config) {
+				assert.Equal(t, 1, len(cfg.Certificates), "expected one client certificate")
+			},
+		},
+		{
+			name:        "should return ErrInvalidConfig when CA file is missing",
+			cfg:         TLSConfig{CAFile: missingFile},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when CA file holds no certificates",
+			cfg:         TLSConfig{CAFile: notPEMFile},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when CA PEM holds no certificates",
+			cfg:         TLSConfig{CAPEM: []byte("garbage")},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when KeyFile is missing",
+			cfg:         TLSConfig{CertFile: certFile},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when client key pair cannot be loaded",
+			cfg:         TLSConfig{CertFile: certFile, KeyFile: notPEMFile},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when MinVersion is unknown",
+			cfg:         TLSConfig{MinVersion: 0x0999},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			cfg, err := tt.cfg.build()
+
+			// --- Assert ---
+			if tt.expectedErr {
+				assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+				assert.True(t, cfg == nil, "expected no config on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when building TLS config")
+			tt.assert(t, cfg)
+		})
+	}
+}
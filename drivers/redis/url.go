@@ -0,0 +1,64 @@
+package redisstore
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shoraid/omnicache"
+)
+
+// ParseURL builds a RedisConfig from a connection string of the form
+//
+//	redis://[[username]:password@]host[:port][/db][?option=value...]
+//	rediss://[[username]:password@]host[:port][/db][?option=value...]
+//
+// The rediss scheme enables TLS, verifying the server against the host name.
+// Query options follow go-redis (dial_timeout, read_timeout, pool_size,
+// max_retries, client_name, skip_verify, ...). Topology and TLS file fields
+// can be set on the returned config afterwards.
+// Returns ErrInvalidConfig if the URL is malformed or uses another scheme.
+func ParseURL(rawURL string) (RedisConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RedisConfig{}, fmt.Errorf("%w: redis url: %v", omnicache.ErrInvalidConfig, err)
+	}
+
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return RedisConfig{}, fmt.Errorf("%w: redis url: unsupported scheme %q", omnicache.ErrInvalidConfig, u.Scheme)
+	}
+
+	opt, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return RedisConfig{}, fmt.Errorf("%w: %v", omnicache.ErrInvalidConfig, err)
+	}
+
+	cfg := RedisConfig{
+		Addr:            opt.Addr,
+		ClientName:      opt.ClientName,
+		Username:        opt.Username,
+		Password:        opt.Password,
+		DB:              opt.DB,
+		MaxRetries:      opt.MaxRetries,
+		MinRetryBackoff: opt.MinRetryBackoff,
+		MaxRetryBackoff: opt.MaxRetryBackoff,
+		DialTimeout:     opt.DialTimeout,
+		ReadTimeout:     opt.ReadTimeout,
+		WriteTimeout:    opt.WriteTimeout,
+		PoolSize:        opt.PoolSize,
+		PoolTimeout:     opt.PoolTimeout,
+		MinIdleConns:    opt.MinIdleConns,
+		ConnMaxIdleTime: opt.ConnMaxIdleTime,
+		ConnMaxLifetime: opt.ConnMaxLifetime,
+	}
+
+	if opt.TLSConfig != nil {
+		cfg.TLS = &TLSConfig{
+			ServerName:         opt.TLSConfig.ServerName,
+			MinVersion:         opt.TLSConfig.MinVersion,
+			InsecureSkipVerify: opt.TLSConfig.InsecureSkipVerify,
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,68 @@
+package sqlstore
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+// Dialect selects the SQL flavour used for DDL, upserts and placeholders.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+
+	// DialectMySQL stores keys in a VARBINARY(255) column, so keys are
+	// compared byte-wise and may be at most 255 bytes long; Set returns
+	// ErrInvalidValue for longer keys.
+	DialectMySQL Dialect = "mysql"
+
+	DialectSQLite Dialect = "sqlite"
+)
+
+type SQLConfig struct {
+	// Dialect is the SQL flavour of the database. Required.
+	Dialect Dialect
+
+	// DriverName and DSN are passed to sql.Open by OpenSQLStore. The
+	// database/sql driver must be registered, e.g. by importing its
+	// package. NewSQLStore ignores them.
+	DriverName string
+	DSN        string
+
+	// Table is the name of the cache table. Must be a plain identifier
+	// (letters, digits and underscores).
+	//
+	// default: "omnicache"
+	Table string
+
+	// CleanupInterval is how often expired rows are purged in the background.
+	//
+	// default: 10 minutes
+	CleanupInterval time.Duration
+
+	// DisableAutoMigrate skips creating the cache table and its index
+	// when the store is created. Use it when the schema is managed by
+	// your own migrations.
+	//
+	// default: false
+	DisableAutoMigrate bool
+
+	// Logger receives errors of the background cleanup.
+	//
+	// default: slog.Default()
+	Logger *slog.Logger
+
+	// Codec serializes values other than strings and []byte values.
+	// Values written with another registered codec remain readable, which
+	// allows switching codecs on a live cache.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
+
+const (
+	DefaultTable           = "omnicache"
+	DefaultCleanupInterval = 10 * time.Minute
+)
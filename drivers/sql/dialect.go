@@ -0,0 +1,181 @@
+package sqlstore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shoraid/omnicache"
+)
+
+// likeEscape is the escape character used in generated LIKE patterns.
+// '!' is used instead of '\' because backslash handling in string
+// literals differs between dialects.
+const likeEscape = '!'
+
+// maxMySQLKeySize is the size in bytes of the MySQL key column. MySQL
+// cannot index an unbounded column as a primary key.
+const maxMySQLKeySize = 255
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// queries holds the dialect-specific statements for a cache table.
+type queries struct {
+	dialect       Dialect
+	migrate       []string
+	get           string
+	has           string
+	upsert        string
+	delete        string
+	deleteLike    string
+	selectLike    string
+	deleteExpired string
+	clear         string
+}
+
+// buildQueries renders all statements for the given dialect and table.
+// Returns ErrInvalidConfig for unknown dialects or unsafe table names.
+func buildQueries(dialect Dialect, table string) (queries, error) {
+	if !identifierRe.MatchString(table) {
+		return queries{}, fmt.Errorf("%w: invalid table name %q", omnicache.ErrInvalidConfig, table)
+	}
+
+	var q queries
+	q.dialect = dialect
+
+	quote := func(s string) string { return `"` + s + `"` }
+	if dialect == DialectMySQL {
+		quote = func(s string) string { return "`" + s + "`" }
+	}
+
+	t, k, v, e := quote(table), quote("key"), quote("value"), quote("expires_at")
+	idx := quote(table + "_expires_at_idx")
+
+	switch dialect {
+	case DialectPostgres:
+		q.migrate = []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s TEXT PRIMARY KEY, %s BYTEA NOT NULL, %s BIGINT NULL)`, t, k, v, e),
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`, idx, t, e),
+		}
+		q.upsert = fmt.Sprintf(`INSERT INTO %s (%s, %s, %s) VALUES (%s, %s, %s) ON CONFLICT (%s) DO UPDATE SET %s = excluded.%s, %s = excluded.%s`,
+			t, k, v, e, q.placeholder(1), q.placeholder(2), q.placeholder(3), k, v, v, e, e)
+
+	case DialectSQLite:
+		q.migrate = []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s TEXT PRIMARY KEY, %s BLOB NOT NULL, %s INTEGER NULL)`, t, k, v, e),
+			fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (%s)`, idx, t, e),
+		}
+		q.upsert = fmt.Sprintf(`INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?) ON CONFLICT (%s) DO UPDATE SET %s = excluded.%s, %s = excluded.%s`,
+			t, k, v, e, k, v, v, e, e)
+
+	case DialectMySQL:
+		// MySQL has no CREATE INDEX IF NOT EXISTS, so the index is declared inline.
+		// The key is binary so that keys compare byte-wise: the default
+		// collations ignore case and trailing spaces.
+		q.migrate = []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s VARBINARY(%d) NOT NULL PRIMARY KEY, %s LONGBLOB NOT NULL, %s BIGINT NULL, INDEX %s (%s))",
+				t, k, maxMySQLKeySize, v, e, idx, e),
+		}
+		q.upsert = fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE %s = VALUES(%s), %s = VALUES(%s)",
+			t, k, v, e, v, v, e, e)
+
+	default:
+		return queries{}, fmt.Errorf("%w: unsupported dialect %q", omnicache.ErrInvalidConfig, dialect)
+	}
+
+	notExpired := fmt.Sprintf(`(%s IS NULL OR %s > %s)`, e, e, q.placeholder(2))
+
+	q.get = fmt.Sprintf(`SELECT %s FROM %s WHERE %s = %s AND %s`, v, t, k, q.placeholder(1), notExpired)
+	q.has = fmt.Sprintf(`SELECT 1 FROM %s WHERE %s = %s AND %s`, t, k, q.placeholder(1), notExpired)
+	q.delete = fmt.Sprintf(`DELETE FROM %s WHERE %s = %s`, t, k, q.placeholder(1))
+	q.deleteLike = fmt.Sprintf(`DELETE FROM %s WHERE %s LIKE %s ESCAPE '%c'`, t, k, q.placeholder(1), likeEscape)
+	q.selectLike = fmt.Sprintf(`SELECT %s FROM %s WHERE %s LIKE %s ESCAPE '%c'`, k, t, k, q.placeholder(1), likeEscape)
+	q.deleteExpired = fmt.Sprintf(`DELETE FROM %s WHERE %s IS NOT NULL AND %s <= %s`, t, e, e, q.placeholder(1))
+	q.clear = fmt.Sprintf(`DELETE FROM %s`, t)
+
+	return q, nil
+}
+
+// deleteMany renders a DELETE statement for n keys.
+func (q queries) deleteMany(n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = q.placeholder(i + 1)
+	}
+
+	return strings.Replace(q.delete, "= "+q.placeholder(1), "IN ("+strings.Join(placeholders, ", ")+")", 1)
+}
+
+// placeholder returns the n-th (1-based) bind parameter marker.
+func (q queries) placeholder(n int) string {
+	if q.dialect == DialectPostgres {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+// caseSensitiveLike reports whether LIKE compares keys case-sensitively.
+// SQLite does not; MySQL does, as the key column is binary.
+func (q queries) caseSensitiveLike() bool {
+	return q.dialect != DialectSQLite
+}
+
+// checkKey returns ErrInvalidValue for keys the key column cannot hold.
+func (q queries) checkKey(key string) error {
+	if q.dialect == DialectMySQL && len(key) > maxMySQLKeySize {
+		return fmt.Errorf("%w: key exceeds %d bytes", omnicache.ErrInvalidValue, maxMySQLKeySize)
+	}
+
+	return nil
+}
+
+// globToLike translates a Redis-style glob pattern into a LIKE pattern
+// using likeEscape. The second result is false when the LIKE pattern is
+// only an approximation (character classes become '_') and candidates
+// must be filtered further.
+func globToLike(pattern string) (string, bool) {
+	var sb strings.Builder
+	exact := true
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			sb.WriteRune('%')
+		case '?':
+			sb.WriteRune('_')
+		case '[':
+			// LIKE has no character classes: match any single character
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			sb.WriteRune('_')
+			exact = false
+			i = end
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			writeLikeLiteral(&sb, runes[i])
+		default:
+			writeLikeLiteral(&sb, c)
+		}
+	}
+
+	return sb.String(), exact
+}
+
+func writeLikeLiteral(sb *strings.Builder, c rune) {
+	if c == '%' || c == '_' || c == likeEscape {
+		sb.WriteRune(likeEscape)
+	}
+	sb.WriteRune(c)
+}
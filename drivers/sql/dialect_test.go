@@ -0,0 +1,184 @@
+package sqlstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestDialect_buildQueries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		dialect            Dialect
+		table              string
+		expectedUpsert     string
+		expectedGet        string
+		expectedDeleteMany string
+		expectedKeyColumn  string
+		expectedErr        error
+	}{
+		{
+			name:               "should render postgres statements with numbered placeholders",
+			dialect:            DialectPostgres,
+			table:              "cache",
+			expectedUpsert:     `INSERT INTO "cache" ("key", "value", "expires_at") VALUES ($1, $2, $3) ON CONFLICT ("key") DO UPDATE SET "value" = excluded."value", "expires_at" = excluded."expires_at"`,
+			expectedGet:        `SELECT "value" FROM "cache" WHERE "key" = $1 AND ("expires_at" IS NULL OR "expires_at" > $2)`,
+			expectedDeleteMany: `DELETE FROM "cache" WHERE "key" IN ($1, $2, $3)`,
+			expectedKeyColumn:  `"key" TEXT PRIMARY KEY`,
+		},
+		{
+			name:               "should render mysql statements with backtick quoting and duplicate key upsert",
+			dialect:            DialectMySQL,
+			table:              "cache",
+			expectedUpsert:     "INSERT INTO `cache` (`key`, `value`, `expires_at`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`), `expires_at` = VALUES(`expires_at`)",
+			expectedGet:        "SELECT `value` FROM `cache` WHERE `key` = ? AND (`expires_at` IS NULL OR `expires_at` > ?)",
+			expectedDeleteMany: "DELETE FROM `cache` WHERE `key` IN (?, ?, ?)",
+			expectedKeyColumn:  "`key` VARBINARY(255) NOT NULL PRIMARY KEY",
+		},
+		{
+			name:               "should render sqlite statements with question mark placeholders",
+			dialect:            DialectSQLite,
+			table:              "cache",
+			expectedUpsert:     `INSERT INTO "cache" ("key", "value", "expires_at") VALUES (?, ?, ?) ON CONFLICT ("key") DO UPDATE SET "value" = excluded."value", "expires_at" = excluded."expires_at"`,
+			expectedGet:        `SELECT "value" FROM "cache" WHERE "key" = ? AND ("expires_at" IS NULL OR "expires_at" > ?)`,
+			expectedDeleteMany: `DELETE FROM "cache" WHERE "key" IN (?, ?, ?)`,
+			expectedKeyColumn:  `"key" TEXT PRIMARY KEY`,
+		},
+		{
+			name:        "should return ErrInvalidConfig for unknown dialect",
+			dialect:     Dialect("oracle"),
+			table:       "cache",
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name:        "should return ErrInvalidConfig for unsafe table name",
+			dialect:     DialectSQLite,
+			table:       "cache; DROP TABLE users",
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			q, err := buildQueries(tt.dialect, tt.table)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must wrap the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when building queries")
+			assert.Equal(t, tt.expectedUpsert, q.upsert, "upsert statement must match")
+			assert.Equal(t, tt.expectedGet, q.get, "get statement must match")
+			assert.Equal(t, tt.expectedDeleteMany, q.deleteMany(3), "delete many statement must match")
+			assert.Contains(t, q.migrate[0], tt.expectedKeyColumn, "key column must be compared byte-wise")
+		})
+	}
+}
+
+func TestDialect_checkKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		dialect     Dialect
+		key         string
+		expectedErr error
+	}{
+		{name: "should accept mysql keys of 255 bytes", dialect: DialectMySQL, key: strings.Repeat("k", 255)},
+		{name: "should reject longer mysql keys", dialect: DialectMySQL, key: strings.Repeat("k", 256), expectedErr: omnicache.ErrInvalidValue},
+		{name: "should accept long postgres keys", dialect: DialectPostgres, key: strings.Repeat("k", 1024)},
+		{name: "should accept long sqlite keys", dialect: DialectSQLite, key: strings.Repeat("k", 1024)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			q, err := buildQueries(tt.dialect, "cache")
+			assert.NoError(t, err, "expected no error when building queries")
+
+			// --- Act ---
+			err = q.checkKey(tt.key)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must wrap the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected the key to be accepted")
+		})
+	}
+}
+
+func TestDialect_globToLike(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		pattern       string
+		expectedLike  string
+		expectedExact bool
+	}{
+		{
+			name:          "should translate '*' to '%'",
+			pattern:       "user:*",
+			expectedLike:  "user:%",
+			expectedExact: true,
+		},
+		{
+			name:          "should translate '?' to '_'",
+			pattern:       "id-?",
+			expectedLike:  "id-_",
+			expectedExact: true,
+		},
+		{
+			name:          "should escape LIKE wildcards and the escape character",
+			pattern:       "50%_off!*",
+			expectedLike:  "50!%!_off!!%",
+			expectedExact: true,
+		},
+		{
+			name:          "should honor glob backslash escapes",
+			pattern:       `a\*b`,
+			expectedLike:  "a*b",
+			expectedExact: true,
+		},
+		{
+			name:          "should approximate character classes and flag as inexact",
+			pattern:       "item:[12]*",
+			expectedLike:  "item:_%",
+			expectedExact: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			like, exact := globToLike(tt.pattern)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedLike, like, "LIKE pattern must match")
+			assert.Equal(t, tt.expectedExact, exact, "exactness must match")
+		})
+	}
+}
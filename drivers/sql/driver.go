@@ -0,0 +1,26 @@
+package sqlstore
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DriverName is the name the sql driver is registered under for
+// omnicache.NewManagerFromConfig.
+const DriverName = "sql"
+
+func init() {
+	omnicache.RegisterDriver(DriverName, newFromOptions)
+}
+
+// newFromOptions creates a store from configuration options naming the
+// fields of SQLConfig. The store opens the database with OpenSQLStore, so
+// the options must include driver_name and dsn.
+func newFromOptions(options map[string]any) (contract.Store, error) {
+	var config SQLConfig
+	if err := omnicache.DecodeOptions(options, &config); err != nil {
+		return nil, err
+	}
+
+	return OpenSQLStore(config)
+}
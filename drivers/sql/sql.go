@@ -0,0 +1,345 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/glob"
+	"github.com/shoraid/omnicache/internal/storevalue"
+)
+
+// deleteBatchSize caps the number of bind parameters per DELETE ... IN
+// statement, staying well below the limits of every supported database.
+const deleteBatchSize = 500
+
+type SQLStore struct {
+	db            *sql.DB
+	ownsDB        bool
+	q             queries
+	logger        *slog.Logger
+	codec         codec.Codec
+	cancelCleanup context.CancelFunc
+	doneCh        chan struct{}
+}
+
+// OpenSQLStore opens the database named by config.DriverName and
+// config.DSN and creates a SQLStore on top of it, as NewSQLStore does.
+// The database is closed by Close.
+// Returns ErrInvalidConfig if DriverName or DSN is empty or the driver is
+// not registered, and the error of the database if it is unreachable.
+func OpenSQLStore(config SQLConfig) (contract.Store, error) {
+	if config.DriverName == "" || config.DSN == "" {
+		return nil, fmt.Errorf("%w: sql: driver name and DSN are required", omnicache.ErrInvalidConfig)
+	}
+
+	db, err := sql.Open(config.DriverName, config.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sql: %v", omnicache.ErrInvalidConfig, err)
+	}
+
+	if err := db.PingContext(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store, err := NewSQLStore(db, config)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	store.(*SQLStore).ownsDB = true
+
+	return store, nil
+}
+
+// NewSQLStore creates a new SQLStore on top of an existing *sql.DB.
+// Entries live in a single table (key, value, expires_at) which is created
+// automatically unless DisableAutoMigrate is set.
+// It starts a background goroutine to periodically purge expired rows.
+// The database handle is not closed by Close; its lifecycle is managed by the caller.
+func NewSQLStore(db *sql.DB, config SQLConfig) (contract.Store, error) {
+	if db == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	table := DefaultTable
+	if config.Table != "" {
+		table = config.Table
+	}
+
+	q, err := buildQueries(config.Dialect, table)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &SQLStore{
+		db:     db,
+		q:      q,
+		logger: config.Logger,
+		codec:  config.Codec,
+		doneCh: make(chan struct{}),
+	}
+
+	if store.logger == nil {
+		store.logger = slog.Default()
+	}
+
+	if store.codec == nil {
+		store.codec = codec.Default
+	}
+
+	if !config.DisableAutoMigrate {
+		if err := store.migrate(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Set the cleanup interval from config, or use default
+	cleanupInterval := DefaultCleanupInterval
+	if config.CleanupInterval > 0 {
+		cleanupInterval = config.CleanupInterval
+	}
+
+	// Start the background goroutine for purging expired rows
+	ctx, cancel := context.WithCancel(context.Background())
+	store.cancelCleanup = cancel
+
+	go store.cleanupExpiredKeys(ctx, cleanupInterval)
+
+	return store, nil
+}
+
+// migrate creates the cache table and its expiry index if they don't exist.
+func (s *SQLStore) migrate(ctx context.Context) error {
+	for _, stmt := range s.q.migrate {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanupExpiredKeys runs in a background goroutine to purge expired rows at regular intervals.
+func (s *SQLStore) cleanupExpiredKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer func() {
+		// Notify that the cleanup goroutine has exited.
+		// This is used for deterministic shutdowns in tests.
+		if s.doneCh != nil {
+			close(s.doneCh)
+		}
+	}()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.deleteExpiredKeys(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warn("omnicache: purging expired rows failed", slog.String("driver", "sql"), slog.Any("error", err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deleteExpiredKeys removes all rows whose expiry has passed.
+func (s *SQLStore) deleteExpiredKeys(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.q.deleteExpired, time.Now().UnixNano())
+	return err
+}
+
+// Clear removes all rows from the cache table.
+func (s *SQLStore) Clear(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, s.q.clear)
+	return err
+}
+
+// Close stops the background cleanup goroutine, and closes the database
+// if the store was created with OpenSQLStore.
+// It is safe to call Close multiple times.
+func (s *SQLStore) Close(ctx context.Context) error {
+	if s.cancelCleanup != nil {
+		s.cancelCleanup()
+		s.cancelCleanup = nil // Prevent calling cancel multiple times
+
+		// Wait for an in-flight purge before closing the database under it
+		if s.ownsDB && s.doneCh != nil {
+			<-s.doneCh
+		}
+
+		if s.ownsDB {
+			return s.db.Close()
+		}
+	}
+
+	return nil
+}
+
+// Delete removes the entry associated with the given key.
+// If the key does not exist, the operation is a no-op.
+func (s *SQLStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, s.q.delete, key)
+	return err
+}
+
+// DeleteByPattern removes all entries whose keys match the given glob pattern.
+//
+// Pattern rules:
+//   - Redis-style glob syntax: '*', '?', '[abc]', '[^a-z]' and '\' escapes.
+//   - The pattern is translated to LIKE ('*' → '%', '?' → '_').
+//
+// On PostgreSQL, patterns without character classes are deleted with a
+// single DELETE ... LIKE. Otherwise, because LIKE is case-insensitive on
+// SQLite and MySQL and has no character classes, matching keys are
+// selected with LIKE, filtered exactly in Go and then deleted.
+//
+// Returns ErrInvalidValue if the pattern is malformed.
+func (s *SQLStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	// Fast path: clear all
+	if pattern == "*" {
+		return s.Clear(ctx)
+	}
+
+	re, err := glob.Compile(pattern)
+	if err != nil {
+		return omnicache.ErrInvalidValue
+	}
+
+	like, exact := globToLike(pattern)
+	if exact && s.q.caseSensitiveLike() {
+		_, err := s.db.ExecContext(ctx, s.q.deleteLike, like)
+		return err
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.q.selectLike, like)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var keysToDelete []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return err
+		}
+		if re.MatchString(key) {
+			keysToDelete = append(keysToDelete, key)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	return s.DeleteMany(ctx, keysToDelete...)
+}
+
+// DeleteMany removes multiple keys using batched DELETE ... IN statements.
+// Keys that do not exist are skipped without error.
+func (s *SQLStore) DeleteMany(ctx context.Context, keys ...string) error {
+	for start := 0; start < len(keys); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		batch := keys[start:end]
+		args := make([]any, len(batch))
+		for i, key := range batch {
+			args[i] = key
+		}
+
+		if _, err := s.db.ExecContext(ctx, s.q.deleteMany(len(batch)), args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get retrieves a value from the cache by key.
+//
+// Behavior:
+//   - If the key does not exist or is expired, returns (nil, ErrCacheMiss).
+//     Expired rows are removed by the background purge.
+//   - Otherwise returns the value: strings and []byte values as such,
+//     other values as codec.Raw.
+func (s *SQLStore) Get(ctx context.Context, key string) (any, error) {
+	var value []byte
+
+	err := s.db.QueryRowContext(ctx, s.q.get, key, time.Now().UnixNano()).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, omnicache.ErrCacheMiss
+	} else if err != nil {
+		return nil, err
+	}
+
+	return storevalue.Decode(value), nil
+}
+
+// Has checks whether a key exists and is not expired.
+func (s *SQLStore) Has(ctx context.Context, key string) (bool, error) {
+	var one int
+
+	err := s.db.QueryRowContext(ctx, s.q.has, key, time.Now().UnixNano()).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Ping checks that the database is reachable.
+func (s *SQLStore) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Set stores a value in the cache with the given key using a
+// dialect-specific upsert.
+//
+// Behavior:
+//   - TTL > 0: entry expires after duration
+//   - TTL = 0: entry never expires (expires_at is NULL)
+//   - TTL < 0: returns ErrInvalidValue
+//   - Keys longer than 255 bytes with DialectMySQL: returns ErrInvalidValue
+func (s *SQLStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	if err := s.q.checkKey(key); err != nil {
+		return err
+	}
+
+	data, err := storevalue.Encode(s.codec, value)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullInt64
+	if ttl > 0 {
+		expiresAt = sql.NullInt64{Int64: time.Now().Add(ttl).UnixNano(), Valid: true}
+	}
+
+	_, err = s.db.ExecContext(ctx, s.q.upsert, key, data, expiresAt)
+
+	return err
+}
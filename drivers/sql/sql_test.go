@@ -0,0 +1,802 @@
+package sqlstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+	_ "modernc.org/sqlite"
+)
+
+// newTestStore creates a SQLStore on a fresh SQLite database without
+// starting the background cleanup goroutine.
+func newTestStore(t *testing.T) *SQLStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	assert.NoError(t, err, "expected no error when opening database")
+	t.Cleanup(func() { db.Close() })
+
+	q, err := buildQueries(DialectSQLite, DefaultTable)
+	assert.NoError(t, err)
+
+	store := &SQLStore{db: db, q: q, logger: slog.Default()}
+	assert.NoError(t, store.migrate(context.Background()), "expected no error when migrating")
+
+	return store
+}
+
+// putRow inserts a raw row with the given expiry directly into the table.
+func putRow(t *testing.T, s *SQLStore, key, value string, expiration time.Time) {
+	t.Helper()
+
+	var expiresAt sql.NullInt64
+	if !expiration.IsZero() {
+		expiresAt = sql.NullInt64{Int64: expiration.UnixNano(), Valid: true}
+	}
+
+	_, err := s.db.Exec(s.q.upsert, key, []byte(value), expiresAt)
+	assert.NoError(t, err, "expected no error when inserting test row")
+}
+
+// keys returns all keys in the table, sorted, including expired ones.
+func keys(t *testing.T, s *SQLStore) []string {
+	t.Helper()
+
+	rows, err := s.db.Query(`SELECT "key" FROM "` + DefaultTable + `"`)
+	assert.NoError(t, err)
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var k string
+		assert.NoError(t, rows.Scan(&k))
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+func TestSQLStore_NewSQLStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      SQLConfig
+		nilDB       bool
+		expectTable bool
+		expectedErr error
+	}{
+		{
+			name:        "should create store and migrate table",
+			config:      SQLConfig{Dialect: DialectSQLite},
+			expectTable: true,
+		},
+		{
+			name:        "should skip migration when disabled",
+			config:      SQLConfig{Dialect: DialectSQLite, DisableAutoMigrate: true},
+			expectTable: false,
+		},
+		{
+			name:        "should return ErrInvalidConfig when db is nil",
+			config:      SQLConfig{Dialect: DialectSQLite},
+			nilDB:       true,
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name:        "should return ErrInvalidConfig when dialect is missing",
+			config:      SQLConfig{},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			if tt.nilDB {
+				db = nil
+			}
+
+			// --- Act ---
+			store, err := NewSQLStore(db, tt.config)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must wrap the expected error")
+				assert.Nil(t, store, "store must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating store")
+			defer store.Close(context.Background())
+
+			var name string
+			err = db.QueryRow(`SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?`, DefaultTable).Scan(&name)
+			if tt.expectTable {
+				assert.NoError(t, err, "expected cache table to exist")
+			} else {
+				assert.True(t, errors.Is(err, sql.ErrNoRows), "expected cache table not to exist")
+			}
+		})
+	}
+}
+
+func TestSQLStore_OpenSQLStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		config      func(dir string) SQLConfig
+		expectedErr error
+	}{
+		{
+			name: "should open the database and create the store",
+			config: func(dir string) SQLConfig {
+				return SQLConfig{Dialect: DialectSQLite, DriverName: "sqlite", DSN: filepath.Join(dir, "cache.db")}
+			},
+		},
+		{
+			name: "should return ErrInvalidConfig when the DSN is missing",
+			config: func(dir string) SQLConfig {
+				return SQLConfig{Dialect: DialectSQLite, DriverName: "sqlite"}
+			},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name: "should return ErrInvalidConfig when the driver is not registered",
+			config: func(dir string) SQLConfig {
+				return SQLConfig{Dialect: DialectSQLite, DriverName: "unknown", DSN: filepath.Join(dir, "cache.db")}
+			},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+		{
+			name: "should return ErrInvalidConfig when the dialect is missing",
+			config: func(dir string) SQLConfig {
+				return SQLConfig{DriverName: "sqlite", DSN: filepath.Join(dir, "cache.db")}
+			},
+			expectedErr: omnicache.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := OpenSQLStore(tt.config(t.TempDir()))
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must wrap the expected error")
+				assert.Nil(t, store, "store must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when opening store")
+			sqlStore := store.(*SQLStore)
+			assert.NoError(t, store.Set(context.Background(), "key", "value", 0), "expected the table to be migrated")
+
+			assert.NoError(t, store.Close(context.Background()), "expected no error when calling Close")
+			assert.NoError(t, store.Close(context.Background()), "expected no error when calling Close multiple times")
+			assert.Error(t, sqlStore.db.Ping(), "expected the database to be closed")
+		})
+	}
+}
+
+func TestSQLStore_newFromOptions(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	m, err := omnicache.NewManagerFromConfig(map[string]any{
+		"stores": map[string]any{
+			"db": map[string]any{
+				"driver": DriverName,
+				"options": map[string]any{
+					"dialect":     "sqlite",
+					"driver_name": "sqlite",
+					"dsn":         filepath.Join(t.TempDir(), "cache.db"),
+				},
+			},
+		},
+	})
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when creating the manager")
+	t.Cleanup(func() { _ = m.Close(context.Background()) })
+
+	assert.NoError(t, m.Set(context.Background(), "k", "v", time.Minute), "expected the sql store to be registered")
+	value, err := m.Get(context.Background(), "k")
+	assert.NoError(t, err, "expected no error from the sql store")
+	assert.Equal(t, "v", value, "expected the stored value")
+}
+
+func TestSQLStore_Close(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should stop cleanup goroutine and leave database open", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		store, err := NewSQLStore(db, SQLConfig{Dialect: DialectSQLite, CleanupInterval: 50 * time.Millisecond})
+		assert.NoError(t, err)
+
+		sqlStore := store.(*SQLStore)
+
+		// --- Act ---
+		err = sqlStore.Close(context.Background())
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when calling Close")
+
+		select {
+		case <-sqlStore.doneCh:
+			// success — cleanup goroutine stopped
+		case <-time.After(200 * time.Millisecond):
+			t.Fatal("expected cleanup goroutine to stop quickly")
+		}
+
+		assert.NoError(t, sqlStore.Close(context.Background()), "expected no error when calling Close multiple times")
+		assert.NoError(t, db.Ping(), "expected database to remain open")
+	})
+}
+
+func TestSQLStore_cleanupExpiredKeys(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putRow(t, store, "expired", `"a"`, time.Now().Add(-time.Minute))
+	putRow(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+	store.doneCh = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// --- Act ---
+	go store.cleanupExpiredKeys(ctx, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-store.doneCh
+
+	// --- Assert ---
+	assert.Equal(t, []string{"valid"}, keys(t, store), "expected only the valid key to remain")
+}
+
+func TestSQLStore_deleteExpiredKeys(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putRow(t, store, "expired", `"a"`, time.Now().Add(-time.Minute))
+	putRow(t, store, "valid", `"b"`, time.Now().Add(time.Hour))
+	putRow(t, store, "forever", `"c"`, time.Time{})
+
+	// --- Act ---
+	err := store.deleteExpiredKeys(context.Background())
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when purging expired rows")
+	assert.Equal(t, []string{"forever", "valid"}, keys(t, store), "expected non-expired rows to remain")
+}
+
+func TestSQLStore_Clear(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := newTestStore(t)
+	putRow(t, store, "user:1", `"John"`, time.Time{})
+	putRow(t, store, "user:2", `"Jane"`, time.Time{})
+
+	// --- Act ---
+	err := store.Clear(context.Background())
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when clearing store")
+	assert.Empty(t, keys(t, store), "expected table to be empty after Clear()")
+}
+
+func TestSQLStore_Delete(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+	putRow(t, store, "key", `"value"`, time.Time{})
+	putRow(t, store, "other", `"value"`, time.Time{})
+
+	// --- Act ---
+	err := store.Delete(ctx, "key")
+	errMissing := store.Delete(ctx, "missing")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting existing key")
+	assert.NoError(t, errMissing, "expected no error when deleting missing key")
+	assert.Equal(t, []string{"other"}, keys(t, store), "expected only the other key to remain")
+}
+
+func TestSQLStore_DeleteByPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		keys                  []string
+		pattern               string
+		expectedRemainingKeys []string
+		expectedErr           error
+	}{
+		{
+			name:                  "should delete keys matching prefix pattern",
+			keys:                  []string{"user:1", "user:2", "product:1"},
+			pattern:               "user:*",
+			expectedRemainingKeys: []string{"product:1"},
+		},
+		{
+			name:                  "should delete all keys when using global '*' pattern",
+			keys:                  []string{"user:1", "product:1"},
+			pattern:               "*",
+			expectedRemainingKeys: []string{},
+		},
+		{
+			name:                  "should match case-sensitively even though SQLite LIKE does not",
+			keys:                  []string{"user:1", "USER:2"},
+			pattern:               "user:*",
+			expectedRemainingKeys: []string{"USER:2"},
+		},
+		{
+			name:                  "should treat LIKE wildcards in keys literally",
+			keys:                  []string{"50%_off", "50abcoff"},
+			pattern:               "50%_off",
+			expectedRemainingKeys: []string{"50abcoff"},
+		},
+		{
+			name:                  "should filter character classes exactly",
+			keys:                  []string{"item:1", "item:2", "item:3"},
+			pattern:               "item:[12]",
+			expectedRemainingKeys: []string{"item:3"},
+		},
+		{
+			name:                  "should do nothing when pattern is empty",
+			keys:                  []string{"user:1"},
+			pattern:               "",
+			expectedRemainingKeys: []string{"user:1"},
+		},
+		{
+			name:                  "should return ErrInvalidValue when pattern is malformed",
+			keys:                  []string{"user:1"},
+			pattern:               "user:[1",
+			expectedRemainingKeys: []string{"user:1"},
+			expectedErr:           omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			for _, key := range tt.keys {
+				putRow(t, store, key, `"v"`, time.Time{})
+			}
+
+			// --- Act ---
+			err := store.DeleteByPattern(context.Background(), tt.pattern)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+			} else {
+				assert.NoError(t, err, "expected no error when deleting by pattern")
+			}
+
+			assert.Equal(t, tt.expectedRemainingKeys, keys(t, store), "remaining keys must match")
+		})
+	}
+}
+
+func TestSQLStore_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		count         int
+		deleteCount   int
+		expectedCount int
+	}{
+		{
+			name:          "should delete a few keys in one statement",
+			count:         3,
+			deleteCount:   2,
+			expectedCount: 1,
+		},
+		{
+			name:          "should delete more keys than one batch holds",
+			count:         deleteBatchSize + 10,
+			deleteCount:   deleteBatchSize + 5,
+			expectedCount: 5,
+		},
+		{
+			name:          "should do nothing when no keys are provided",
+			count:         2,
+			deleteCount:   0,
+			expectedCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			var all []string
+			for i := 0; i < tt.count; i++ {
+				key := fmt.Sprintf("key:%04d", i)
+				all = append(all, key)
+				putRow(t, store, key, `"v"`, time.Time{})
+			}
+
+			// --- Act ---
+			err := store.DeleteMany(context.Background(), all[:tt.deleteCount]...)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when deleting many keys")
+			assert.Equal(t, tt.expectedCount, len(keys(t, store)), "remaining key count must match")
+		})
+	}
+}
+
+func TestSQLStore_Get(t *testing.T) {
+	t.Parallel()
+
+	encoded, err := codec.Encode(codec.Default, map[string]int{"id": 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, s *SQLStore)
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name: "should return value when key exists and is not expired",
+			setup: func(t *testing.T, s *SQLStore) {
+				assert.NoError(t, s.Set(context.Background(), "key", "value", time.Hour))
+			},
+			expectedVal: "value",
+		},
+		{
+			name: "should return other values as codec.Raw when key has no expiration",
+			setup: func(t *testing.T, s *SQLStore) {
+				assert.NoError(t, s.Set(context.Background(), "key", map[string]int{"id": 1}, 0))
+			},
+			expectedVal: codec.Raw(encoded),
+		},
+		{
+			name:        "should return ErrCacheMiss when key does not exist",
+			setup:       func(t *testing.T, s *SQLStore) {},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should return ErrCacheMiss when key is expired",
+			setup: func(t *testing.T, s *SQLStore) {
+				putRow(t, s, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			tt.setup(t, store)
+
+			// --- Act ---
+			val, err := store.Get(context.Background(), "key")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Nil(t, val, "value must be nil on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when getting key")
+			assert.Equal(t, tt.expectedVal, val, "value must match the stored value")
+		})
+	}
+}
+
+func TestSQLStore_Has(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, s *SQLStore)
+		expected bool
+	}{
+		{
+			name: "should return true when key exists and is not expired",
+			setup: func(t *testing.T, s *SQLStore) {
+				putRow(t, s, "key", `"value"`, time.Now().Add(time.Hour))
+			},
+			expected: true,
+		},
+		{
+			name:     "should return false when key does not exist",
+			setup:    func(t *testing.T, s *SQLStore) {},
+			expected: false,
+		},
+		{
+			name: "should return false when key is expired",
+			setup: func(t *testing.T, s *SQLStore) {
+				putRow(t, s, "key", `"value"`, time.Now().Add(-time.Second))
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			store := newTestStore(t)
+			tt.setup(t, store)
+
+			// --- Act ---
+			ok, err := store.Has(context.Background(), "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when checking key")
+			assert.Equal(t, tt.expected, ok, "existence must match the expected value")
+		})
+	}
+}
+
+func TestSQLStore_Set(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		value       any
+		ttl         time.Duration
+		expectedVal any
+		expectedErr error
+	}{
+		{
+			name:        "should store string value as is",
+			value:       "hello",
+			ttl:         time.Minute,
+			expectedVal: "hello",
+		},
+		{
+			name:        "should store struct value with the codec without expiration",
+			value:       map[string]int{"id": 1},
+			ttl:         0,
+			expectedVal: codec.Raw("\x01{\"id\":1}"),
+		},
+		{
+			name:        "should return ErrInvalidValue when ttl is negative",
+			value:       "hello",
+			ttl:         -time.Second,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newTestStore(t)
+
+			// --- Act ---
+			err := store.Set(ctx, "key", tt.value, tt.ttl)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				assert.Empty(t, keys(t, store), "expected nothing to be written")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when setting key")
+
+			val, err := store.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedVal, val, "stored value must round-trip")
+		})
+	}
+
+	t.Run("should overwrite existing key and its expiry via upsert", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := newTestStore(t)
+		putRow(t, store, "key", `"old"`, time.Now().Add(-time.Second))
+
+		// --- Act ---
+		err := store.Set(ctx, "key", "new", 0)
+
+		// --- Assert ---
+		assert.NoError(t, err)
+		val, err := store.Get(ctx, "key")
+		assert.NoError(t, err, "expected overwritten key to no longer be expired")
+		assert.Equal(t, "new", val)
+	})
+}
+
+func TestSQLStore_caseDistinctKeys(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+	assert.NoError(t, store.Set(ctx, "User:1", "upper", 0))
+	assert.NoError(t, store.Set(ctx, "user:1", "lower", 0))
+
+	// --- Act ---
+	err := store.DeleteByPattern(ctx, "user:*")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when deleting by pattern")
+	assert.Equal(t, []string{"User:1"}, keys(t, store), "expected keys differing in case to be distinct")
+
+	val, err := store.Get(ctx, "User:1")
+	assert.NoError(t, err, "expected the key to be found")
+	assert.Equal(t, "upper", val, "expected the value of the key with the same case")
+}
+
+func TestSQLStore_managerGetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{name: "should stream strings that look like base64 as is", value: "user"},
+		{name: "should stream padded base64 strings as is", value: "dGVzdA=="},
+		{name: "should stream []byte values as is", value: []byte("test")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			m := omnicache.NewManager()
+			assert.NoError(t, m.Register("sql", newTestStore(t)))
+			assert.NoError(t, m.Set(ctx, "key", tt.value, 0))
+
+			// --- Act ---
+			reader, err := m.GetReader(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when opening reader")
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "expected no error when reading")
+			assert.NoError(t, reader.Close(), "expected no error when closing")
+			assert.Equal(t, fmt.Sprintf("%s", tt.value), string(data), "streamed value must match the stored value")
+		})
+	}
+}
+
+func TestSQLStore_cleanupExpiredKeys_logsErrors(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	var buf bytes.Buffer
+	store := newTestStore(t)
+	store.logger = slog.New(slog.NewTextHandler(&buf, nil))
+	store.doneCh = make(chan struct{})
+	assert.NoError(t, store.db.Close(), "expected no error when closing the database")
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// --- Act ---
+	go store.cleanupExpiredKeys(ctx, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-store.doneCh
+
+	// --- Assert ---
+	assert.True(t, strings.Contains(buf.String(), "purging expired rows failed"), "expected the cleanup error to be logged")
+}
+
+func TestSQLStore_codec(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id" cbor:"id"`
+		Name string `json:"name" cbor:"name"`
+	}
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+	assert.NoError(t, store.Set(ctx, "before", user{ID: 1, Name: "a"}, 0), "expected no error when setting key")
+	store.codec = codec.CBOR
+
+	// --- Act ---
+	err := store.Set(ctx, "after", user{ID: 2, Name: "b"}, 0)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when setting key")
+
+	after, err := store.Get(ctx, "after")
+	assert.NoError(t, err, "expected no error when getting key")
+	raw, ok := after.(codec.Raw)
+	assert.True(t, ok, "expected the value to be returned as codec.Raw")
+	assert.Equal(t, codec.IDCBOR, raw[0], "expected the value to be encoded with the configured codec")
+
+	var got user
+	assert.NoError(t, codec.Decode(raw, nil, &got), "expected the value to decode")
+	assert.Equal(t, user{ID: 2, Name: "b"}, got)
+
+	before, err := store.Get(ctx, "before")
+	assert.NoError(t, err, "expected no error when getting key")
+	assert.NoError(t, codec.Decode(before.(codec.Raw), nil, &got), "expected values of the previous codec to remain readable")
+	assert.Equal(t, user{ID: 1, Name: "a"}, got)
+
+}
+
+func TestSQLStore_Ping(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// --- Act ---
+	errOpen := store.Ping(ctx)
+	assert.NoError(t, store.db.Close())
+	errClosed := store.Ping(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, errOpen, "expected no error while the database is open")
+	assert.Error(t, errClosed, "expected an error once the database is closed")
+}
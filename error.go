@@ -4,11 +4,14 @@ import "errors"
 
 var (
 	ErrCacheMiss              = errors.New("cache: cache miss")
+	ErrCircuitOpen            = errors.New("cache: circuit open")
 	ErrInternal               = errors.New("cache: internal error")
 	ErrInvalidConfig          = errors.New("cache: invalid config")
 	ErrInvalidDefaultStore    = errors.New("cache: invalid default cache store")
 	ErrInvalidStore           = errors.New("cache: invalid cache store")
 	ErrStoreAlreadyRegistered = errors.New("cache: store already registered")
 	ErrInvalidValue           = errors.New("cache: invalid value")
+	ErrIntegrity              = errors.New("cache: value failed integrity check")
+	ErrPatternNotSupported    = errors.New("cache: delete by pattern not supported by store")
 	ErrTypeMismatch           = errors.New("cache: value type mismatch")
 )
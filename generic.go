@@ -28,7 +28,7 @@ func (g *GenericManager[T]) Get(ctx context.Context, key string) (T, error) {
 		return zero, err
 	}
 
-	result, err := convertAnyToType[T](val)
+	result, err := convertAnyToType[T](val, g.m.codec)
 	if err != nil {
 		var zero T
 		return zero, ErrTypeMismatch
@@ -42,23 +42,47 @@ func (g *GenericManager[T]) Get(ctx context.Context, key string) (T, error) {
 // the given TTL, and returns it. If storing fails, it still returns
 // the computed value along with the store error.
 func (g *GenericManager[T]) GetOrSet(ctx context.Context, key string, ttl time.Duration, defaultFn func() (T, error)) (T, error) {
+	call := &Call{Op: OpGetOrSet, Keys: []string{key}, TTL: ttl}
+	call.Load = func(context.Context) (any, error) { return defaultFn() }
+
+	err := g.m.run(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Result, err = g.getOrSet(ctx, call.Keys[0], call.TTL, call.Load)
+		return err
+	})
+
+	result, ok := call.Result.(T)
+	if !ok {
+		var zero T
+		return zero, err
+	}
+
+	return result, err
+}
+
+func (g *GenericManager[T]) getOrSet(ctx context.Context, key string, ttl time.Duration, load func(context.Context) (any, error)) (T, error) {
 	val, err := g.Get(ctx, key)
 	if err == nil {
 		return val, nil
 	}
 
-	if !errors.Is(err, ErrCacheMiss) && !errors.Is(err, ErrTypeMismatch) {
+	if !errors.Is(err, ErrCacheMiss) && !errors.Is(err, ErrTypeMismatch) && !g.m.failsOpen(ctx, err) {
 		var zero T
 		return zero, err
 	}
 
-	defaultValue, err := defaultFn()
+	loaded, err := load(ctx)
+	g.m.recordLoad(err)
 	if err != nil {
 		var zero T
 		return zero, err
 	}
 
-	if err := g.m.Set(ctx, key, defaultValue, ttl); err != nil {
+	defaultValue, ok := loaded.(T)
+	if !ok && loaded != nil {
+		return defaultValue, ErrTypeMismatch
+	}
+
+	if err := g.m.Set(ctx, key, defaultValue, ttl); err != nil && !g.m.failsOpen(ctx, err) {
 		return defaultValue, err
 	}
 
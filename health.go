@@ -0,0 +1,183 @@
+package omnicache
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// DefaultHealthTimeout is how long Health waits for each store by default.
+const DefaultHealthTimeout = 2 * time.Second
+
+// healthProbeKey is the key Has is called with for stores that do not
+// implement contract.Pinger.
+const healthProbeKey = "omnicache:health"
+
+// HealthStatus is the outcome of a health check.
+type HealthStatus string
+
+const (
+	HealthUp   HealthStatus = "up"
+	HealthDown HealthStatus = "down"
+)
+
+// StoreHealth is the result of checking a single store.
+type StoreHealth struct {
+	Status HealthStatus
+
+	// Latency is how long the store took to answer, or the timeout if it
+	// did not answer in time.
+	Latency time.Duration
+
+	// Err is the error the check failed with; nil when the store is up.
+	Err error
+}
+
+// Health is the result of Manager.Health.
+type Health struct {
+	// Status is HealthUp if every store is up.
+	Status HealthStatus
+
+	// Stores holds the result of every registered store by alias.
+	Stores map[string]StoreHealth
+}
+
+// Health checks every registered store concurrently and reports whether
+// each can serve requests. Stores implementing contract.Pinger are pinged;
+// others are probed with a Has call. A store that does not answer within
+// the health timeout (see WithHealthTimeout), or before ctx is done, is
+// reported down.
+//
+// Checks bypass middleware and are not counted in Stats.
+func (m *Manager) Health(ctx context.Context) Health {
+	m.mu.RLock()
+	timeout := m.healthTimeout
+	m.mu.RUnlock()
+
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+
+	reg := m.registry()
+	reg.mu.RLock()
+	stores := make(map[string]contract.Store, len(reg.stores))
+	for alias, store := range reg.stores {
+		stores[alias] = store
+	}
+	reg.mu.RUnlock()
+
+	health := Health{
+		Status: HealthUp,
+		Stores: make(map[string]StoreHealth, len(stores)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for alias, store := range stores {
+		wg.Add(1)
+		go func(alias string, store contract.Store) {
+			defer wg.Done()
+
+			result := checkStore(ctx, store, timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			health.Stores[alias] = result
+			if result.Status != HealthUp {
+				health.Status = HealthDown
+			}
+		}(alias, store)
+	}
+	wg.Wait()
+
+	return health
+}
+
+// checkStore pings store, giving up after timeout even if the store does
+// not honour ctx.
+func checkStore(ctx context.Context, store contract.Store, timeout time.Duration) StoreHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	start := time.Now()
+
+	go func() {
+		if pinger, ok := store.(contract.Pinger); ok {
+			done <- pinger.Ping(ctx)
+			return
+		}
+
+		_, err := store.Has(ctx, healthProbeKey)
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := StoreHealth{Status: HealthUp, Latency: time.Since(start)}
+	if err != nil {
+		result.Status = HealthDown
+		result.Err = err
+	}
+
+	return result
+}
+
+// HealthHandler returns an http.Handler serving the result of Health as
+// JSON, for use as a readiness probe. It responds with 200 OK if every
+// store is up and 503 Service Unavailable otherwise:
+//
+//	{"status":"down","stores":{"main":{"status":"up","latency_ms":0.4},
+//	 "remote":{"status":"down","latency_ms":2000,"error":"context deadline exceeded"}}}
+//
+// Error messages may name hosts or paths, so serve it on an internal port.
+func (m *Manager) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := m.Health(r.Context())
+
+		type storeResponse struct {
+			Status    HealthStatus `json:"status"`
+			LatencyMS float64      `json:"latency_ms"`
+			Error     string       `json:"error,omitempty"`
+		}
+
+		response := struct {
+			Status HealthStatus             `json:"status"`
+			Stores map[string]storeResponse `json:"stores"`
+		}{
+			Status: health.Status,
+			Stores: make(map[string]storeResponse, len(health.Stores)),
+		}
+
+		for alias, store := range health.Stores {
+			s := storeResponse{
+				Status:    store.Status,
+				LatencyMS: float64(store.Latency) / float64(time.Millisecond),
+			}
+			if store.Err != nil {
+				s.Error = store.Err.Error()
+			}
+			response.Stores[alias] = s
+		}
+
+		status := http.StatusOK
+		if health.Status != HealthUp {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
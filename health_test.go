@@ -0,0 +1,204 @@
+package omnicache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// pingStore is a store implementing contract.Pinger. Ping fails with err,
+// or blocks until release is closed, ignoring ctx, if release is set.
+type pingStore struct {
+	contract.Store
+	err     error
+	release chan struct{}
+}
+
+func (s *pingStore) Ping(ctx context.Context) error {
+	if s.release != nil {
+		<-s.release
+	}
+
+	return s.err
+}
+
+// hasStore is a store without Ping whose Has fails with err.
+type hasStore struct {
+	contract.Store
+	err   error
+	calls chan string
+}
+
+func (s *hasStore) Has(ctx context.Context, key string) (bool, error) {
+	s.calls <- key
+	return false, s.err
+}
+
+func TestManager_Health(t *testing.T) {
+	t.Parallel()
+
+	pingErr := errors.New("connection refused")
+
+	tests := []struct {
+		name           string
+		stores         func(t *testing.T) map[string]contract.Store
+		expectedStatus HealthStatus
+		expectedStores map[string]HealthStatus
+		expectedErrs   map[string]error
+	}{
+		{
+			name: "should report up when every store answers",
+			stores: func(t *testing.T) map[string]contract.Store {
+				return map[string]contract.Store{
+					"pinger": &pingStore{},
+					"probed": &hasStore{calls: make(chan string, 1)},
+				}
+			},
+			expectedStatus: HealthUp,
+			expectedStores: map[string]HealthStatus{"pinger": HealthUp, "probed": HealthUp},
+		},
+		{
+			name: "should report down when a ping fails",
+			stores: func(t *testing.T) map[string]contract.Store {
+				return map[string]contract.Store{
+					"ok":     &pingStore{},
+					"broken": &pingStore{err: pingErr},
+				}
+			},
+			expectedStatus: HealthDown,
+			expectedStores: map[string]HealthStatus{"ok": HealthUp, "broken": HealthDown},
+			expectedErrs:   map[string]error{"broken": pingErr},
+		},
+		{
+			name: "should report down when a probe fails",
+			stores: func(t *testing.T) map[string]contract.Store {
+				return map[string]contract.Store{
+					"probed": &hasStore{err: pingErr, calls: make(chan string, 1)},
+				}
+			},
+			expectedStatus: HealthDown,
+			expectedStores: map[string]HealthStatus{"probed": HealthDown},
+			expectedErrs:   map[string]error{"probed": pingErr},
+		},
+		{
+			name: "should report down when a store does not answer in time",
+			stores: func(t *testing.T) map[string]contract.Store {
+				release := make(chan struct{})
+				t.Cleanup(func() { close(release) })
+				return map[string]contract.Store{
+					"ok":   &pingStore{},
+					"hung": &pingStore{release: release},
+				}
+			},
+			expectedStatus: HealthDown,
+			expectedStores: map[string]HealthStatus{"ok": HealthUp, "hung": HealthDown},
+			expectedErrs:   map[string]error{"hung": context.DeadlineExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			m := NewManager(WithHealthTimeout(20 * time.Millisecond))
+			for alias, store := range tt.stores(t) {
+				assert.NoError(t, m.Register(alias, store))
+			}
+
+			// --- Act ---
+			health := m.Health(context.Background())
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedStatus, health.Status, "expected the overall status")
+			assert.Equal(t, len(tt.expectedStores), len(health.Stores), "expected a result for every store")
+			for alias, expected := range tt.expectedStores {
+				result := health.Stores[alias]
+				assert.Equal(t, expected, result.Status, "expected the status of "+alias)
+				assert.True(t, errors.Is(result.Err, tt.expectedErrs[alias]), "expected the error of "+alias)
+				assert.True(t, result.Latency < time.Second, "expected the latency of "+alias+" to be bounded by the timeout")
+			}
+		})
+	}
+}
+
+func TestManager_Health_probeKey(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := &hasStore{calls: make(chan string, 1)}
+	m := NewManager()
+	assert.NoError(t, m.Register("probed", store))
+
+	// --- Act ---
+	_ = m.Store("probed").Health(context.Background())
+
+	// --- Assert ---
+	assert.Equal(t, healthProbeKey, <-store.calls, "expected stores without Ping to be probed with Has")
+	assert.Equal(t, 0, len(m.Stats()["probed"].Latency), "expected checks not to be counted")
+}
+
+func TestManager_HealthHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		store        contract.Store
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "should respond 200 when every store is up",
+			store:        &pingStore{},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"up","stores":{"main":{"status":"up"}}}`,
+		},
+		{
+			name:         "should respond 503 with the error when a store is down",
+			store:        &pingStore{err: errors.New("connection refused")},
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"down","stores":{"main":{"status":"down","error":"connection refused"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			m := NewManager()
+			assert.NoError(t, m.Register("main", tt.store))
+
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+
+			// --- Act ---
+			m.HealthHandler().ServeHTTP(rec, req)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedCode, rec.Code, "expected the status code")
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "expected a JSON response")
+
+			var body map[string]any
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), "expected a valid JSON body")
+			stores := body["stores"].(map[string]any)
+			main := stores["main"].(map[string]any)
+			_, hasLatency := main["latency_ms"]
+			assert.True(t, hasLatency, "expected the latency to be reported")
+			delete(main, "latency_ms")
+
+			var expected map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(tt.expectedBody), &expected))
+			assert.Equal(t, expected, body, "expected the body")
+		})
+	}
+}
@@ -4,18 +4,24 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/bytedance/sonic"
+	"github.com/shoraid/omnicache/codec"
 )
 
 // convertAnyToType converts any Go value to a target generic type T.
 // It supports primitives, []byte, and slices efficiently.
-func convertAnyToType[T any](v any) (T, error) {
+// Encoded payloads are decoded with c, or with the codec named by their
+// header byte when they have one.
+func convertAnyToType[T any](v any, c codec.Codec) (T, error) {
 	var zero T
 
 	if v == nil {
 		return zero, ErrTypeMismatch
 	}
 
+	if c == nil {
+		c = codec.Default
+	}
+
 	switch val := any(v).(type) {
 
 	// --- Fast paths for primitives ---
@@ -23,41 +29,48 @@ func convertAnyToType[T any](v any) (T, error) {
 		return val, nil
 
 	case string:
-		return fromString[T](val)
+		return fromString[T](val, c)
 
 	case []byte:
-		return fromBytes[T](val)
+		return fromBytes[T](val, c)
+
+	case codec.Raw:
+		var out T
+		if err := codec.Decode(val, c, &out); err != nil {
+			return zero, fmt.Errorf("decode error: %w", err)
+		}
+		return out, nil
 
 	case int:
-		return fromStringOrNumber[T](strconv.Itoa(val))
+		return fromStringOrNumber[T](strconv.Itoa(val), c)
 
 	case int64:
-		return fromStringOrNumber[T](strconv.FormatInt(val, 10))
+		return fromStringOrNumber[T](strconv.FormatInt(val, 10), c)
 
 	case float64:
-		return fromStringOrNumber[T](strconv.FormatFloat(val, 'f', -1, 64))
+		return fromStringOrNumber[T](strconv.FormatFloat(val, 'f', -1, 64), c)
 
 	case bool:
-		return fromStringOrNumber[T](strconv.FormatBool(val))
+		return fromStringOrNumber[T](strconv.FormatBool(val), c)
 
 	default:
 		var out T
 
 		// --- convert struct/slice to string
 		if _, ok := any(zero).(string); ok {
-			b, err := sonic.Marshal(val)
+			b, err := c.Marshal(val)
 			if err != nil {
 				return zero, fmt.Errorf("marshal error: %w", err)
 			}
 			return any(string(b)).(T), nil
 		}
 
-		// --- Fallback: JSON round-trip
-		b, err := sonic.Marshal(val)
+		// --- Fallback: codec round-trip
+		b, err := c.Marshal(val)
 		if err != nil {
 			return zero, fmt.Errorf("marshal error: %w", err)
 		}
-		if err := sonic.Unmarshal(b, &out); err != nil {
+		if err := c.Unmarshal(b, &out); err != nil {
 			return zero, fmt.Errorf("unmarshal error: %w", err)
 		}
 		return out, nil
@@ -65,7 +78,7 @@ func convertAnyToType[T any](v any) (T, error) {
 }
 
 // fromString converts a string to a target generic type T.
-func fromString[T any](s string) (T, error) {
+func fromString[T any](s string, c codec.Codec) (T, error) {
 	var zero T
 	var result any
 	var err error
@@ -87,7 +100,7 @@ func fromString[T any](s string) (T, error) {
 		result = []byte(s)
 	default:
 		var out T
-		if err := sonic.Unmarshal([]byte(s), &out); err != nil {
+		if err := codec.Decode([]byte(s), c, &out); err != nil {
 			return zero, fmt.Errorf("unsupported conversion from string to %T: %w", zero, err)
 		}
 		return out, nil
@@ -101,7 +114,7 @@ func fromString[T any](s string) (T, error) {
 }
 
 // fromBytes converts a byte slice to a target generic type T.
-func fromBytes[T any](b []byte) (T, error) {
+func fromBytes[T any](b []byte, c codec.Codec) (T, error) {
 	var zero T
 	var result any
 	var err error
@@ -122,7 +135,7 @@ func fromBytes[T any](b []byte) (T, error) {
 	default:
 		// Fallback for struct or unknown type
 		var out T
-		if err := sonic.Unmarshal(b, &out); err != nil {
+		if err := codec.Decode(b, c, &out); err != nil {
 			return zero, fmt.Errorf("unsupported conversion from string to %T: %w", zero, err)
 		}
 		return out, nil
@@ -136,6 +149,6 @@ func fromBytes[T any](b []byte) (T, error) {
 }
 
 // fromStringOrNumber converts a string to a target generic type T.
-func fromStringOrNumber[T any](s string) (T, error) {
-	return fromString[T](s)
+func fromStringOrNumber[T any](s string, c codec.Codec) (T, error) {
+	return fromString[T](s, c)
 }
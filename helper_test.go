@@ -3,6 +3,7 @@ package omnicache
 import (
 	"testing"
 
+	"github.com/shoraid/omnicache/codec"
 	"github.com/shoraid/omnicache/internal/assert"
 )
 
@@ -107,27 +108,27 @@ func TestHelper_convertAnyToType(t *testing.T) {
 
 			switch tt.expected.(type) {
 			case string:
-				result, err = convertAnyToType[string](tt.input)
+				result, err = convertAnyToType[string](tt.input, codec.Default)
 			case int:
-				result, err = convertAnyToType[int](tt.input)
+				result, err = convertAnyToType[int](tt.input, codec.Default)
 			case int64:
-				result, err = convertAnyToType[int64](tt.input)
+				result, err = convertAnyToType[int64](tt.input, codec.Default)
 			case float64:
-				result, err = convertAnyToType[float64](tt.input)
+				result, err = convertAnyToType[float64](tt.input, codec.Default)
 			case bool:
-				result, err = convertAnyToType[bool](tt.input)
+				result, err = convertAnyToType[bool](tt.input, codec.Default)
 			case []byte:
-				result, err = convertAnyToType[[]byte](tt.input)
+				result, err = convertAnyToType[[]byte](tt.input, codec.Default)
 			case dummy:
-				result, err = convertAnyToType[dummy](tt.input)
+				result, err = convertAnyToType[dummy](tt.input, codec.Default)
 			case []string:
-				result, err = convertAnyToType[[]string](tt.input)
+				result, err = convertAnyToType[[]string](tt.input, codec.Default)
 			case []int:
-				result, err = convertAnyToType[[]int](tt.input)
+				result, err = convertAnyToType[[]int](tt.input, codec.Default)
 			case nil:
-				result, err = convertAnyToType[any](tt.input)
+				result, err = convertAnyToType[any](tt.input, codec.Default)
 			case map[string]any:
-				result, err = convertAnyToType[map[string]any](tt.input)
+				result, err = convertAnyToType[map[string]any](tt.input, codec.Default)
 			default:
 				t.Fatalf("unsupported type in test: %T", tt.expected)
 			}
@@ -193,27 +194,27 @@ func TestHelper_fromString(t *testing.T) {
 
 			switch tt.expected.(type) {
 			case string:
-				result, err = fromString[string](tt.input)
+				result, err = fromString[string](tt.input, codec.Default)
 			case int:
-				result, err = fromString[int](tt.input)
+				result, err = fromString[int](tt.input, codec.Default)
 			case int64:
-				result, err = fromString[int64](tt.input)
+				result, err = fromString[int64](tt.input, codec.Default)
 			case float64:
-				result, err = fromString[float64](tt.input)
+				result, err = fromString[float64](tt.input, codec.Default)
 			case bool:
-				result, err = fromString[bool](tt.input)
+				result, err = fromString[bool](tt.input, codec.Default)
 			case []byte:
-				result, err = fromString[[]byte](tt.input)
+				result, err = fromString[[]byte](tt.input, codec.Default)
 			case []string:
-				result, err = fromString[[]string](tt.input)
+				result, err = fromString[[]string](tt.input, codec.Default)
 			case []int:
-				result, err = fromString[[]int](tt.input)
+				result, err = fromString[[]int](tt.input, codec.Default)
 			case dummy:
-				result, err = fromString[dummy](tt.input)
+				result, err = fromString[dummy](tt.input, codec.Default)
 			case uint:
-				result, err = fromString[uint](tt.input)
+				result, err = fromString[uint](tt.input, codec.Default)
 			case uint64:
-				result, err = fromString[uint64](tt.input)
+				result, err = fromString[uint64](tt.input, codec.Default)
 			default:
 				t.Fatalf("unsupported type in test: %T", tt.expected)
 			}
@@ -278,25 +279,25 @@ func TestHelper_fromBytes(t *testing.T) {
 
 			switch tt.expected.(type) {
 			case string:
-				result, err = fromBytes[string](tt.input)
+				result, err = fromBytes[string](tt.input, codec.Default)
 			case int:
-				result, err = fromBytes[int](tt.input)
+				result, err = fromBytes[int](tt.input, codec.Default)
 			case int64:
-				result, err = fromBytes[int64](tt.input)
+				result, err = fromBytes[int64](tt.input, codec.Default)
 			case float64:
-				result, err = fromBytes[float64](tt.input)
+				result, err = fromBytes[float64](tt.input, codec.Default)
 			case bool:
-				result, err = fromBytes[bool](tt.input)
+				result, err = fromBytes[bool](tt.input, codec.Default)
 			case []string:
-				result, err = fromBytes[[]string](tt.input)
+				result, err = fromBytes[[]string](tt.input, codec.Default)
 			case []int:
-				result, err = fromBytes[[]int](tt.input)
+				result, err = fromBytes[[]int](tt.input, codec.Default)
 			case dummy:
-				result, err = fromBytes[dummy](tt.input)
+				result, err = fromBytes[dummy](tt.input, codec.Default)
 			case uint:
-				result, err = fromBytes[uint](tt.input)
+				result, err = fromBytes[uint](tt.input, codec.Default)
 			case uint64:
-				result, err = fromBytes[uint64](tt.input)
+				result, err = fromBytes[uint64](tt.input, codec.Default)
 			default:
 				t.Fatalf("unsupported type in test: %T", tt.expected)
 			}
@@ -339,15 +340,15 @@ func TestHelper_fromStringOrNumber(t *testing.T) {
 
 			switch tt.expected.(type) {
 			case string:
-				result, err = fromStringOrNumber[string](tt.input)
+				result, err = fromStringOrNumber[string](tt.input, codec.Default)
 			case int:
-				result, err = fromStringOrNumber[int](tt.input)
+				result, err = fromStringOrNumber[int](tt.input, codec.Default)
 			case int64:
-				result, err = fromStringOrNumber[int64](tt.input)
+				result, err = fromStringOrNumber[int64](tt.input, codec.Default)
 			case float64:
-				result, err = fromStringOrNumber[float64](tt.input)
+				result, err = fromStringOrNumber[float64](tt.input, codec.Default)
 			case bool:
-				result, err = fromStringOrNumber[bool](tt.input)
+				result, err = fromStringOrNumber[bool](tt.input, codec.Default)
 			default:
 				t.Fatalf("unsupported type in test: %T", tt.expected)
 			}
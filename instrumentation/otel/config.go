@@ -0,0 +1,51 @@
+package otelcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultMaxKeys is the number of keys recorded on a span.
+const DefaultMaxKeys = 10
+
+type OtelConfig struct {
+
+	// TracerProvider creates the tracer spans are recorded with.
+	//
+	// default: otel.GetTracerProvider()
+	TracerProvider trace.TracerProvider
+
+	// MeterProvider creates the meter instruments are recorded with.
+	//
+	// default: otel.GetMeterProvider()
+	MeterProvider metric.MeterProvider
+
+	// RedactKey maps a key or pattern to the value recorded in the
+	// "cache.keys" and "cache.pattern" span attributes. Keys often hold
+	// user identifiers, so they are not recorded unless RedactKey is set;
+	// use PlainKey to record them as is or HashKey to record a digest.
+	// Keys are never recorded on metrics.
+	//
+	// default: nil, keys are not recorded
+	RedactKey func(key string) string
+
+	// MaxKeys caps the number of keys recorded on a span, e.g. for DeleteMany.
+	//
+	// default: 10
+	MaxKeys int
+}
+
+// PlainKey records keys as is.
+func PlainKey(key string) string {
+	return key
+}
+
+// HashKey records the first 8 bytes of the SHA-256 of a key, hex-encoded.
+// Equal keys have equal digests, so spans can still be correlated.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,217 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the tracer and meter of this package.
+const instrumentationName = "github.com/shoraid/omnicache/instrumentation/otel"
+
+// Attribute keys recorded on spans and metrics.
+const (
+	attrStore     = attribute.Key("cache.store")
+	attrOperation = attribute.Key("cache.operation")
+	attrHit       = attribute.Key("cache.hit")
+	attrKeyCount  = attribute.Key("cache.key_count")
+	attrKeys      = attribute.Key("cache.keys")
+	attrPattern   = attribute.Key("cache.pattern")
+	attrResult    = attribute.Key("cache.result")
+)
+
+// Values of the cache.result attribute.
+const (
+	resultHit   = "hit"
+	resultMiss  = "miss"
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+type instrumentation struct {
+	tracer    trace.Tracer
+	redactKey func(string) string
+	maxKeys   int
+
+	operations     metric.Int64Counter
+	duration       metric.Float64Histogram
+	loaderDuration metric.Float64Histogram
+}
+
+// NewMiddleware returns middleware recording a span and metrics for every
+// Manager operation. Install it with Manager.Use:
+//
+//	mw, err := otelcache.NewMiddleware(otelcache.OtelConfig{RedactKey: otelcache.HashKey})
+//	manager.Use(mw)
+//
+// Each operation runs in a span named "omnicache.<operation>", started from
+// the caller's context and passed down to the store, so spans recorded by
+// the store's client become its children. Get, GetReader and GetOrSet
+// record whether they hit. GetOrSet records the loader in a child span
+// named "omnicache.load", next to the spans of the Get and Set it performs.
+//
+// The metrics are:
+//   - omnicache.operations: operations by store, operation and result
+//     (hit, miss, ok or error).
+//   - omnicache.operation.duration: store latency in seconds, by store and
+//     operation.
+//   - omnicache.loader.duration: GetOrSet loader latency in seconds, by
+//     store and result.
+func NewMiddleware(config OtelConfig) (omnicache.Middleware, error) {
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+
+	if config.MeterProvider == nil {
+		config.MeterProvider = otel.GetMeterProvider()
+	}
+
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultMaxKeys
+	}
+
+	meter := config.MeterProvider.Meter(instrumentationName)
+
+	operations, err := meter.Int64Counter("omnicache.operations",
+		metric.WithDescription("Number of cache operations."))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram("omnicache.operation.duration",
+		metric.WithDescription("Store latency of cache operations."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	loaderDuration, err := meter.Float64Histogram("omnicache.loader.duration",
+		metric.WithDescription("Latency of GetOrSet loaders."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	i := &instrumentation{
+		tracer:         config.TracerProvider.Tracer(instrumentationName),
+		redactKey:      config.RedactKey,
+		maxKeys:        config.MaxKeys,
+		operations:     operations,
+		duration:       duration,
+		loaderDuration: loaderDuration,
+	}
+
+	return i.middleware, nil
+}
+
+func (i *instrumentation) middleware(next omnicache.Handler) omnicache.Handler {
+	return func(ctx context.Context, call *omnicache.Call) error {
+		ctx, span := i.tracer.Start(ctx, "omnicache."+string(call.Op),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(i.spanAttributes(call)...))
+		defer span.End()
+
+		loaded := false
+		if call.Load != nil {
+			load := call.Load
+			call.Load = func(ctx context.Context) (any, error) {
+				loaded = true
+				return i.load(ctx, call.Alias, load)
+			}
+		}
+
+		err := next(ctx, call)
+
+		result := resultOf(call.Op, err, loaded)
+		if result == resultHit || result == resultMiss {
+			span.SetAttributes(attrHit.Bool(result == resultHit))
+		}
+		if result == resultError {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		store, op := attrStore.String(call.Alias), attrOperation.String(string(call.Op))
+		i.operations.Add(ctx, 1, metric.WithAttributes(store, op, attrResult.String(result)))
+		i.duration.Record(ctx, call.Duration.Seconds(), metric.WithAttributes(store, op))
+
+		return err
+	}
+}
+
+// load runs a GetOrSet loader in a span of its own.
+func (i *instrumentation) load(ctx context.Context, alias string, load func(context.Context) (any, error)) (any, error) {
+	ctx, span := i.tracer.Start(ctx, "omnicache.load", trace.WithAttributes(attrStore.String(alias)))
+	defer span.End()
+
+	start := time.Now()
+	value, err := load(ctx)
+	elapsed := time.Since(start)
+
+	result := resultOK
+	if err != nil {
+		result = resultError
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	i.loaderDuration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrStore.String(alias), attrResult.String(result)))
+
+	return value, err
+}
+
+func (i *instrumentation) spanAttributes(call *omnicache.Call) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attrStore.String(call.Alias),
+		attrOperation.String(string(call.Op)),
+		attrKeyCount.Int(len(call.Keys)),
+	}
+
+	if i.redactKey == nil {
+		return attrs
+	}
+
+	if len(call.Keys) > 0 {
+		keys := call.Keys
+		if len(keys) > i.maxKeys {
+			keys = keys[:i.maxKeys]
+		}
+
+		redacted := make([]string, len(keys))
+		for j, key := range keys {
+			redacted[j] = i.redactKey(key)
+		}
+		attrs = append(attrs, attrKeys.StringSlice(redacted))
+	}
+
+	if call.Pattern != "" {
+		attrs = append(attrs, attrPattern.String(i.redactKey(call.Pattern)))
+	}
+
+	return attrs
+}
+
+// resultOf classifies the outcome of an operation. A miss is not an error.
+func resultOf(op omnicache.Op, err error, loaded bool) string {
+	switch {
+	case (op == omnicache.OpGet || op == omnicache.OpGetReader) && errors.Is(err, omnicache.ErrCacheMiss):
+		return resultMiss
+	case err != nil:
+		return resultError
+	case op == omnicache.OpGet || op == omnicache.OpGetReader:
+		return resultHit
+	case op == omnicache.OpGetOrSet && loaded:
+		return resultMiss
+	case op == omnicache.OpGetOrSet:
+		return resultHit
+	default:
+		return resultOK
+	}
+}
@@ -0,0 +1,305 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type harness struct {
+	manager *omnicache.Manager
+	store   contract.Store
+	spans   *tracetest.SpanRecorder
+	reader  sdkmetric.Reader
+}
+
+func newHarness(t *testing.T, config OtelConfig) *harness {
+	t.Helper()
+
+	store, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+
+	h := &harness{
+		store:  store,
+		spans:  tracetest.NewSpanRecorder(),
+		reader: sdkmetric.NewManualReader(),
+	}
+
+	config.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(h.spans))
+	config.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(h.reader))
+
+	mw, err := NewMiddleware(config)
+	assert.NoError(t, err, "expected no error when creating the middleware")
+
+	h.manager = omnicache.NewManager()
+	assert.NoError(t, h.manager.Register("main", h.store))
+	h.manager.Use(mw)
+
+	return h
+}
+
+// span returns the ended span with the given name.
+func (h *harness) span(t *testing.T, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	for _, span := range h.spans.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	t.Fatalf("expected a span named %q", name)
+	return nil
+}
+
+// metric returns the collected metric with the given name.
+func (h *harness) metric(t *testing.T, name string) metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, h.reader.Collect(context.Background(), &rm), "expected no error when collecting")
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m
+			}
+		}
+	}
+
+	t.Fatalf("expected a metric named %q", name)
+	return metricdata.Metrics{}
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestMiddleware_spans(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		config         OtelConfig
+		setup          func(ctx context.Context, store contract.Store)
+		act            func(ctx context.Context, m *omnicache.Manager)
+		spanName       string
+		expectedAttrs  map[attribute.Key]attribute.Value
+		absentAttrs    []attribute.Key
+		expectedStatus codes.Code
+	}{
+		{
+			name: "should record a hit without keys by default",
+			setup: func(ctx context.Context, store contract.Store) {
+				_ = store.Set(ctx, "user:1", "v", 0)
+			},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_, _ = m.Get(ctx, "user:1")
+			},
+			spanName: "omnicache.get",
+			expectedAttrs: map[attribute.Key]attribute.Value{
+				attrStore:     attribute.StringValue("main"),
+				attrOperation: attribute.StringValue("get"),
+				attrKeyCount:  attribute.IntValue(1),
+				attrHit:       attribute.BoolValue(true),
+			},
+			absentAttrs:    []attribute.Key{attrKeys},
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:  "should record a miss without an error status",
+			setup: func(ctx context.Context, store contract.Store) {},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_, _ = m.Get(ctx, "k")
+			},
+			spanName:       "omnicache.get",
+			expectedAttrs:  map[attribute.Key]attribute.Value{attrHit: attribute.BoolValue(false)},
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:  "should record store errors",
+			setup: func(ctx context.Context, store contract.Store) {},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_ = m.Set(ctx, "k", "v", -time.Minute)
+			},
+			spanName:       "omnicache.set",
+			absentAttrs:    []attribute.Key{attrHit},
+			expectedStatus: codes.Error,
+		},
+		{
+			name:   "should record hashed keys",
+			config: OtelConfig{RedactKey: HashKey},
+			setup:  func(ctx context.Context, store contract.Store) {},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_ = m.Delete(ctx, "user:1")
+			},
+			spanName: "omnicache.delete",
+			expectedAttrs: map[attribute.Key]attribute.Value{
+				attrKeys: attribute.StringSliceValue([]string{HashKey("user:1")}),
+			},
+		},
+		{
+			name:   "should cap the number of recorded keys",
+			config: OtelConfig{RedactKey: PlainKey, MaxKeys: 2},
+			setup:  func(ctx context.Context, store contract.Store) {},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_ = m.DeleteMany(ctx, "a", "b", "c")
+			},
+			spanName: "omnicache.delete_many",
+			expectedAttrs: map[attribute.Key]attribute.Value{
+				attrKeyCount: attribute.IntValue(3),
+				attrKeys:     attribute.StringSliceValue([]string{"a", "b"}),
+			},
+		},
+		{
+			name:   "should record the redacted pattern",
+			config: OtelConfig{RedactKey: PlainKey},
+			setup:  func(ctx context.Context, store contract.Store) {},
+			act: func(ctx context.Context, m *omnicache.Manager) {
+				_ = m.DeleteByPattern(ctx, "user:*")
+			},
+			spanName: "omnicache.delete_by_pattern",
+			expectedAttrs: map[attribute.Key]attribute.Value{
+				attrPattern:  attribute.StringValue("user:*"),
+				attrKeyCount: attribute.IntValue(0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			h := newHarness(t, tt.config)
+			tt.setup(ctx, h.store)
+
+			// --- Act ---
+			tt.act(ctx, h.manager)
+
+			// --- Assert ---
+			span := h.span(t, tt.spanName)
+			attrs := attributes(span)
+			for key, expected := range tt.expectedAttrs {
+				assert.Equal(t, expected, attrs[key], "expected attribute "+string(key))
+			}
+			for _, key := range tt.absentAttrs {
+				_, ok := attrs[key]
+				assert.False(t, ok, "expected no attribute "+string(key))
+			}
+			assert.Equal(t, tt.expectedStatus, span.Status().Code, "expected span status")
+		})
+	}
+}
+
+func TestMiddleware_GetOrSet(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	h := newHarness(t, OtelConfig{})
+
+	loaderErr := errors.New("loader failed")
+
+	// --- Act ---
+	_, err := h.manager.GetOrSet(ctx, "k", time.Minute, func() (any, error) {
+		return nil, loaderErr
+	})
+
+	// --- Assert ---
+	assert.EqualError(t, loaderErr, err, "expected the loader error")
+
+	parent := h.span(t, "omnicache.get_or_set")
+	load := h.span(t, "omnicache.load")
+	get := h.span(t, "omnicache.get")
+
+	assert.Equal(t, parent.SpanContext().SpanID(), load.Parent().SpanID(), "expected the loader span to be a child of GetOrSet")
+	assert.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID(), "expected the nested Get span to be a child of GetOrSet")
+	assert.Equal(t, codes.Error, load.Status().Code, "expected the loader error on the loader span")
+	assert.Equal(t, codes.Error, parent.Status().Code, "expected the loader error on the GetOrSet span")
+
+	loaderDuration := h.metric(t, "omnicache.loader.duration").Data.(metricdata.Histogram[float64])
+	assert.Equal(t, 1, len(loaderDuration.DataPoints), "expected one loader data point")
+	assert.Equal(t, uint64(1), loaderDuration.DataPoints[0].Count, "expected one loader observation")
+}
+
+func TestMiddleware_GetOrSet_hit(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	h := newHarness(t, OtelConfig{})
+	assert.NoError(t, h.store.Set(ctx, "k", "cached", 0))
+
+	// --- Act ---
+	value, err := h.manager.GetOrSet(ctx, "k", time.Minute, func() (any, error) {
+		return "loaded", nil
+	})
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, "cached", value, "expected the cached value")
+	assert.Equal(t, attribute.BoolValue(true), attributes(h.span(t, "omnicache.get_or_set"))[attrHit], "expected a hit")
+	for _, span := range h.spans.Ended() {
+		assert.True(t, span.Name() != "omnicache.load", "expected no loader span on a hit")
+	}
+}
+
+func TestMiddleware_metrics(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	h := newHarness(t, OtelConfig{})
+	assert.NoError(t, h.store.Set(ctx, "hit", "v", 0))
+
+	// --- Act ---
+	_, _ = h.manager.Get(ctx, "hit")
+	_, _ = h.manager.Get(ctx, "hit")
+	_, _ = h.manager.Get(ctx, "miss")
+
+	// --- Assert ---
+	operations := h.metric(t, "omnicache.operations").Data.(metricdata.Sum[int64])
+	counts := make(map[string]int64)
+	for _, dp := range operations.DataPoints {
+		result, _ := dp.Attributes.Value(attrResult)
+		counts[result.AsString()] = dp.Value
+	}
+	assert.Equal(t, map[string]int64{resultHit: 2, resultMiss: 1}, counts, "expected operations by result")
+
+	duration := h.metric(t, "omnicache.operation.duration").Data.(metricdata.Histogram[float64])
+	assert.Equal(t, 1, len(duration.DataPoints), "expected one data point for get")
+	assert.Equal(t, uint64(3), duration.DataPoints[0].Count, "expected every get to be observed")
+}
+
+func TestHashKey(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	digest := HashKey("user:1")
+
+	// --- Assert ---
+	assert.Equal(t, 16, len(digest), "expected 8 hex-encoded bytes")
+	assert.Equal(t, digest, HashKey("user:1"), "expected a stable digest")
+	assert.True(t, digest != HashKey("user:2"), "expected different keys to differ")
+}
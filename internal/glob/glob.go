@@ -0,0 +1,77 @@
+package glob
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrUnterminatedClass = errors.New("glob: unterminated character class")
+
+// Compile converts a Redis-style glob pattern into an anchored regular expression.
+//
+// Pattern rules:
+//   - '*' matches zero or more characters (including '/' and '\n').
+//   - '?' matches exactly one character (including '\n').
+//   - '[abc]', '[a-z]' and '[^abc]' (or '[!abc]') match character classes.
+//   - '\' escapes the following character.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	// (?s) lets '*' and '?' match newlines, as they do in Redis
+	sb.WriteString("(?s)^")
+
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, ErrUnterminatedClass
+			}
+
+			class := string(runes[i+1 : end])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// LiteralPrefix returns the part of pattern before its first wildcard
+// and whether the pattern is exactly that prefix followed by a single
+// trailing '*' (e.g. "user:*"). Such patterns can be served by an
+// ordered prefix scan instead of matching every key.
+func LiteralPrefix(pattern string) (string, bool) {
+	idx := strings.IndexAny(pattern, `*?[\`)
+	if idx < 0 {
+		return pattern, false
+	}
+
+	return pattern[:idx], idx == len(pattern)-1 && pattern[idx] == '*'
+}
@@ -0,0 +1,144 @@
+package glob
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestGlob_Compile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		pattern   string
+		match     []string
+		noMatch   []string
+		expectErr bool
+	}{
+		{
+			name:    "should treat '*' as any sequence",
+			pattern: "user:*:profile",
+			match:   []string{"user:1:profile", "user::profile", "user:a/b:profile"},
+			noMatch: []string{"user:1:settings"},
+		},
+		{
+			name:    "should treat '?' as a single character",
+			pattern: "id-?",
+			match:   []string{"id-1", "id-x"},
+			noMatch: []string{"id-", "id-12"},
+		},
+		{
+			name:    "should match newlines with '*' and '?'",
+			pattern: "line*end?",
+			match:   []string{"line\n\nend\n", "line1\nend2"},
+			noMatch: []string{"line\nend"},
+		},
+		{
+			name:    "should support negated character classes",
+			pattern: "v[!0-4]",
+			match:   []string{"v5", "v9"},
+			noMatch: []string{"v0", "v4"},
+		},
+		{
+			name:    "should escape regex metacharacters",
+			pattern: "a.b+c",
+			match:   []string{"a.b+c"},
+			noMatch: []string{"aXbbc"},
+		},
+		{
+			name:    "should honor backslash escapes",
+			pattern: `literal\*`,
+			match:   []string{"literal*"},
+			noMatch: []string{"literalX"},
+		},
+		{
+			name:      "should return error for unterminated class",
+			pattern:   "a[bc",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			re, err := Compile(tt.pattern)
+
+			// --- Assert ---
+			if tt.expectErr {
+				assert.EqualError(t, ErrUnterminatedClass, err, "expected error for malformed pattern")
+				return
+			}
+
+			assert.NoError(t, err)
+			for _, s := range tt.match {
+				assert.True(t, re.MatchString(s), fmt.Sprintf("expected %q to match %q", tt.pattern, s))
+			}
+			for _, s := range tt.noMatch {
+				assert.False(t, re.MatchString(s), fmt.Sprintf("expected %q not to match %q", tt.pattern, s))
+			}
+		})
+	}
+}
+
+func TestGlob_LiteralPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		pattern        string
+		expectedPrefix string
+		expectedOK     bool
+	}{
+		{
+			name:           "should detect a single trailing wildcard",
+			pattern:        "user:*",
+			expectedPrefix: "user:",
+			expectedOK:     true,
+		},
+		{
+			name:           "should report empty prefix for '*'",
+			pattern:        "*",
+			expectedPrefix: "",
+			expectedOK:     true,
+		},
+		{
+			name:           "should reject wildcards in the middle",
+			pattern:        "user:*:profile",
+			expectedPrefix: "user:",
+			expectedOK:     false,
+		},
+		{
+			name:           "should reject other meta characters",
+			pattern:        "user:?*",
+			expectedPrefix: "user:",
+			expectedOK:     false,
+		},
+		{
+			name:           "should reject patterns without wildcard",
+			pattern:        "user:1",
+			expectedPrefix: "user:1",
+			expectedOK:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			prefix, ok := LiteralPrefix(tt.pattern)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedPrefix, prefix, "prefix must match")
+			assert.Equal(t, tt.expectedOK, ok, "prefix-only flag must match")
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Package payload recovers the bytes a wrapper store wrote from the value
+// its inner store returns on Get.
+package payload
+
+import (
+	"github.com/shoraid/omnicache/codec"
+)
+
+// Extract returns the payload behind v if it starts with magic.
+//
+// Stores return stored []byte values as []byte or codec.Raw; strings are
+// accepted as well. It reports false for values not written by the
+// wrapper.
+func Extract(v any, magic byte) ([]byte, bool) {
+	var data []byte
+
+	switch val := v.(type) {
+	case []byte:
+		data = val
+	case codec.Raw:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return nil, false
+	}
+
+	if len(data) == 0 || data[0] != magic {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// rawMarker starts a body holding a plain []byte. Codec IDs are never 0, so
+// it cannot be mistaken for the header of a codec-encoded body.
+const rawMarker byte = 0x00
+
+// Marshal serializes value into a wrapper body.
+//
+// []byte values are kept as-is behind rawMarker, so they read back as
+// []byte and wrappers can be stacked; codec.Raw values are already encoded
+// and are kept as-is. Everything else is encoded with c.
+func Marshal(c codec.Codec, value any) ([]byte, error) {
+	switch val := value.(type) {
+	case []byte:
+		out := make([]byte, 0, len(val)+1)
+		out = append(out, rawMarker)
+		return append(out, val...), nil
+	case codec.Raw:
+		return append([]byte{}, val...), nil
+	}
+
+	return codec.Encode(c, value)
+}
+
+// Unmarshal reverses Marshal. It returns []byte for plain byte bodies and
+// codec.Raw for codec-encoded ones.
+func Unmarshal(body []byte) any {
+	if len(body) > 0 && body[0] == rawMarker {
+		return body[1:]
+	}
+
+	return codec.Raw(body)
+}
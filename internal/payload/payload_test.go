@@ -0,0 +1,75 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestPayload_Extract(t *testing.T) {
+	t.Parallel()
+
+	const magic = 0xc7
+	data := []byte{magic, 0x00, 'h', 'i'}
+
+	tests := []struct {
+		name       string
+		value      any
+		expected   []byte
+		expectedOk bool
+	}{
+		{name: "should extract []byte", value: data, expected: data, expectedOk: true},
+		{name: "should extract codec.Raw", value: codec.Raw(data), expected: data, expectedOk: true},
+		{name: "should extract raw string", value: string(data), expected: data, expectedOk: true},
+		{name: "should reject bytes without magic", value: []byte("plain"), expectedOk: false},
+		{name: "should reject empty bytes", value: []byte{}, expectedOk: false},
+		{name: "should reject other types", value: 42, expectedOk: false},
+		{name: "should reject nil", value: nil, expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result, ok := Extract(tt.value, magic)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedOk, ok, "ok must match")
+			if tt.expectedOk {
+				assert.Equal(t, tt.expected, result, "payload must match")
+			}
+		})
+	}
+}
+
+func TestPayload_MarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{name: "should round-trip []byte as []byte", value: []byte{0x00, 0xff}, expected: []byte{0x00, 0xff}},
+		{name: "should round-trip empty []byte", value: []byte{}, expected: []byte{}},
+		{name: "should keep codec.Raw as-is", value: codec.Raw("\x01\"hi\""), expected: codec.Raw("\x01\"hi\"")},
+		{name: "should encode other values with the codec", value: map[string]int{"a": 1}, expected: codec.Raw("\x01{\"a\":1}")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			body, err := Marshal(codec.Sonic, tt.value)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when marshaling")
+			assert.Equal(t, tt.expected, Unmarshal(body), "value must round-trip")
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package storevalue serializes values for stores that keep them as plain
+// bytes (file, bolt, sql, memcached), recording enough of their type for
+// Get to return strings as strings and []byte values as []byte.
+//
+// A stored value starts with a marker byte:
+//   - markerBytes: a []byte value, stored as-is;
+//   - markerString: a string value, stored as-is;
+//   - a codec ID: any other value, encoded with codec.Encode.
+package storevalue
+
+import (
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+)
+
+const (
+	// markerBytes starts a []byte value. Codec IDs are never 0.
+	markerBytes byte = 0x00
+
+	// markerString starts a string value. It is outside the ranges of
+	// codec IDs.
+	markerString byte = 0x1f
+)
+
+// Encode serializes value. []byte and string values are stored as-is
+// behind their marker; codec.Raw values are already encoded and are kept
+// as-is. Everything else is encoded with c, or codec.Default if c is nil.
+func Encode(c codec.Codec, value any) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return withMarker(markerBytes, v), nil
+	case string:
+		return withMarker(markerString, []byte(v)), nil
+	case codec.Raw:
+		return append([]byte{}, v...), nil
+	}
+
+	if c == nil {
+		c = codec.Default
+	}
+
+	return codec.Encode(c, value)
+}
+
+// Decode reverses Encode. It returns []byte and string values as such and
+// other values as codec.Raw.
+func Decode(data []byte) any {
+	if len(data) > 0 {
+		switch data[0] {
+		case markerBytes:
+			return append([]byte{}, data[1:]...)
+		case markerString:
+			return string(data[1:])
+		}
+	}
+
+	return codec.Raw(append([]byte{}, data...))
+}
+
+// Bytes returns the bytes of the []byte or string value in data, and
+// ErrTypeMismatch for other values.
+func Bytes(data []byte) ([]byte, error) {
+	if len(data) > 0 && HoldsBytes(data[0]) {
+		return data[1:], nil
+	}
+
+	return nil, omnicache.ErrTypeMismatch
+}
+
+// HoldsBytes reports whether a value whose first byte is marker is a
+// []byte or string value, whose bytes follow the marker.
+func HoldsBytes(marker byte) bool {
+	return marker == markerBytes || marker == markerString
+}
+
+func withMarker(marker byte, data []byte) []byte {
+	out := make([]byte, 0, len(data)+1)
+	out = append(out, marker)
+
+	return append(out, data...)
+}
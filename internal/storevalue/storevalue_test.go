@@ -0,0 +1,113 @@
+package storevalue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+func TestStoreValue_EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	cbor, err := codec.Encode(codec.CBOR, map[string]int{"id": 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		codec    codec.Codec
+		value    any
+		expected any
+	}{
+		{name: "should round-trip a string", value: "dGVzdA==", expected: "dGVzdA=="},
+		{name: "should round-trip an empty string", value: "", expected: ""},
+		{name: "should round-trip []byte", value: []byte{0x00, 0xff}, expected: []byte{0x00, 0xff}},
+		{name: "should encode other values with the default codec", value: 42, expected: codec.Raw(append([]byte{codec.IDSonic}, "42"...))},
+		{name: "should encode other values with the codec", codec: codec.CBOR, value: map[string]int{"id": 1}, expected: codec.Raw(cbor)},
+		{name: "should keep codec.Raw as-is", value: codec.Raw(cbor), expected: codec.Raw(cbor)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			data, err := Encode(tt.codec, tt.value)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when encoding")
+			assert.Equal(t, tt.expected, Decode(data), "decoded value must match")
+		})
+	}
+}
+
+func TestStoreValue_Decode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected any
+	}{
+		{name: "should return data without a marker as codec.Raw", data: []byte{0x7f, 'x'}, expected: codec.Raw{0x7f, 'x'}},
+		{name: "should return empty data as empty codec.Raw", data: []byte{}, expected: codec.Raw{}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result := Decode(tt.data)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expected, result, "decoded value must match")
+		})
+	}
+}
+
+func TestStoreValue_Bytes(t *testing.T) {
+	t.Parallel()
+
+	str, err := Encode(nil, "test")
+	assert.NoError(t, err)
+	raw, err := Encode(nil, []byte("dGVzdA=="))
+	assert.NoError(t, err)
+	other, err := Encode(nil, 42)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		data        []byte
+		expected    []byte
+		expectedErr error
+	}{
+		{name: "should return strings that look like base64 as is", data: str, expected: []byte("test")},
+		{name: "should return []byte values as is", data: raw, expected: []byte("dGVzdA==")},
+		{name: "should return ErrTypeMismatch for other values", data: other, expectedErr: omnicache.ErrTypeMismatch},
+		{name: "should return ErrTypeMismatch for data without a marker", data: []byte(`"user"`), expectedErr: omnicache.ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result, err := Bytes(tt.data)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected ErrTypeMismatch")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, result, "bytes must match the stored value")
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package stream streams values to and from stores for wrapper stores,
+// which implement contract.Streamer whether or not the store they wrap does.
+package stream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+)
+
+// healthProbeKey is the key Ping calls Has with; Manager.Health probes
+// stores with the same key.
+const healthProbeKey = "omnicache:health"
+
+// Ping pings store if it implements contract.Pinger, and probes it with
+// Has otherwise, as Manager.Health does.
+func Ping(ctx context.Context, store contract.Store) error {
+	if pinger, ok := store.(contract.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	_, err := store.Has(ctx, healthProbeKey)
+	return err
+}
+
+// GetReader returns a reader over the []byte or string value of key. The
+// value is streamed if store implements contract.Streamer, and read with
+// Get otherwise.
+func GetReader(ctx context.Context, store contract.Store, key string) (io.ReadCloser, error) {
+	if streamer, ok := store.(contract.Streamer); ok {
+		return streamer.GetReader(ctx, key)
+	}
+
+	value, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch val := value.(type) {
+	case []byte:
+		return io.NopCloser(bytes.NewReader(val)), nil
+	case string:
+		return io.NopCloser(strings.NewReader(val)), nil
+	default:
+		return nil, omnicache.ErrTypeMismatch
+	}
+}
+
+// SetFromReader stores everything read from r as a []byte value under key.
+// The value is streamed if store implements contract.Streamer, and read
+// into memory and stored with Set otherwise.
+func SetFromReader(ctx context.Context, store contract.Store, key string, r io.Reader, ttl time.Duration) error {
+	if streamer, ok := store.(contract.Streamer); ok {
+		return streamer.SetFromReader(ctx, key, r, ttl)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return store.Set(ctx, key, data, ttl)
+}
+
+// Size returns the size reported by store, or -1 entries and bytes if it
+// does not implement contract.Sizer, as in omnicache.Stats.
+func Size(store contract.Store) (entries int64, bytes int64) {
+	if sizer, ok := store.(contract.Sizer); ok {
+		return sizer.Size()
+	}
+
+	return -1, -1
+}
+
+// Evictions returns the evictions reported by store, or 0 if it does not
+// implement contract.EvictionCounter.
+func Evictions(store contract.Store) uint64 {
+	if counter, ok := store.(contract.EvictionCounter); ok {
+		return counter.Evictions()
+	}
+
+	return 0
+}
@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// plainStore hides every optional interface of the store it embeds.
+type plainStore struct {
+	contract.Store
+}
+
+func newMemoryStore(t *testing.T) contract.Store {
+	t.Helper()
+
+	store, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+
+	return store
+}
+
+func TestStream_GetReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		plain       bool
+		value       any
+		expected    string
+		expectedErr error
+	}{
+		{name: "should stream the value from a Streamer", value: []byte("bytes"), expected: "bytes"},
+		{name: "should read []byte with Get from other stores", plain: true, value: []byte("bytes"), expected: "bytes"},
+		{name: "should read a string with Get from other stores", plain: true, value: "text", expected: "text"},
+		{name: "should return ErrTypeMismatch for other values", plain: true, value: 42, expectedErr: omnicache.ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newMemoryStore(t)
+			assert.NoError(t, store.Set(ctx, "k", tt.value, 0))
+			if tt.plain {
+				store = plainStore{store}
+			}
+
+			// --- Act ---
+			reader, err := GetReader(ctx, store, "k")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected error to match")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when opening the reader")
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "expected no error when reading the value")
+			assert.Equal(t, tt.expected, string(data), "value must match")
+		})
+	}
+}
+
+func TestStream_SetFromReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		plain bool
+	}{
+		{name: "should stream the value into a Streamer"},
+		{name: "should set the value as []byte in other stores", plain: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := newMemoryStore(t)
+			if tt.plain {
+				store = plainStore{store}
+			}
+
+			// --- Act ---
+			err := SetFromReader(ctx, store, "k", strings.NewReader("streamed"), 0)
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when setting the value")
+			value, err := store.Get(ctx, "k")
+			assert.NoError(t, err, "expected no error when getting the value")
+			assert.Equal(t, []byte("streamed"), value, "value must match")
+		})
+	}
+}
+
+func TestStream_SizeAndEvictions(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := newMemoryStore(t)
+	assert.NoError(t, store.Set(ctx, "k", "v", 0))
+
+	// --- Act ---
+	entries, _ := Size(store)
+	plainEntries, plainBytes := Size(plainStore{store})
+
+	// --- Assert ---
+	assert.Equal(t, int64(1), entries, "expected the size of a Sizer")
+	assert.Equal(t, int64(-1), plainEntries, "expected -1 entries for other stores")
+	assert.Equal(t, int64(-1), plainBytes, "expected -1 bytes for other stores")
+	assert.Equal(t, uint64(0), Evictions(store), "expected 0 evictions for stores that do not count them")
+}
+
+// pingStore fails Ping with errPing and records the keys Has is called with.
+type pingStore struct {
+	contract.Store
+	hasKeys []string
+}
+
+var errPing = errors.New("ping failed")
+
+func (s *pingStore) Ping(ctx context.Context) error {
+	return errPing
+}
+
+func (s *pingStore) Has(ctx context.Context, key string) (bool, error) {
+	s.hasKeys = append(s.hasKeys, key)
+	return false, nil
+}
+
+func TestStream_Ping(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should ping a Pinger", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := &pingStore{}
+
+		// --- Act ---
+		err := Ping(context.Background(), store)
+
+		// --- Assert ---
+		assert.True(t, errors.Is(err, errPing), "expected the error of Ping")
+		assert.Empty(t, store.hasKeys, "expected Has not to be called")
+	})
+
+	t.Run("should probe other stores with Has", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := &pingStore{}
+
+		// --- Act ---
+		err := Ping(context.Background(), plainStore{store})
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when the probe succeeds")
+		assert.Equal(t, []string{healthProbeKey}, store.hasKeys, "expected Has to be called with the probe key")
+	})
+}
@@ -0,0 +1,321 @@
+package testutil
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeMemcached is an in-process memcached server speaking the subset of
+// the meta protocol (mg, ms, md, mn) plus flush_all that the memcached
+// driver uses. It is intended for tests only.
+type FakeMemcached struct {
+	t        *testing.T
+	listener net.Listener
+	mu       sync.Mutex
+	items    map[string]fakeItem
+	commands []string
+	wg       sync.WaitGroup
+}
+
+type fakeItem struct {
+	value      []byte
+	expiration time.Time
+}
+
+// NewFakeMemcached starts a fake memcached server on a random local port.
+// The server is shut down automatically when the test finishes.
+func NewFakeMemcached(t *testing.T) *FakeMemcached {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("fake memcached: listen: %v", err)
+	}
+
+	s := &FakeMemcached{
+		t:        t,
+		listener: listener,
+		items:    make(map[string]fakeItem),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Addr returns the host:port the server listens on.
+func (s *FakeMemcached) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops accepting connections and waits for handlers to exit.
+func (s *FakeMemcached) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Len returns the number of live (non-expired) items.
+func (s *FakeMemcached) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, item := range s.items {
+		if !item.expired() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Has reports whether a live item exists for key.
+func (s *FakeMemcached) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	return ok && !item.expired()
+}
+
+// Expiration returns the expiration time stored for key.
+func (s *FakeMemcached) Expiration(key string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.items[key].expiration
+}
+
+// Commands returns the names of all commands received so far, in order.
+func (s *FakeMemcached) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+func (s *FakeMemcached) serve() {
+	defer s.wg.Done()
+
+	var conns sync.WaitGroup
+	defer conns.Wait()
+
+	var mu sync.Mutex
+	open := map[net.Conn]struct{}{}
+	defer func() {
+		mu.Lock()
+		for c := range open {
+			c.Close()
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		open[conn] = struct{}{}
+		mu.Unlock()
+
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			s.handle(conn)
+
+			mu.Lock()
+			delete(open, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+func (s *FakeMemcached) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(strings.TrimRight(line, "\r\n"))
+		if len(fields) == 0 {
+			continue
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, fields[0])
+		s.mu.Unlock()
+
+		var resp string
+		switch fields[0] {
+		case "mg":
+			resp = s.metaGet(fields)
+		case "ms":
+			resp, err = s.metaSet(fields, r)
+			if err != nil {
+				return
+			}
+		case "md":
+			resp = s.metaDelete(fields)
+		case "mn":
+			resp = "MN\r\n"
+		case "flush_all":
+			s.mu.Lock()
+			s.items = make(map[string]fakeItem)
+			s.mu.Unlock()
+			resp = "OK\r\n"
+		default:
+			resp = "ERROR\r\n"
+		}
+
+		if _, err := w.WriteString(resp); err != nil {
+			return
+		}
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *FakeMemcached) metaGet(fields []string) string {
+	key, flags, err := parseMetaKey(fields)
+	if err != nil {
+		return "CLIENT_ERROR bad key\r\n"
+	}
+
+	s.mu.Lock()
+	item, ok := s.items[key]
+	if ok && item.expired() {
+		delete(s.items, key)
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return "EN\r\n"
+	}
+
+	if hasFlag(flags, "v") {
+		return fmt.Sprintf("VA %d\r\n%s\r\n", len(item.value), item.value)
+	}
+
+	return "HD\r\n"
+}
+
+func (s *FakeMemcached) metaSet(fields []string, r *bufio.Reader) (string, error) {
+	if len(fields) < 3 {
+		return "CLIENT_ERROR bad command line format\r\n", nil
+	}
+
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "CLIENT_ERROR bad data chunk\r\n", nil
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", err
+	}
+
+	key, flags, err := parseMetaKey(append(fields[:2:2], fields[3:]...))
+	if err != nil {
+		return "CLIENT_ERROR bad key\r\n", nil
+	}
+
+	item := fakeItem{value: data[:size]}
+	for _, f := range flags {
+		if strings.HasPrefix(f, "T") {
+			ttl, _ := strconv.ParseInt(f[1:], 10, 64)
+			switch {
+			case ttl > 30*24*60*60:
+				item.expiration = time.Unix(ttl, 0)
+			case ttl > 0:
+				item.expiration = time.Now().Add(time.Duration(ttl) * time.Second)
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.items[key] = item
+	s.mu.Unlock()
+
+	if hasFlag(flags, "q") {
+		return "", nil
+	}
+
+	return "HD\r\n", nil
+}
+
+func (s *FakeMemcached) metaDelete(fields []string) string {
+	key, flags, err := parseMetaKey(fields)
+	if err != nil {
+		return "CLIENT_ERROR bad key\r\n"
+	}
+
+	s.mu.Lock()
+	_, ok := s.items[key]
+	delete(s.items, key)
+	s.mu.Unlock()
+
+	if hasFlag(flags, "q") {
+		return ""
+	}
+	if !ok {
+		return "NF\r\n"
+	}
+
+	return "HD\r\n"
+}
+
+// parseMetaKey extracts the key (decoding it when the 'b' flag is set)
+// and the remaining flags from a meta command.
+func parseMetaKey(fields []string) (string, []string, error) {
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("missing key")
+	}
+
+	key, flags := fields[1], fields[2:]
+	if hasFlag(flags, "b") {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return "", nil, err
+		}
+		key = string(decoded)
+	}
+
+	return key, flags, nil
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i fakeItem) expired() bool {
+	return !i.expiration.IsZero() && time.Now().After(i.expiration)
+}
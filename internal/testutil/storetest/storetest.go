@@ -0,0 +1,109 @@
+// Package storetest provides stores and helpers shared by the tests of
+// the store wrappers.
+package storetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+)
+
+// NewMemoryStore returns a memory store that is closed when the test finishes.
+func NewMemoryStore(t *testing.T) contract.Store {
+	t.Helper()
+
+	store, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close(context.Background()) })
+
+	return store
+}
+
+// Decode decodes a codec.Raw read back from a store into a T.
+func Decode[T any](t *testing.T, value any) T {
+	t.Helper()
+
+	raw, ok := value.(codec.Raw)
+	assert.True(t, ok, "expected codec.Raw")
+
+	var out T
+	assert.NoError(t, codec.Decode(raw, nil, &out))
+
+	return out
+}
+
+// FlakyStore fails Get and Set with the queued Errs, one per call, and
+// then with Err, counting the calls that reached it. Get returns "value"
+// once both are used up. Other operations reach the embedded Store.
+type FlakyStore struct {
+	contract.Store
+
+	// Errs and Err must not be changed once the store is in use; use
+	// Fail instead.
+	Errs []error
+	Err  error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// Fail makes the following calls fail with err, or succeed if err is nil.
+func (s *FlakyStore) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Errs, s.Err = nil, err
+}
+
+// Calls returns the number of Get and Set calls that reached the store.
+func (s *FlakyStore) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+func (s *FlakyStore) Get(ctx context.Context, key string) (any, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+
+	return "value", nil
+}
+
+func (s *FlakyStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return s.next()
+}
+
+func (s *FlakyStore) Close(ctx context.Context) error {
+	return nil
+}
+
+func (s *FlakyStore) next() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if len(s.Errs) == 0 {
+		return s.Err
+	}
+
+	err := s.Errs[0]
+	s.Errs = s.Errs[1:]
+	return err
+}
+
+// EvictingStore reports a fixed eviction count of 7.
+type EvictingStore struct {
+	contract.Store
+}
+
+func (s *EvictingStore) Evictions() uint64 {
+	return 7
+}
@@ -1,42 +1,98 @@
 package omnicache
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/shoraid/omnicache/codec"
 	"github.com/shoraid/omnicache/contract"
 )
 
 type Manager struct {
-	mu     sync.RWMutex
-	stores map[string]contract.Store
-	store  contract.Store
+	mu         sync.RWMutex
+	stores     map[string]contract.Store
+	store      contract.Store
+	alias      string
+	codec      codec.Codec
+	middleware []Middleware
+
+	// root is the Manager a view was derived from with Store. It owns the
+	// registered stores; nil for Managers created with NewManager.
+	root *Manager
+
+	logger        *slog.Logger
+	slowThreshold time.Duration
+	unknownAlias  UnknownAliasMode
+	healthTimeout time.Duration
+	failOpen      bool
+
+	// fallbacks maps aliases to the alias of their fallback store.
+	fallbacks map[string]string
+
+	statsByAlias map[string]*storeStats
+
+	// routes is a snapshot of the registered stores, from which operations
+	// resolve their store without locking. It is published again on every
+	// change to the registry.
+	routes atomic.Pointer[map[string]route]
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		stores: make(map[string]contract.Store),
+// route is how operations on an alias reach its store.
+type route struct {
+	// store is the registered store, wrapped to fail over if the alias
+	// has a fallback.
+	store contract.Store
+	stats *storeStats
+}
+
+func NewManager(opts ...Option) *Manager {
+	m := &Manager{
+		stores:        make(map[string]contract.Store),
+		codec:         codec.Default,
+		logger:        slog.Default(),
+		healthTimeout: DefaultHealthTimeout,
+		statsByAlias:  make(map[string]*storeStats),
+	}
+
+	for _, opt := range opts {
+		opt(m)
 	}
+
+	return m
 }
 
 // Register adds a new store with the given alias.
 // The first registered store becomes the default.
 // Returns an error if the alias is already registered.
 func (m *Manager) Register(alias string, store contract.Store) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	reg := m.registry()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
 
-	// First store becomes default
-	if len(m.stores) == 0 {
-		m.store = store
+	if _, exists := reg.stores[alias]; exists {
+		return ErrStoreAlreadyRegistered
 	}
 
-	if _, exists := m.stores[alias]; exists {
-		return ErrStoreAlreadyRegistered
+	if reg.stores == nil {
+		reg.stores = make(map[string]contract.Store)
+	}
+	if reg.statsByAlias == nil {
+		reg.statsByAlias = make(map[string]*storeStats)
+	}
+	reg.statsByAlias[alias] = newStoreStats(store)
+
+	// First store becomes default
+	if reg.store == nil {
+		reg.store = store
+		reg.alias = alias
 	}
 
-	m.stores[alias] = store
+	reg.stores[alias] = store
+	reg.publishRoutes()
 
 	return nil
 }
@@ -44,35 +100,198 @@ func (m *Manager) Register(alias string, store contract.Store) error {
 // SetDefault sets the store with the given alias as the default store.
 // Returns an error if the alias has not been registered.
 func (m *Manager) SetDefault(alias string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	reg := m.registry()
+	reg.mu.RLock()
+	store, exists := reg.stores[alias]
+	reg.mu.RUnlock()
 
-	store, exists := m.stores[alias]
 	if !exists {
 		return ErrInvalidDefaultStore
 	}
 
+	m.mu.Lock()
 	m.store = store
+	m.alias = alias
+	m.mu.Unlock()
 
 	return nil
 }
 
 // Store switches the active cache store to the one registered under
 // the given alias. It returns a new Manager instance bound to that
-// store. If the alias does not exist, the implementation may return
-// a no-op manager or panic, depending on configuration.
+// store. If the alias does not exist, Store behaves according to the
+// WithUnknownAlias option: by default it logs a warning and returns m,
+// bound to the default store. Use StoreE to handle unknown aliases.
 func (m *Manager) Store(alias string) *Manager {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	view, err := m.StoreE(alias)
+	if err == nil {
+		return view
+	}
 
-	store, exists := m.stores[alias]
-	if !exists {
-		fmt.Fprintf(os.Stderr, "[omnicache] warning: store alias %q not found, using default store\n", alias)
+	switch m.unknownAlias {
+	case UnknownAliasPanic:
+		panic(err)
+	case UnknownAliasError:
+		return m.view(alias, invalidStore{err: err})
+	default:
+		m.log().Warn("omnicache: store alias not found, using default store", slog.String("alias", alias))
 		return m
 	}
+}
+
+// StoreE is like Store, but returns ErrInvalidStore if the alias has not
+// been registered.
+func (m *Manager) StoreE(alias string) (*Manager, error) {
+	reg := m.registry()
+	reg.mu.RLock()
+	store, exists := reg.stores[alias]
+	reg.mu.RUnlock()
+
+	if !exists {
+		return nil, unregisteredAlias(alias)
+	}
+
+	return m.view(alias, store), nil
+}
+
+// MustStore is like StoreE, but panics if the alias has not been
+// registered. It is meant for initialization code.
+func (m *Manager) MustStore(alias string) *Manager {
+	view, err := m.StoreE(alias)
+	if err != nil {
+		panic(err)
+	}
+
+	return view
+}
+
+// view returns a Manager bound to alias, sharing the configuration of m.
+// store is the store currently registered under alias.
+func (m *Manager) view(alias string, store contract.Store) *Manager {
+	reg := m.registry()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
 	return &Manager{
-		stores: m.stores,
-		store:  store,
+		stores:        reg.stores,
+		store:         store,
+		alias:         alias,
+		codec:         m.codec,
+		middleware:    m.middleware,
+		root:          reg,
+		logger:        m.logger,
+		slowThreshold: m.slowThreshold,
+		unknownAlias:  m.unknownAlias,
+		healthTimeout: m.healthTimeout,
+		failOpen:      m.failOpen,
+	}
+}
+
+// registry returns the Manager owning the registered stores.
+func (m *Manager) registry() *Manager {
+	if m.root != nil {
+		return m.root
+	}
+
+	return m
+}
+
+// current returns the store operations run against: the store registered
+// under the alias of m, so that views follow Replace, wrapped to fail over
+// if the alias has a fallback. A Manager without alias, e.g. one whose
+// default store was unregistered, uses its own store. If neither exists,
+// the returned store fails every operation with ErrInvalidStore.
+func (m *Manager) current() contract.Store {
+	m.mu.RLock()
+	alias, store := m.alias, m.store
+	m.mu.RUnlock()
+
+	store, _ = m.resolve(alias, store)
+
+	return store
+}
+
+// resolve returns the store operations on alias run against, as described
+// by current, and the counters of alias. store is the store of m.
+func (m *Manager) resolve(alias string, store contract.Store) (contract.Store, *storeStats) {
+	r, exists := m.route(alias)
+
+	switch {
+	case exists:
+		return r.store, r.stats
+	case alias == "" && store != nil:
+		return store, nil
+	case alias == "":
+		return invalidStore{err: fmt.Errorf("%w: no default store", ErrInvalidStore)}, nil
+	default:
+		return invalidStore{err: unregisteredAlias(alias)}, nil
+	}
+}
+
+// statsFor returns the counters of the store registered under alias.
+func (m *Manager) statsFor(alias string) *storeStats {
+	r, _ := m.route(alias)
+
+	return r.stats
+}
+
+// route returns the route of alias from the published snapshot.
+func (m *Manager) route(alias string) (route, bool) {
+	routes := m.registry().routes.Load()
+	if routes == nil {
+		return route{}, false
+	}
+
+	r, exists := (*routes)[alias]
+
+	return r, exists
+}
+
+// publishRoutes publishes a snapshot of the registered stores. The
+// registry lock must be held for writing.
+func (m *Manager) publishRoutes() {
+	routes := make(map[string]route, len(m.stores))
+	for alias, store := range m.stores {
+		r := route{store: store, stats: m.statsByAlias[alias]}
+		if name, ok := m.fallbacks[alias]; ok {
+			if fallback, ok := m.stores[name]; ok {
+				r.store = failoverStore{primary: store, fallback: fallback, stats: r.stats}
+			}
+		}
+		routes[alias] = r
 	}
+
+	m.routes.Store(&routes)
+}
+
+func unregisteredAlias(alias string) error {
+	return fmt.Errorf("%w: alias %q is not registered", ErrInvalidStore, alias)
+}
+
+// log returns the logger of m, falling back to slog.Default().
+func (m *Manager) log() *slog.Logger {
+	if m.logger == nil {
+		return slog.Default()
+	}
+
+	return m.logger
+}
+
+// invalidStore is the store of views returned by Store for unknown aliases
+// in UnknownAliasError mode. Every operation fails with err.
+type invalidStore struct {
+	err error
+}
+
+func (s invalidStore) Clear(ctx context.Context) error                           { return s.err }
+func (s invalidStore) Close(ctx context.Context) error                           { return nil }
+func (s invalidStore) Delete(ctx context.Context, key string) error              { return s.err }
+func (s invalidStore) DeleteByPattern(ctx context.Context, pattern string) error { return s.err }
+func (s invalidStore) DeleteMany(ctx context.Context, keys ...string) error      { return s.err }
+func (s invalidStore) Get(ctx context.Context, key string) (any, error)          { return nil, s.err }
+func (s invalidStore) Has(ctx context.Context, key string) (bool, error)         { return false, s.err }
+
+func (s invalidStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return s.err
 }
@@ -9,29 +9,62 @@ import (
 // Get retrieves a raw cached value by key. It returns ErrCacheMiss
 // if the key is not found or has expired.
 func (m *Manager) Get(ctx context.Context, key string) (any, error) {
-	return m.store.Get(ctx, key)
+	if c, ok := m.fast(); ok {
+		val, err := c.store.Get(ctx, key)
+		c.done(ctx, OpGet, 1, err)
+		return val, err
+	}
+
+	call := &Call{Op: OpGet, Keys: []string{key}}
+
+	err := m.run(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Result, err = m.current().Get(ctx, call.Keys[0])
+		return err
+	})
+
+	return call.Result, err
 }
 
 // GetOrSet retrieves a value from the cache if present; otherwise,
 // it computes the value lazily by calling defaultFn, stores it with
 // the given TTL, and returns it. If storing fails, it still returns
-// the computed value along with the store error.
+// the computed value along with the store error. See WithFailOpen to
+// serve loaded values without error while the store is failing.
 func (m *Manager) GetOrSet(ctx context.Context, key string, ttl time.Duration, defaultFn func() (any, error)) (any, error) {
+	if c, ok := m.fast(); ok {
+		val, err := m.getOrSet(ctx, key, ttl, func(context.Context) (any, error) { return defaultFn() })
+		c.done(ctx, OpGetOrSet, 1, err)
+		return val, err
+	}
+
+	call := &Call{Op: OpGetOrSet, Keys: []string{key}, TTL: ttl}
+	call.Load = func(context.Context) (any, error) { return defaultFn() }
+
+	err := m.run(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Result, err = m.getOrSet(ctx, call.Keys[0], call.TTL, call.Load)
+		return err
+	})
+
+	return call.Result, err
+}
+
+func (m *Manager) getOrSet(ctx context.Context, key string, ttl time.Duration, load func(context.Context) (any, error)) (any, error) {
 	val, err := m.Get(ctx, key)
 	if err == nil {
 		return val, nil
 	}
 
-	if !errors.Is(err, ErrCacheMiss) {
+	if !errors.Is(err, ErrCacheMiss) && !m.failsOpen(ctx, err) {
 		return nil, err
 	}
 
-	defaultValue, err := defaultFn()
+	defaultValue, err := load(ctx)
+	m.recordLoad(err)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := m.Set(ctx, key, defaultValue, ttl); err != nil {
+	if err := m.Set(ctx, key, defaultValue, ttl); err != nil && !m.failsOpen(ctx, err) {
 		return defaultValue, err
 	}
 
@@ -41,12 +74,37 @@ func (m *Manager) GetOrSet(ctx context.Context, key string, ttl time.Duration, d
 // Has reports whether the given key exists and is not expired.
 // It should not return an error if the key simply doesn't exist.
 func (m *Manager) Has(ctx context.Context, key string) (bool, error) {
-	return m.store.Has(ctx, key)
+	if c, ok := m.fast(); ok {
+		exists, err := c.store.Has(ctx, key)
+		c.done(ctx, OpHas, 1, err)
+		return exists, err
+	}
+
+	call := &Call{Op: OpHas, Keys: []string{key}}
+
+	err := m.run(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Result, err = m.current().Has(ctx, call.Keys[0])
+		return err
+	})
+
+	exists, _ := call.Result.(bool)
+
+	return exists, err
 }
 
 // Set stores a value in the cache under the given key with the specified TTL.
 // A ttl <= 0 should be treated as "no expiration" by convention, but this
 // behavior is driver-dependent.
 func (m *Manager) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
-	return m.store.Set(ctx, key, value, ttl)
+	if c, ok := m.fast(); ok {
+		err := c.store.Set(ctx, key, value, ttl)
+		c.done(ctx, OpSet, 1, err)
+		return err
+	}
+
+	call := &Call{Op: OpSet, Keys: []string{key}, TTL: ttl, Value: value}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return m.current().Set(ctx, call.Keys[0], call.Value, call.TTL)
+	})
 }
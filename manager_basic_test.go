@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/shoraid/omnicache/contract"
 	"github.com/shoraid/omnicache/internal/assert"
 	omnicachemock "github.com/shoraid/omnicache/mock"
 )
@@ -348,3 +349,77 @@ func TestManager_Set(t *testing.T) {
 		})
 	}
 }
+
+// valueStore is a store whose Get always returns the same value, so that
+// benchmarks measure the Manager rather than a store.
+type valueStore struct {
+	contract.Store
+}
+
+func (valueStore) Get(ctx context.Context, key string) (any, error) {
+	return "value", nil
+}
+
+func BenchmarkManager_Get(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("without middleware", func(b *testing.B) {
+		m := NewManager()
+		if err := m.Register("main", valueStore{}); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_, _ = m.Get(ctx, "key")
+		}
+	})
+
+	b.Run("with middleware", func(b *testing.B) {
+		m := NewManager()
+		if err := m.Register("main", valueStore{}); err != nil {
+			b.Fatal(err)
+		}
+		m.Use(func(next Handler) Handler { return next })
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_, _ = m.Get(ctx, "key")
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		m := NewManager()
+		if err := m.Register("main", valueStore{}); err != nil {
+			b.Fatal(err)
+		}
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_, _ = m.Get(ctx, "key")
+			}
+		})
+	})
+
+	b.Run("view", func(b *testing.B) {
+		m := NewManager()
+		if err := m.Register("main", valueStore{}); err != nil {
+			b.Fatal(err)
+		}
+		view := m.Store("main")
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			_, _ = view.Get(ctx, "key")
+		}
+	})
+}
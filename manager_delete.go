@@ -8,26 +8,66 @@ import (
 // Clear removes all keys and values from the current store.
 // It should not return an error if the store is already empty.
 func (m *Manager) Clear(ctx context.Context) error {
-	return m.store.Clear(ctx)
+	if c, ok := m.fast(); ok {
+		err := c.store.Clear(ctx)
+		c.done(ctx, OpClear, 0, err)
+		return err
+	}
+
+	call := &Call{Op: OpClear}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return m.current().Clear(ctx)
+	})
 }
 
 // Delete removes a single entry by key. If the key does not exist,
 // it should return nil (no error).
 func (m *Manager) Delete(ctx context.Context, key string) error {
-	return m.store.Delete(ctx, key)
+	if c, ok := m.fast(); ok {
+		err := c.store.Delete(ctx, key)
+		c.done(ctx, OpDelete, 1, err)
+		return err
+	}
+
+	call := &Call{Op: OpDelete, Keys: []string{key}}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return m.current().Delete(ctx, call.Keys[0])
+	})
 }
 
 // DeleteByPattern removes all keys matching the provided pattern.
 // The pattern syntax depends on the underlying driver (e.g. glob
 // for Redis, regex for memory). Drivers should document their behavior.
 func (m *Manager) DeleteByPattern(ctx context.Context, pattern string) error {
-	return m.store.DeleteByPattern(ctx, pattern)
+	if c, ok := m.fast(); ok {
+		err := c.store.DeleteByPattern(ctx, pattern)
+		c.done(ctx, OpDeleteByPattern, 0, err)
+		return err
+	}
+
+	call := &Call{Op: OpDeleteByPattern, Pattern: pattern}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return m.current().DeleteByPattern(ctx, call.Pattern)
+	})
 }
 
 // DeleteMany removes multiple entries by their keys. If some keys
 // do not exist, they are skipped without returning an error.
 func (m *Manager) DeleteMany(ctx context.Context, keys ...string) error {
-	return m.store.DeleteMany(ctx, keys...)
+	if c, ok := m.fast(); ok {
+		err := c.store.DeleteMany(ctx, keys...)
+		c.done(ctx, OpDeleteMany, len(keys), err)
+		return err
+	}
+
+	call := &Call{Op: OpDeleteMany, Keys: keys}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return m.current().DeleteMany(ctx, call.Keys...)
+	})
 }
 
 // DeleteManyByPattern removes entries matching any of the given patterns.
@@ -41,7 +81,7 @@ func (m *Manager) DeleteManyByPattern(ctx context.Context, patterns ...string) e
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := m.store.DeleteByPattern(ctx, p); err != nil {
+			if err := m.DeleteByPattern(ctx, p); err != nil {
 				errCh <- err
 			}
 		}()
@@ -0,0 +1,199 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// SetFallback makes operations on alias fail over to the store registered
+// under fallback when the store fails, e.g. to a memory store while Redis
+// is unreachable. Misses and errors caused by the caller, such as an
+// invalid TTL or a context that is done, are returned as usual.
+//
+// Failover is decided per operation: once the store recovers, operations
+// use it again. Values written to the fallback in the meantime are not
+// copied back, and the store misses deletes made while it was down, so
+// prefer short TTLs for data that must not go stale. Sets and deletes that
+// succeed on the store also delete their keys from the fallback, on a best
+// effort basis, so a later outage does not bring back values invalidated
+// since; Clear leaves the fallback alone. The fallback of the
+// fallback is not used. Streaming operations are buffered while a fallback
+// is set.
+//
+// An empty fallback removes the fallback of alias. Returns
+// ErrInvalidStore if either alias is not registered or they are equal.
+func (m *Manager) SetFallback(alias, fallback string) error {
+	reg := m.registry()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, exists := reg.stores[alias]; !exists {
+		return unregisteredAlias(alias)
+	}
+
+	if fallback == "" {
+		delete(reg.fallbacks, alias)
+		reg.publishRoutes()
+		return nil
+	}
+
+	if _, exists := reg.stores[fallback]; !exists {
+		return unregisteredAlias(fallback)
+	}
+
+	if fallback == alias {
+		return fmt.Errorf("%w: store %q cannot be its own fallback", ErrInvalidStore, alias)
+	}
+
+	if reg.fallbacks == nil {
+		reg.fallbacks = make(map[string]string)
+	}
+	reg.fallbacks[alias] = fallback
+	reg.publishRoutes()
+
+	return nil
+}
+
+// IsStoreFailure reports whether err means the store failed, as opposed to
+// a miss, an error caused by the caller or an entry that cannot be read,
+// such as one failing its integrity check. Errors returned once ctx is done
+// are the caller's; timeouts the store applies itself are failures.
+//
+// It decides when a Manager fails over to a fallback store, and is the
+// default failure classifier of the store wrappers.
+func IsStoreFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	for _, target := range []error{
+		ErrCacheMiss,
+		ErrIntegrity,
+		ErrInvalidValue,
+		ErrInvalidStore,
+		ErrTypeMismatch,
+		ErrPatternNotSupported,
+	} {
+		if errors.Is(err, target) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// failsOpen reports whether GetOrSet treats err as a miss.
+func (m *Manager) failsOpen(ctx context.Context, err error) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.failOpen && IsStoreFailure(ctx, err)
+}
+
+// failoverStore runs operations against primary, and against fallback
+// when primary fails. Writes that succeed on primary are invalidated in
+// fallback.
+type failoverStore struct {
+	primary  contract.Store
+	fallback contract.Store
+	stats    *storeStats
+}
+
+// failover reports whether err should be retried against the fallback.
+func (s failoverStore) failover(ctx context.Context, err error) bool {
+	if !IsStoreFailure(ctx, err) {
+		return false
+	}
+
+	if s.stats != nil {
+		atomic.AddUint64(&s.stats.fallbacks, 1)
+	}
+
+	return true
+}
+
+func (s failoverStore) Clear(ctx context.Context) error {
+	if err := s.primary.Clear(ctx); !s.failover(ctx, err) {
+		return err
+	}
+
+	return s.fallback.Clear(ctx)
+}
+
+// Close is a no-op: both stores are registered and closed by the Manager.
+func (s failoverStore) Close(ctx context.Context) error {
+	return nil
+}
+
+func (s failoverStore) Delete(ctx context.Context, key string) error {
+	err := s.primary.Delete(ctx, key)
+	if err == nil {
+		_ = s.fallback.Delete(ctx, key)
+		return nil
+	}
+	if !s.failover(ctx, err) {
+		return err
+	}
+
+	return s.fallback.Delete(ctx, key)
+}
+
+func (s failoverStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	err := s.primary.DeleteByPattern(ctx, pattern)
+	if err == nil {
+		_ = s.fallback.DeleteByPattern(ctx, pattern)
+		return nil
+	}
+	if !s.failover(ctx, err) {
+		return err
+	}
+
+	return s.fallback.DeleteByPattern(ctx, pattern)
+}
+
+func (s failoverStore) DeleteMany(ctx context.Context, keys ...string) error {
+	err := s.primary.DeleteMany(ctx, keys...)
+	if err == nil {
+		_ = s.fallback.DeleteMany(ctx, keys...)
+		return nil
+	}
+	if !s.failover(ctx, err) {
+		return err
+	}
+
+	return s.fallback.DeleteMany(ctx, keys...)
+}
+
+func (s failoverStore) Get(ctx context.Context, key string) (any, error) {
+	if value, err := s.primary.Get(ctx, key); !s.failover(ctx, err) {
+		return value, err
+	}
+
+	return s.fallback.Get(ctx, key)
+}
+
+func (s failoverStore) Has(ctx context.Context, key string) (bool, error) {
+	if exists, err := s.primary.Has(ctx, key); !s.failover(ctx, err) {
+		return exists, err
+	}
+
+	return s.fallback.Has(ctx, key)
+}
+
+func (s failoverStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	err := s.primary.Set(ctx, key, value, ttl)
+	if err == nil {
+		_ = s.fallback.Delete(ctx, key)
+		return nil
+	}
+	if !s.failover(ctx, err) {
+		return err
+	}
+
+	return s.fallback.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,310 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+func TestManager_SetFallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		alias       string
+		fallback    string
+		expectedErr error
+	}{
+		{name: "should set the fallback of a store", alias: "primary", fallback: "local"},
+		{name: "should remove the fallback when it is empty", alias: "primary", fallback: ""},
+		{name: "should reject an unknown alias", alias: "unknown", fallback: "local", expectedErr: ErrInvalidStore},
+		{name: "should reject an unknown fallback", alias: "primary", fallback: "unknown", expectedErr: ErrInvalidStore},
+		{name: "should reject a store as its own fallback", alias: "primary", fallback: "primary", expectedErr: ErrInvalidStore},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", omnicachemock.NewMockStore(t)))
+			assert.NoError(t, m.Register("local", omnicachemock.NewMockStore(t)))
+
+			// --- Act ---
+			err := m.SetFallback(tt.alias, tt.fallback)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected ErrInvalidStore")
+				assert.Equal(t, 0, len(m.fallbacks), "expected no fallback to be set")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.fallback, m.fallbacks[tt.alias], "expected the fallback to be set")
+		})
+	}
+}
+
+func TestManager_IsStoreFailure(t *testing.T) {
+	t.Parallel()
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		expected bool
+	}{
+		{name: "should not report nil", ctx: context.Background(), err: nil, expected: false},
+		{name: "should report store errors", ctx: context.Background(), err: errors.New("connection refused"), expected: true},
+		{name: "should not report misses", ctx: context.Background(), err: ErrCacheMiss, expected: false},
+		{name: "should not report invalid values", ctx: context.Background(), err: ErrInvalidValue, expected: false},
+		{name: "should not report entries failing their integrity check", ctx: context.Background(), err: fmt.Errorf("%w: tampered", ErrIntegrity), expected: false},
+		{name: "should not report errors once the context is done", ctx: canceled, err: errors.New("connection refused"), expected: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result := IsStoreFailure(tt.ctx, tt.err)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expected, result, "expected the failure to be classified")
+		})
+	}
+}
+
+func TestManager_fallback(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("dial tcp: connection refused")
+
+	tests := []struct {
+		name              string
+		setup             func(ctx context.Context, primary, fallback *omnicachemock.MockStore)
+		act               func(ctx context.Context, m *Manager) (any, error)
+		expected          any
+		expectedErr       error
+		expectedFallbacks uint64
+	}{
+		{
+			name: "should serve Get from the fallback when the store fails",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Get", ctx, "k").Return(nil, storeErr)
+				fallback.Mock.On("Get", ctx, "k").Return("local", nil)
+			},
+			act:               func(ctx context.Context, m *Manager) (any, error) { return m.Get(ctx, "k") },
+			expected:          "local",
+			expectedFallbacks: 1,
+		},
+		{
+			name: "should not fail over on a miss",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Get", ctx, "k").Return(nil, ErrCacheMiss)
+			},
+			act:         func(ctx context.Context, m *Manager) (any, error) { return m.Get(ctx, "k") },
+			expectedErr: ErrCacheMiss,
+		},
+		{
+			name: "should not fail over on an invalid value",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Set", ctx, "k", "v", -time.Second).Return(ErrInvalidValue)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.Set(ctx, "k", "v", -time.Second)
+			},
+			expectedErr: ErrInvalidValue,
+		},
+		{
+			name: "should write to the fallback when the store fails",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Set", ctx, "k", "v", time.Minute).Return(storeErr)
+				fallback.Mock.On("Set", ctx, "k", "v", time.Minute).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.Set(ctx, "k", "v", time.Minute)
+			},
+			expectedFallbacks: 1,
+		},
+		{
+			name: "should delete from the fallback when the store fails",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(storeErr)
+				fallback.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.DeleteMany(ctx, "a", "b")
+			},
+			expectedFallbacks: 1,
+		},
+		{
+			name: "should delete the key from the fallback when Set succeeds",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Set", ctx, "k", "v", time.Minute).Return(nil)
+				fallback.Mock.On("Delete", ctx, "k").Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.Set(ctx, "k", "v", time.Minute)
+			},
+		},
+		{
+			name: "should delete the keys from the fallback when DeleteMany succeeds",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+				fallback.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.DeleteMany(ctx, "a", "b")
+			},
+		},
+		{
+			name: "should ignore fallback errors when invalidating after Delete",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Delete", ctx, "k").Return(nil)
+				fallback.Mock.On("Delete", ctx, "k").Return(ErrInternal)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.Delete(ctx, "k")
+			},
+		},
+		{
+			name: "should delete matching keys from the fallback when DeleteByPattern succeeds",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("DeleteByPattern", ctx, "user:*").Return(nil)
+				fallback.Mock.On("DeleteByPattern", ctx, "user:*").Return(ErrPatternNotSupported)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return nil, m.DeleteByPattern(ctx, "user:*")
+			},
+		},
+		{
+			name: "should return the fallback error when both stores fail",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Has", ctx, "k").Return(false, storeErr)
+				fallback.Mock.On("Has", ctx, "k").Return(false, ErrInternal)
+			},
+			act:               func(ctx context.Context, m *Manager) (any, error) { return m.Has(ctx, "k") },
+			expected:          false,
+			expectedErr:       ErrInternal,
+			expectedFallbacks: 1,
+		},
+		{
+			name: "should load and store into the fallback in GetOrSet",
+			setup: func(ctx context.Context, primary, fallback *omnicachemock.MockStore) {
+				primary.Mock.On("Get", ctx, "k").Return(nil, storeErr)
+				primary.Mock.On("Set", ctx, "k", "loaded", time.Minute).Return(storeErr)
+				fallback.Mock.On("Get", ctx, "k").Return(nil, ErrCacheMiss)
+				fallback.Mock.On("Set", ctx, "k", "loaded", time.Minute).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) (any, error) {
+				return m.GetOrSet(ctx, "k", time.Minute, func() (any, error) { return "loaded", nil })
+			},
+			expected:          "loaded",
+			expectedFallbacks: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			primary := omnicachemock.NewMockStore(t)
+			fallback := omnicachemock.NewMockStore(t)
+			tt.setup(ctx, primary, fallback)
+
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", primary))
+			assert.NoError(t, m.Register("local", fallback))
+			assert.NoError(t, m.SetFallback("primary", "local"))
+
+			// --- Act ---
+			result, err := tt.act(ctx, m)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected the error to match")
+			} else {
+				assert.NoError(t, err, "expected no error")
+			}
+			assert.Equal(t, tt.expected, result, "expected the result to match")
+
+			stats := m.Stats()["primary"]
+			assert.Equal(t, tt.expectedFallbacks, stats.Fallbacks, "expected the fallbacks to be counted")
+			if tt.expectedErr == nil {
+				assert.Equal(t, uint64(0), stats.Errors, "expected failovers not to be counted as errors")
+			}
+		})
+	}
+}
+
+func TestManager_fallback_views(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	storeErr := errors.New("connection refused")
+
+	primary := omnicachemock.NewMockStore(t)
+	fallback := omnicachemock.NewMockStore(t)
+	primary.Mock.On("Get", ctx, "k").Return(nil, storeErr)
+	fallback.Mock.On("Get", ctx, "k").Return("local", nil)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("main", omnicachemock.NewMockStore(t)))
+	assert.NoError(t, m.Register("primary", primary))
+	assert.NoError(t, m.Register("local", fallback))
+	view := m.Store("primary")
+
+	// --- Act ---
+	assert.NoError(t, m.SetFallback("primary", "local"))
+	withFallback, errWithFallback := view.Get(ctx, "k")
+
+	_, err := m.Unregister("local")
+	assert.NoError(t, err, "expected no error when unregistering the fallback")
+	_, errWithoutFallback := view.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.NoError(t, errWithFallback, "expected existing views to fail over")
+	assert.Equal(t, "local", withFallback, "expected the fallback value")
+	assert.EqualError(t, storeErr, errWithoutFallback, "expected the store error once the fallback is unregistered")
+	assert.Equal(t, 0, len(m.fallbacks), "expected the fallback to be removed")
+}
+
+func TestManager_fallback_contextDone(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary := omnicachemock.NewMockStore(t)
+	primary.Mock.On("Get", ctx, "k").Return(nil, context.Canceled)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("primary", primary))
+	assert.NoError(t, m.Register("local", omnicachemock.NewMockStore(t)))
+	assert.NoError(t, m.SetFallback("primary", "local"))
+
+	// --- Act ---
+	_, err := m.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, context.Canceled), "expected the caller's error")
+	assert.Equal(t, uint64(0), m.Stats()["primary"].Fallbacks, "expected no failover once the context is done")
+}
@@ -0,0 +1,114 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// Unregister removes the store registered under alias and returns it. It
+// also removes the fallback of alias and any fallbacks pointing at alias.
+// The store is not closed. Views bound to alias fail with ErrInvalidStore
+// from then on, and if alias is the default store, the Manager has no
+// default until SetDefault is called or another store is registered.
+// Returns ErrInvalidStore if the alias is not registered.
+func (m *Manager) Unregister(alias string) (contract.Store, error) {
+	reg := m.registry()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	store, exists := reg.stores[alias]
+	if !exists {
+		return nil, unregisteredAlias(alias)
+	}
+
+	delete(reg.stores, alias)
+	delete(reg.statsByAlias, alias)
+	delete(reg.fallbacks, alias)
+	for primary, fallback := range reg.fallbacks {
+		if fallback == alias {
+			delete(reg.fallbacks, primary)
+		}
+	}
+
+	if reg.alias == alias {
+		reg.store = nil
+		reg.alias = ""
+	}
+	reg.publishRoutes()
+
+	return store, nil
+}
+
+// Replace atomically swaps the store registered under alias, e.g. after a
+// failover, and returns the previous one. Operations started afterwards
+// use the new store, including those of views returned by Store before
+// the swap. The previous store is not closed, so operations still running
+// against it can complete; close it once they have.
+// Statistics of alias carry over to the new store.
+// Returns ErrInvalidStore if the alias is not registered or store is nil.
+func (m *Manager) Replace(alias string, store contract.Store) (contract.Store, error) {
+	if store == nil {
+		return nil, fmt.Errorf("%w: store is nil", ErrInvalidStore)
+	}
+
+	reg := m.registry()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	previous, exists := reg.stores[alias]
+	if !exists {
+		return nil, unregisteredAlias(alias)
+	}
+
+	reg.stores[alias] = store
+	if stats := reg.statsByAlias[alias]; stats != nil {
+		stats.replace(store)
+	}
+
+	if reg.alias == alias {
+		reg.store = store
+	}
+	reg.publishRoutes()
+
+	return previous, nil
+}
+
+// Close closes every registered store concurrently and returns their
+// errors joined, each prefixed with the alias of its store. The stores stay
+// registered. A store registered under several aliases is closed once per alias.
+func (m *Manager) Close(ctx context.Context) error {
+	reg := m.registry()
+	reg.mu.RLock()
+	aliases := make([]string, 0, len(reg.stores))
+	stores := make([]contract.Store, 0, len(reg.stores))
+	for alias := range reg.stores {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		stores = append(stores, reg.stores[alias])
+	}
+	reg.mu.RUnlock()
+
+	errs := make([]error, len(stores))
+
+	var wg sync.WaitGroup
+	for i := range stores {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			if err := stores[i].Close(ctx); err != nil {
+				errs[i] = fmt.Errorf("cache: closing store %q: %w", aliases[i], err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
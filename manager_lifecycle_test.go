@@ -0,0 +1,258 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+func TestManager_Unregister(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		alias           string
+		expectedErr     error
+		expectedDefault bool
+	}{
+		{
+			name:            "should unregister a secondary store and keep the default",
+			alias:           "secondary",
+			expectedDefault: true,
+		},
+		{
+			name:            "should unregister the default store and leave no default",
+			alias:           "primary",
+			expectedDefault: false,
+		},
+		{
+			name:        "should return ErrInvalidStore for an unknown alias",
+			alias:       "unknown",
+			expectedErr: ErrInvalidStore,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			stores := map[string]*omnicachemock.MockStore{
+				"primary":   omnicachemock.NewMockStore(t),
+				"secondary": omnicachemock.NewMockStore(t),
+			}
+
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", stores["primary"]))
+			assert.NoError(t, m.Register("secondary", stores["secondary"]))
+			view := m.Store(tt.alias)
+
+			// --- Act ---
+			store, err := m.Unregister(tt.alias)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected ErrInvalidStore")
+				assert.Nil(t, store, "expected no store")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, contract.Store(stores[tt.alias]), store, "expected the unregistered store")
+
+			_, err = m.StoreE(tt.alias)
+			assert.True(t, errors.Is(err, ErrInvalidStore), "expected the alias to be unknown")
+			assert.True(t, errors.Is(view.Set(ctx, "k", "v", 0), ErrInvalidStore), "expected existing views to fail")
+			_, exists := m.Stats()[tt.alias]
+			assert.False(t, exists, "expected the stats of the alias to be dropped")
+
+			if tt.expectedDefault {
+				stores["primary"].Mock.On("Clear", ctx).Return(nil)
+				assert.NoError(t, m.Clear(ctx), "expected the default store to remain")
+			} else {
+				assert.True(t, errors.Is(m.Clear(ctx), ErrInvalidStore), "expected no default store")
+			}
+		})
+	}
+}
+
+func TestManager_Unregister_registerDefaultAgain(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := omnicachemock.NewMockStore(t)
+	store.Mock.On("Clear", ctx).Return(nil)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("primary", omnicachemock.NewMockStore(t)))
+	_, err := m.Unregister("primary")
+	assert.NoError(t, err, "expected no error when unregistering")
+
+	// --- Act ---
+	err = m.Register("secondary", store)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error when registering")
+	assert.NoError(t, m.Clear(ctx), "expected the newly registered store to become the default")
+}
+
+func TestManager_Replace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		alias       string
+		store       func(t *testing.T) contract.Store
+		expectedErr error
+	}{
+		{
+			name:        "should return ErrInvalidStore for an unknown alias",
+			alias:       "unknown",
+			store:       func(t *testing.T) contract.Store { return omnicachemock.NewMockStore(t) },
+			expectedErr: ErrInvalidStore,
+		},
+		{
+			name:        "should return ErrInvalidStore for a nil store",
+			alias:       "primary",
+			store:       func(t *testing.T) contract.Store { return nil },
+			expectedErr: ErrInvalidStore,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", omnicachemock.NewMockStore(t)))
+
+			// --- Act ---
+			previous, err := m.Replace(tt.alias, tt.store(t))
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, tt.expectedErr), "expected ErrInvalidStore")
+			assert.Nil(t, previous, "expected no previous store")
+		})
+	}
+}
+
+func TestManager_Replace_views(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	oldPrimary := &sizedStore{MockStore: omnicachemock.NewMockStore(t), evictions: 10}
+	newPrimary := &sizedStore{MockStore: omnicachemock.NewMockStore(t), evictions: 100}
+	oldPrimary.Mock.On("Get", ctx, "k").Return("old", nil)
+	newPrimary.Mock.On("Get", ctx, "k").Return("new", nil)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("primary", oldPrimary))
+	view := m.Store("primary")
+	typed := G[string](m)
+
+	_, _ = m.Get(ctx, "k")
+	atomic.AddUint64(&oldPrimary.evictions, 2)
+
+	// --- Act ---
+	previous, err := m.Replace("primary", newPrimary)
+	atomic.AddUint64(&newPrimary.evictions, 3)
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, contract.Store(oldPrimary), previous, "expected the previous store to be returned")
+
+	value, err := m.Get(ctx, "k")
+	assert.NoError(t, err, "expected no error from the default store")
+	assert.Equal(t, "new", value, "expected the default store to be replaced")
+
+	value, err = view.Get(ctx, "k")
+	assert.NoError(t, err, "expected no error from the view")
+	assert.Equal(t, "new", value, "expected existing views to use the new store")
+
+	typedValue, err := typed.Get(ctx, "k")
+	assert.NoError(t, err, "expected no error from the typed manager")
+	assert.Equal(t, "new", typedValue, "expected typed managers to use the new store")
+
+	stats := m.Stats()["primary"]
+	assert.Equal(t, uint64(4), stats.Hits, "expected stats to carry over the swap")
+	assert.Equal(t, uint64(5), stats.Evictions, "expected evictions of both stores")
+}
+
+func TestManager_Close(t *testing.T) {
+	t.Parallel()
+
+	closeErr := errors.New("connection reset")
+
+	tests := []struct {
+		name        string
+		setup       func(ctx context.Context, primary, secondary *omnicachemock.MockStore)
+		expectedErr []string
+	}{
+		{
+			name: "should close every store",
+			setup: func(ctx context.Context, primary, secondary *omnicachemock.MockStore) {
+				primary.Mock.On("Close", ctx).Return(nil)
+				secondary.Mock.On("Close", ctx).Return(nil)
+			},
+		},
+		{
+			name: "should close every store and join the errors",
+			setup: func(ctx context.Context, primary, secondary *omnicachemock.MockStore) {
+				primary.Mock.On("Close", ctx).Return(closeErr)
+				secondary.Mock.On("Close", ctx).Return(closeErr)
+			},
+			expectedErr: []string{
+				`cache: closing store "primary": connection reset`,
+				`cache: closing store "secondary": connection reset`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			primary := omnicachemock.NewMockStore(t)
+			secondary := omnicachemock.NewMockStore(t)
+			tt.setup(ctx, primary, secondary)
+
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", primary))
+			assert.NoError(t, m.Register("secondary", secondary))
+
+			// --- Act ---
+			err := m.Close(ctx)
+
+			// --- Assert ---
+			primary.Mock.AssertCalled(t, "Close", ctx)
+			secondary.Mock.AssertCalled(t, "Close", ctx)
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err, "expected no error")
+				return
+			}
+
+			assert.True(t, errors.Is(err, closeErr), "expected the close error to be wrapped")
+			for _, msg := range tt.expectedErr {
+				assert.Contains(t, err.Error(), msg, "expected the error of each store")
+			}
+
+			_, err = m.StoreE("primary")
+			assert.NoError(t, err, "expected stores to stay registered")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package omnicache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// GetReader returns a reader over the []byte or string value stored under
+// key. The caller must close it.
+//
+// Stores implementing contract.Streamer stream the value natively. For
+// other stores the value is read with Get.
+// Returns ErrCacheMiss if the key is not found or expired, and
+// ErrTypeMismatch if the value is neither a []byte nor a string.
+func (m *Manager) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	if c, ok := m.fast(); ok {
+		reader, err := getReader(ctx, c.store, key)
+		c.done(ctx, OpGetReader, 1, err)
+		return reader, err
+	}
+
+	call := &Call{Op: OpGetReader, Keys: []string{key}}
+
+	err := m.run(ctx, call, func(ctx context.Context, call *Call) (err error) {
+		call.Result, err = getReader(ctx, m.current(), call.Keys[0])
+		return err
+	})
+
+	reader, _ := call.Result.(io.ReadCloser)
+
+	return reader, err
+}
+
+func getReader(ctx context.Context, store contract.Store, key string) (io.ReadCloser, error) {
+	if streamer, ok := store.(contract.Streamer); ok {
+		return streamer.GetReader(ctx, key)
+	}
+
+	val, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return readerFromValue(val)
+}
+
+// SetFromReader stores everything read from r as a []byte value under key
+// with the given TTL.
+//
+// Stores implementing contract.Streamer write the value without buffering
+// it; for other stores it is read into memory and stored with Set.
+func (m *Manager) SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error {
+	if c, ok := m.fast(); ok {
+		err := setFromReader(ctx, c.store, key, r, ttl)
+		c.done(ctx, OpSetFromReader, 1, err)
+		return err
+	}
+
+	call := &Call{Op: OpSetFromReader, Keys: []string{key}, TTL: ttl}
+
+	return m.run(ctx, call, func(ctx context.Context, call *Call) error {
+		return setFromReader(ctx, m.current(), call.Keys[0], r, call.TTL)
+	})
+}
+
+func setFromReader(ctx context.Context, store contract.Store, key string, r io.Reader, ttl time.Duration) error {
+	if streamer, ok := store.(contract.Streamer); ok {
+		return streamer.SetFromReader(ctx, key, r, ttl)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return store.Set(ctx, key, data, ttl)
+}
+
+// readerFromValue returns a reader over a value returned by Store.Get.
+func readerFromValue(v any) (io.ReadCloser, error) {
+	switch val := v.(type) {
+	case []byte:
+		return io.NopCloser(bytes.NewReader(val)), nil
+
+	case string:
+		return io.NopCloser(strings.NewReader(val)), nil
+
+	default:
+		return nil, ErrTypeMismatch
+	}
+}
@@ -0,0 +1,177 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+func TestManager_GetReader(t *testing.T) {
+	t.Parallel()
+
+	key := "test-key"
+
+	tests := []struct {
+		name        string
+		mockVal     any
+		mockErr     error
+		expectedVal string
+		expectedErr error
+	}{
+		{
+			name:        "should stream []byte values",
+			mockVal:     []byte("bytes"),
+			expectedVal: "bytes",
+		},
+		{
+			name:        "should stream string values",
+			mockVal:     "text",
+			expectedVal: "text",
+		},
+		{
+			name:        "should stream strings that look like base64 as is",
+			mockVal:     "test",
+			expectedVal: "test",
+		},
+		{
+			name:        "should stream quoted strings as is",
+			mockVal:     `"dGVzdA=="`,
+			expectedVal: `"dGVzdA=="`,
+		},
+		{
+			name:        "should return ErrTypeMismatch for other values",
+			mockVal:     42,
+			expectedErr: ErrTypeMismatch,
+		},
+		{
+			name:        "should return the store error",
+			mockErr:     ErrCacheMiss,
+			expectedErr: ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			mockStore := omnicachemock.NewMockStore(t)
+			mockStore.Mock.On("Get", ctx, key).Return(tt.mockVal, tt.mockErr)
+
+			manager := &Manager{store: mockStore}
+
+			// --- Act ---
+			reader, err := manager.GetReader(ctx, key)
+
+			// --- Assert ---
+			mockStore.Mock.AssertExpectations(t)
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error returned by GetReader must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "must not return an error when GetReader succeeds")
+			data, err := io.ReadAll(reader)
+			assert.NoError(t, err, "must not return an error when reading")
+			assert.NoError(t, reader.Close(), "must not return an error when closing")
+			assert.Equal(t, tt.expectedVal, string(data), "streamed value must match the expected value")
+		})
+	}
+
+	t.Run("should delegate to stores implementing contract.Streamer", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		reader := io.NopCloser(strings.NewReader("native"))
+		mockStore := omnicachemock.NewMockStreamStore(t)
+		mockStore.Mock.On("GetReader", ctx, key).Return(reader, nil)
+
+		manager := &Manager{store: mockStore}
+
+		// --- Act ---
+		result, err := manager.GetReader(ctx, key)
+
+		// --- Assert ---
+		mockStore.Mock.AssertExpectations(t)
+		mockStore.Mock.AssertNotCalled(t, "Get", ctx, key)
+		assert.NoError(t, err, "must not return an error when GetReader succeeds")
+		assert.Equal(t, reader, result, "must return the store's reader")
+	})
+}
+
+func TestManager_SetFromReader(t *testing.T) {
+	t.Parallel()
+
+	key := "test-key"
+	ttl := time.Minute
+
+	t.Run("should buffer the content and call Set for other stores", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		mockStore := omnicachemock.NewMockStore(t)
+		mockStore.Mock.On("Set", ctx, key, []byte("content"), ttl).Return(nil)
+
+		manager := &Manager{store: mockStore}
+
+		// --- Act ---
+		err := manager.SetFromReader(ctx, key, strings.NewReader("content"), ttl)
+
+		// --- Assert ---
+		mockStore.Mock.AssertExpectations(t)
+		assert.NoError(t, err, "must not return an error when SetFromReader succeeds")
+	})
+
+	t.Run("should not call Set when reading fails", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		readErr := errors.New("read failed")
+		mockStore := omnicachemock.NewMockStore(t)
+
+		manager := &Manager{store: mockStore}
+
+		// --- Act ---
+		err := manager.SetFromReader(ctx, key, io.MultiReader(strings.NewReader("partial"), failingReader{readErr}), ttl)
+
+		// --- Assert ---
+		mockStore.Mock.AssertNotCalled(t, "Set", ctx, key, []byte("partial"), ttl)
+		assert.EqualError(t, readErr, err, "error must match the read error")
+	})
+
+	t.Run("should delegate to stores implementing contract.Streamer", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		reader := strings.NewReader("content")
+		mockStore := omnicachemock.NewMockStreamStore(t)
+		mockStore.Mock.On("SetFromReader", ctx, key, reader, ttl).Return(nil)
+
+		manager := &Manager{store: mockStore}
+
+		// --- Act ---
+		err := manager.SetFromReader(ctx, key, reader, ttl)
+
+		// --- Assert ---
+		mockStore.Mock.AssertExpectations(t)
+		assert.NoError(t, err, "must not return an error when SetFromReader succeeds")
+	})
+}
+
+type failingReader struct{ err error }
+
+func (r failingReader) Read([]byte) (int, error) { return 0, r.err }
@@ -1,6 +1,11 @@
 package omnicache
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
 	"testing"
 
 	"github.com/shoraid/omnicache/contract"
@@ -175,6 +180,50 @@ func TestManager_SetDefault(t *testing.T) {
 	}
 }
 
+func TestManager_SetDefault_concurrentCalls(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	m := NewManager()
+	assert.NoError(t, m.Register("a", valueStore{}))
+	assert.NoError(t, m.Register("b", valueStore{}))
+
+	var mu sync.Mutex
+	aliases := map[string]bool{}
+	m.Use(func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) error {
+			mu.Lock()
+			aliases[call.Alias] = true
+			mu.Unlock()
+			return next(ctx, call)
+		}
+	})
+
+	// --- Act ---
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_ = m.SetDefault([]string{"a", "b"}[i%2])
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_, _ = m.Get(ctx, "k")
+			_, _ = G[string](m).GetOrSet(ctx, "k", 0, func() (string, error) { return "value", nil })
+		}
+	}()
+	wg.Wait()
+
+	// --- Assert ---
+	for alias := range aliases {
+		assert.True(t, alias == "a" || alias == "b", "expected calls to carry a registered alias")
+	}
+}
+
 func TestManager_Store(t *testing.T) {
 	t.Parallel()
 
@@ -229,3 +278,153 @@ func TestManager_Store(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_StoreE(t *testing.T) {
+	t.Parallel()
+
+	mockMemory := omnicachemock.NewMockStore(t)
+	mockRedis := omnicachemock.NewMockStore(t)
+
+	tests := []struct {
+		name          string
+		alias         string
+		expectedStore contract.Store
+		expectedErr   error
+	}{
+		{
+			name:          "should return manager with specified store when alias exists",
+			alias:         "redis",
+			expectedStore: mockRedis,
+		},
+		{
+			name:        "should return ErrInvalidStore when alias does not exist",
+			alias:       "nonexistent",
+			expectedErr: ErrInvalidStore,
+		},
+		{
+			name:        "should return ErrInvalidStore when alias is empty",
+			alias:       "",
+			expectedErr: ErrInvalidStore,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			manager := NewManager()
+			assert.NoError(t, manager.Register("memory", mockMemory))
+			assert.NoError(t, manager.Register("redis", mockRedis))
+
+			// --- Act ---
+			aliasedManager, err := manager.StoreE(tt.alias)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, ErrInvalidStore), "expected ErrInvalidStore")
+				assert.True(t, aliasedManager == nil, "expected no manager")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expectedStore, aliasedManager.store, "expected the aliased store to be set correctly")
+			assert.Equal(t, tt.alias, aliasedManager.alias, "expected the alias of the view")
+		})
+	}
+}
+
+func TestManager_MustStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return manager with specified store when alias exists", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := omnicachemock.NewMockStore(t)
+		manager := NewManager()
+		assert.NoError(t, manager.Register("memory", store))
+
+		// --- Act ---
+		aliasedManager := manager.MustStore("memory")
+
+		// --- Assert ---
+		assert.Equal(t, contract.Store(store), aliasedManager.store, "expected the aliased store")
+	})
+
+	t.Run("should panic when alias does not exist", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		manager := NewManager()
+
+		defer func() {
+			err, _ := recover().(error)
+			assert.True(t, errors.Is(err, ErrInvalidStore), "expected a panic with ErrInvalidStore")
+		}()
+
+		// --- Act ---
+		manager.MustStore("nonexistent")
+	})
+}
+
+func TestManager_StoreUnknownAliasModes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fall back to the default store by default", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		manager := NewManager(WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+		assert.NoError(t, manager.Register("memory", omnicachemock.NewMockStore(t)))
+
+		// --- Act ---
+		aliasedManager := manager.Store("nonexistent")
+
+		// --- Assert ---
+		assert.True(t, aliasedManager == manager, "expected the manager itself")
+	})
+
+	t.Run("should panic in panic mode", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		manager := NewManager(WithUnknownAlias(UnknownAliasPanic))
+		assert.NoError(t, manager.Register("memory", omnicachemock.NewMockStore(t)))
+
+		defer func() {
+			err, _ := recover().(error)
+			assert.True(t, errors.Is(err, ErrInvalidStore), "expected a panic with ErrInvalidStore")
+		}()
+
+		// --- Act ---
+		manager.Store("nonexistent")
+	})
+
+	t.Run("should fail every operation in error mode", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := omnicachemock.NewMockStore(t)
+		manager := NewManager(WithUnknownAlias(UnknownAliasError))
+		assert.NoError(t, manager.Register("memory", store))
+
+		// --- Act ---
+		aliasedManager := manager.Store("sessions")
+		_, getErr := aliasedManager.Get(ctx, "k")
+		setErr := aliasedManager.Set(ctx, "k", "v", 0)
+		_, loadErr := aliasedManager.GetOrSet(ctx, "k", 0, func() (any, error) { return "v", nil })
+		storeAfterMode := manager.Store("memory")
+
+		// --- Assert ---
+		assert.True(t, errors.Is(getErr, ErrInvalidStore), "expected Get to fail")
+		assert.True(t, errors.Is(setErr, ErrInvalidStore), "expected Set to fail")
+		assert.True(t, errors.Is(loadErr, ErrInvalidStore), "expected GetOrSet to fail without loading")
+		assert.Equal(t, "sessions", aliasedManager.alias, "expected the requested alias")
+		assert.Equal(t, contract.Store(store), storeAfterMode.store, "expected registered aliases to be unaffected")
+		store.Mock.AssertNotCalled(t, "Get")
+	})
+}
@@ -0,0 +1,138 @@
+package prommetrics
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shoraid/omnicache"
+)
+
+const (
+	labelStore     = "store"
+	labelOperation = "operation"
+)
+
+// Collector is a prometheus.Collector exporting the statistics a Manager
+// collects for its stores (see Manager.Stats), labelled by store alias.
+//
+// Counters are read when Prometheus scrapes, so the Manager's hot path is
+// not affected. Manager.ResetStats shows up as a counter reset.
+type Collector struct {
+	manager *omnicache.Manager
+
+	hits         *prometheus.Desc
+	misses       *prometheus.Desc
+	sets         *prometheus.Desc
+	deletes      *prometheus.Desc
+	errors       *prometheus.Desc
+	loaderCalls  *prometheus.Desc
+	loaderErrors *prometheus.Desc
+	fallbacks    *prometheus.Desc
+	evictions    *prometheus.Desc
+	entries      *prometheus.Desc
+	bytes        *prometheus.Desc
+	duration     *prometheus.Desc
+}
+
+// NewCollector creates a Collector for manager. Register it with a
+// prometheus.Registerer to export the metrics:
+//
+//	collector, err := prommetrics.NewCollector(manager, prommetrics.CollectorConfig{})
+//	prometheus.MustRegister(collector)
+//
+// Returns ErrInvalidConfig if manager is nil.
+func NewCollector(manager *omnicache.Manager, config CollectorConfig) (*Collector, error) {
+	if manager == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if config.Namespace == "" {
+		config.Namespace = DefaultNamespace
+	}
+
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(config.Namespace, "", name), help, labels, config.ConstLabels)
+	}
+
+	return &Collector{
+		manager:      manager,
+		hits:         desc("hits_total", "Number of lookups that found the key.", labelStore),
+		misses:       desc("misses_total", "Number of lookups that did not find the key.", labelStore),
+		sets:         desc("sets_total", "Number of values stored.", labelStore),
+		deletes:      desc("deletes_total", "Number of keys deleted, and of pattern deletes and clears.", labelStore),
+		errors:       desc("errors_total", "Number of store operations that failed, not counting misses.", labelStore),
+		loaderCalls:  desc("loader_calls_total", "Number of GetOrSet loader invocations.", labelStore),
+		loaderErrors: desc("loader_errors_total", "Number of GetOrSet loader invocations that failed.", labelStore),
+		fallbacks:    desc("fallbacks_total", "Number of operations served by the fallback store.", labelStore),
+		evictions:    desc("evictions_total", "Number of entries evicted before they expired.", labelStore),
+		entries:      desc("entries", "Number of entries in the store.", labelStore),
+		bytes:        desc("bytes", "Approximate size of the entries in the store.", labelStore),
+		duration:     desc("operation_duration_seconds", "Store latency of cache operations.", labelStore, labelOperation),
+	}, nil
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.sets
+	ch <- c.deletes
+	ch <- c.errors
+	ch <- c.loaderCalls
+	ch <- c.loaderErrors
+	ch <- c.fallbacks
+	ch <- c.evictions
+	ch <- c.entries
+	ch <- c.bytes
+	ch <- c.duration
+}
+
+// Collect implements prometheus.Collector.
+//
+// The entries and bytes gauges are only exported for stores implementing
+// contract.Sizer, such as the memory store.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for alias, stats := range c.manager.Stats() {
+		counter := func(desc *prometheus.Desc, value uint64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), alias)
+		}
+
+		counter(c.hits, stats.Hits)
+		counter(c.misses, stats.Misses)
+		counter(c.sets, stats.Sets)
+		counter(c.deletes, stats.Deletes)
+		counter(c.errors, stats.Errors)
+		counter(c.loaderCalls, stats.LoaderCalls)
+		counter(c.loaderErrors, stats.LoaderErrors)
+		counter(c.fallbacks, stats.Fallbacks)
+		counter(c.evictions, stats.Evictions)
+
+		if stats.Entries >= 0 {
+			ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries), alias)
+			ch <- prometheus.MustNewConstMetric(c.bytes, prometheus.GaugeValue, float64(stats.Bytes), alias)
+		}
+
+		ops := make([]string, 0, len(stats.Latency))
+		for op := range stats.Latency {
+			ops = append(ops, string(op))
+		}
+		sort.Strings(ops)
+
+		for _, op := range ops {
+			ch <- c.histogram(stats.Latency[omnicache.Op(op)], alias, op)
+		}
+	}
+}
+
+// histogram converts h to a Prometheus histogram with cumulative buckets.
+func (c *Collector) histogram(h omnicache.LatencyHistogram, alias, op string) prometheus.Metric {
+	buckets := make(map[float64]uint64, len(h.Bounds))
+
+	var cumulative uint64
+	for i, bound := range h.Bounds {
+		cumulative += h.Buckets[i]
+		buckets[bound.Seconds()] = cumulative
+	}
+
+	return prometheus.MustNewConstHistogram(c.duration, h.Count, h.Sum.Seconds(), buckets, alias, op)
+}
@@ -0,0 +1,151 @@
+package prommetrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+// gather registers a collector for m with a fresh registry and returns the
+// gathered metric families by name.
+func gather(t *testing.T, m *omnicache.Manager, config CollectorConfig) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	collector, err := NewCollector(m, config)
+	assert.NoError(t, err, "expected no error when creating the collector")
+
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(collector), "expected the collector to register")
+
+	families, err := registry.Gather()
+	assert.NoError(t, err, "expected no error when gathering")
+
+	byName := make(map[string]*dto.MetricFamily, len(families))
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	return byName
+}
+
+// metric returns the metric of family whose labels match labels.
+func metric(family *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	if family == nil {
+		return nil
+	}
+
+	for _, m := range family.GetMetric() {
+		matched := 0
+		for _, pair := range m.GetLabel() {
+			if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(labels) {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func TestCollector_NewCollector(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	collector, err := NewCollector(nil, CollectorConfig{})
+
+	// --- Assert ---
+	assert.True(t, collector == nil, "expected no collector")
+	assert.EqualError(t, omnicache.ErrInvalidConfig, err, "expected ErrInvalidConfig for a nil manager")
+}
+
+func TestCollector_Collect(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+
+	memoryStore, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+	t.Cleanup(func() { _ = memoryStore.Close(ctx) })
+
+	mockStore := omnicachemock.NewMockStore(t)
+	mockStore.Mock.On("Get", ctx, "k").Return(nil, errors.New("store down"))
+
+	m := omnicache.NewManager()
+	assert.NoError(t, m.Register("memory", memoryStore))
+	assert.NoError(t, m.Register("remote", mockStore))
+
+	assert.NoError(t, m.Set(ctx, "k", "hello", 0))
+	_, _ = m.Get(ctx, "k")
+	_, _ = m.Get(ctx, "missing")
+	_, _ = m.GetOrSet(ctx, "loaded", time.Minute, func() (any, error) { return "v", nil })
+	_, _ = m.Store("remote").Get(ctx, "k")
+
+	// --- Act ---
+	families := gather(t, m, CollectorConfig{})
+
+	// --- Assert ---
+	counters := []struct {
+		name     string
+		alias    string
+		expected float64
+	}{
+		{name: "omnicache_hits_total", alias: "memory", expected: 1},
+		{name: "omnicache_misses_total", alias: "memory", expected: 2},
+		{name: "omnicache_sets_total", alias: "memory", expected: 2},
+		{name: "omnicache_loader_calls_total", alias: "memory", expected: 1},
+		{name: "omnicache_errors_total", alias: "memory", expected: 0},
+		{name: "omnicache_errors_total", alias: "remote", expected: 1},
+		{name: "omnicache_fallbacks_total", alias: "memory", expected: 0},
+		{name: "omnicache_evictions_total", alias: "memory", expected: 0},
+	}
+	for _, c := range counters {
+		m := metric(families[c.name], map[string]string{"store": c.alias})
+		assert.NotNil(t, m, "expected "+c.name+" for "+c.alias)
+		assert.Equal(t, c.expected, m.GetCounter().GetValue(), "expected value of "+c.name+" for "+c.alias)
+	}
+
+	entries := metric(families["omnicache_entries"], map[string]string{"store": "memory"})
+	bytes := metric(families["omnicache_bytes"], map[string]string{"store": "memory"})
+	assert.Equal(t, 2.0, entries.GetGauge().GetValue(), "expected the memory store entries")
+	assert.Equal(t, float64(len("k")+len("hello")+len("loaded")+len("v")), bytes.GetGauge().GetValue(), "expected the memory store bytes")
+	assert.True(t, metric(families["omnicache_entries"], map[string]string{"store": "remote"}) == nil, "expected no entries gauge for stores without a size")
+
+	get := metric(families["omnicache_operation_duration_seconds"], map[string]string{"store": "memory", "operation": "get"})
+	assert.NotNil(t, get, "expected a latency histogram for get")
+	assert.Equal(t, uint64(3), get.GetHistogram().GetSampleCount(), "expected every get to be observed")
+
+	buckets := get.GetHistogram().GetBucket()
+	for i := 1; i < len(buckets); i++ {
+		assert.True(t, buckets[i].GetCumulativeCount() >= buckets[i-1].GetCumulativeCount(), "expected cumulative buckets")
+	}
+}
+
+func TestCollector_config(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	m := omnicache.NewManager()
+	assert.NoError(t, m.Register("memory", omnicachemock.NewMockStore(t)))
+
+	// --- Act ---
+	families := gather(t, m, CollectorConfig{
+		Namespace:   "app_cache",
+		ConstLabels: prometheus.Labels{"service": "api"},
+	})
+
+	// --- Assert ---
+	hits := metric(families["app_cache_hits_total"], map[string]string{"store": "memory", "service": "api"})
+	assert.NotNil(t, hits, "expected the namespace and const labels to be applied")
+	assert.True(t, families["omnicache_hits_total"] == nil, "expected no metrics under the default namespace")
+}
@@ -0,0 +1,20 @@
+package prommetrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DefaultNamespace prefixes every metric name.
+const DefaultNamespace = "omnicache"
+
+type CollectorConfig struct {
+
+	// Namespace prefixes every metric name, e.g. "omnicache_hits_total".
+	//
+	// default: "omnicache"
+	Namespace string
+
+	// ConstLabels are added to every metric, e.g. to tell apart several
+	// managers registered with the same registry.
+	//
+	// default: none
+	ConstLabels prometheus.Labels
+}
@@ -0,0 +1,179 @@
+package omnicache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// Op identifies a Manager operation.
+type Op string
+
+const (
+	OpGet             Op = "get"
+	OpGetOrSet        Op = "get_or_set"
+	OpHas             Op = "has"
+	OpSet             Op = "set"
+	OpDelete          Op = "delete"
+	OpDeleteMany      Op = "delete_many"
+	OpDeleteByPattern Op = "delete_by_pattern"
+	OpClear           Op = "clear"
+	OpGetReader       Op = "get_reader"
+	OpSetFromReader   Op = "set_from_reader"
+)
+
+// Call describes a Manager operation as it passes through middleware.
+//
+// Middleware may change the input fields before calling the next handler,
+// e.g. to prefix keys, and inspect or replace Result afterwards.
+type Call struct {
+	// Op is the operation being performed.
+	Op Op
+
+	// Alias is the alias of the store the operation runs against, or ""
+	// for a Manager whose store was not selected by alias.
+	Alias string
+
+	// Keys holds the key of single-key operations and the keys of
+	// DeleteMany. It is empty for DeleteByPattern and Clear.
+	Keys []string
+
+	// Pattern is the pattern of DeleteByPattern.
+	Pattern string
+
+	// TTL is the TTL of Set, GetOrSet and SetFromReader.
+	TTL time.Duration
+
+	// Value is the value passed to Set.
+	Value any
+
+	// Load calls the loader passed to GetOrSet, which runs on a miss. It is
+	// nil for other operations. Middleware may wrap it, e.g. to time or
+	// trace loading; ctx is the context the GetOrSet call runs with.
+	Load func(ctx context.Context) (any, error)
+
+	// Result is the value returned by Get and GetOrSet, the bool returned
+	// by Has, and the io.ReadCloser returned by GetReader.
+	Result any
+
+	// Duration is the time spent in the store, excluding middleware.
+	// It is set once the innermost handler returns.
+	Duration time.Duration
+
+	// Err is the error returned by the store. It is set once the innermost
+	// handler returns; middleware see their own error as next's return value.
+	Err error
+}
+
+// Handler performs a Call.
+type Handler func(ctx context.Context, call *Call) error
+
+// Middleware wraps a Handler, e.g. to log, measure, validate or fail calls.
+type Middleware func(next Handler) Handler
+
+// Use appends middleware to the chain every operation of m passes through.
+// The first middleware is the outermost. Views returned by Store after
+// Use inherit the chain.
+//
+// GetOrSet is dispatched as a single call, and the Get and Set it performs
+// are dispatched as calls of their own.
+func (m *Manager) Use(middleware ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.middleware = append(m.middleware[:len(m.middleware):len(m.middleware)], middleware...)
+}
+
+// run passes call through the middleware chain and then to fn. It sets
+// call.Alias from the same snapshot of m the chain is taken from.
+func (m *Manager) run(ctx context.Context, call *Call, fn Handler) error {
+	m.mu.RLock()
+	middleware := m.middleware
+	alias := m.alias
+	slowThreshold := m.slowThreshold
+	m.mu.RUnlock()
+
+	call.Alias = alias
+	stats := m.statsFor(alias)
+
+	var h Handler = func(ctx context.Context, call *Call) error {
+		start := time.Now()
+		err := fn(ctx, call)
+		call.Duration = time.Since(start)
+		call.Err = err
+		if stats != nil {
+			stats.record(call.Op, len(call.Keys), call.Duration, err)
+		}
+		if slowThreshold > 0 && call.Duration >= slowThreshold {
+			m.logSlow(ctx, call.Op, call.Alias, len(call.Keys), call.Duration)
+		}
+		return err
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+
+	return h(ctx, call)
+}
+
+// fastCall is an operation of a Manager without middleware. It skips the
+// Call and the handler chain, and is passed by value, so that such
+// operations do not allocate.
+type fastCall struct {
+	m             *Manager
+	store         contract.Store
+	stats         *storeStats
+	alias         string
+	slowThreshold time.Duration
+	start         time.Time
+}
+
+// fast starts an operation that may call the store directly. It reports
+// false if m has middleware; the operation must then go through run.
+func (m *Manager) fast() (fastCall, bool) {
+	m.mu.RLock()
+	hasMiddleware := len(m.middleware) > 0
+	alias, store := m.alias, m.store
+	slowThreshold := m.slowThreshold
+	m.mu.RUnlock()
+
+	if hasMiddleware {
+		return fastCall{}, false
+	}
+
+	store, stats := m.resolve(alias, store)
+
+	return fastCall{
+		m:             m,
+		store:         store,
+		stats:         stats,
+		alias:         alias,
+		slowThreshold: slowThreshold,
+		start:         time.Now(),
+	}, true
+}
+
+// done records the outcome of the operation, as run does for calls.
+func (c fastCall) done(ctx context.Context, op Op, keys int, err error) {
+	d := time.Since(c.start)
+	if c.stats != nil {
+		c.stats.record(op, keys, d, err)
+	}
+	if c.slowThreshold > 0 && d >= c.slowThreshold {
+		c.m.logSlow(ctx, op, c.alias, keys, d)
+	}
+}
+
+// logSlow logs an operation that reached the slow threshold.
+func (m *Manager) logSlow(ctx context.Context, op Op, alias string, keys int, d time.Duration) {
+	m.log().LogAttrs(ctx, slog.LevelWarn, "omnicache: slow operation",
+		slog.String("op", string(op)),
+		slog.String("alias", alias),
+		slog.Int("keys", keys),
+		slog.Duration("duration", d),
+		slog.Duration("threshold", m.slowThreshold),
+	)
+}
@@ -0,0 +1,347 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+// recorder is a middleware that records a copy of every completed call.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) middleware(next Handler) Handler {
+	return func(ctx context.Context, call *Call) error {
+		err := next(ctx, call)
+
+		r.mu.Lock()
+		r.calls = append(r.calls, *call)
+		r.mu.Unlock()
+
+		return err
+	}
+}
+
+func (r *recorder) ops() []Op {
+	ops := make([]Op, len(r.calls))
+	for i, call := range r.calls {
+		ops[i] = call.Op
+	}
+
+	return ops
+}
+
+func TestManager_Use(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("store failed")
+
+	tests := []struct {
+		name     string
+		setup    func(ctx context.Context, store *omnicachemock.MockStore)
+		act      func(ctx context.Context, m *Manager) error
+		expected Call
+	}{
+		{
+			name: "should describe Get",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Get", ctx, "k").Return("v", nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				_, err := m.Get(ctx, "k")
+				return err
+			},
+			expected: Call{Op: OpGet, Alias: "main", Keys: []string{"k"}, Result: "v"},
+		},
+		{
+			name: "should describe Set",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Set", ctx, "k", "v", time.Minute).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				return m.Set(ctx, "k", "v", time.Minute)
+			},
+			expected: Call{Op: OpSet, Alias: "main", Keys: []string{"k"}, TTL: time.Minute, Value: "v"},
+		},
+		{
+			name: "should describe Has",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Has", ctx, "k").Return(true, nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				_, err := m.Has(ctx, "k")
+				return err
+			},
+			expected: Call{Op: OpHas, Alias: "main", Keys: []string{"k"}, Result: true},
+		},
+		{
+			name: "should describe Delete with the store error",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Delete", ctx, "k").Return(storeErr)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				return m.Delete(ctx, "k")
+			},
+			expected: Call{Op: OpDelete, Alias: "main", Keys: []string{"k"}, Err: storeErr},
+		},
+		{
+			name: "should describe DeleteMany",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				return m.DeleteMany(ctx, "a", "b")
+			},
+			expected: Call{Op: OpDeleteMany, Alias: "main", Keys: []string{"a", "b"}},
+		},
+		{
+			name: "should describe DeleteByPattern",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("DeleteByPattern", ctx, "user:*").Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				return m.DeleteByPattern(ctx, "user:*")
+			},
+			expected: Call{Op: OpDeleteByPattern, Alias: "main", Pattern: "user:*"},
+		},
+		{
+			name: "should describe Clear",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Clear", ctx).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) error {
+				return m.Clear(ctx)
+			},
+			expected: Call{Op: OpClear, Alias: "main"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := omnicachemock.NewMockStore(t)
+			tt.setup(ctx, store)
+
+			rec := &recorder{}
+			manager := NewManager()
+			assert.NoError(t, manager.Register("main", store))
+			manager.Use(rec.middleware)
+
+			// --- Act ---
+			err := tt.act(ctx, manager)
+
+			// --- Assert ---
+			store.Mock.AssertExpectations(t)
+			assert.Equal(t, tt.expected.Err, err, "error must be passed through the chain")
+			assert.Equal(t, 1, len(rec.calls), "expected exactly one call")
+
+			call := rec.calls[0]
+			assert.True(t, call.Duration > 0, "expected the store duration to be recorded")
+			call.Duration = 0
+			assert.Equal(t, tt.expected, call, "call descriptor must match")
+		})
+	}
+}
+
+func TestManager_UseOrder(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := omnicachemock.NewMockStore(t)
+	store.Mock.On("Get", ctx, "k").Return("v", nil)
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) error {
+				order = append(order, name+":before")
+				err := next(ctx, call)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+
+	manager := &Manager{store: store}
+	manager.Use(trace("outer"), trace("middle"))
+	manager.Use(trace("inner"))
+
+	// --- Act ---
+	_, err := manager.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"outer:before", "middle:before", "inner:before",
+		"inner:after", "middle:after", "outer:after",
+	}, order, "middleware must run in registration order, first outermost")
+}
+
+func TestManager_UseRewriteAndShortCircuit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should let middleware rewrite keys", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := omnicachemock.NewMockStore(t)
+		store.Mock.On("Set", ctx, "tenant:k", "v", time.Duration(0)).Return(nil)
+
+		manager := &Manager{store: store}
+		manager.Use(func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) error {
+				for i, key := range call.Keys {
+					call.Keys[i] = "tenant:" + key
+				}
+				return next(ctx, call)
+			}
+		})
+
+		// --- Act ---
+		err := manager.Set(ctx, "k", "v", 0)
+
+		// --- Assert ---
+		assert.NoError(t, err)
+		store.Mock.AssertExpectations(t)
+	})
+
+	t.Run("should let middleware fail calls without reaching the store", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		store := omnicachemock.NewMockStore(t)
+		errInvalidKey := errors.New("invalid key")
+
+		manager := &Manager{store: store}
+		manager.Use(func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) error {
+				for _, key := range call.Keys {
+					if strings.Contains(key, " ") {
+						return errInvalidKey
+					}
+				}
+				return next(ctx, call)
+			}
+		})
+
+		// --- Act ---
+		value, err := manager.Get(ctx, "bad key")
+
+		// --- Assert ---
+		assert.EqualError(t, errInvalidKey, err, "expected the middleware error")
+		assert.Nil(t, value, "expected no value")
+		store.Mock.AssertNotCalled(t, "Get", ctx, "bad key")
+	})
+}
+
+func TestManager_UseGetOrSet(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := omnicachemock.NewMockStore(t)
+	store.Mock.On("Get", ctx, "k").Return(nil, ErrCacheMiss)
+	store.Mock.On("Set", ctx, "k", 42, time.Minute).Return(nil)
+
+	rec := &recorder{}
+	manager := &Manager{store: store}
+	manager.Use(rec.middleware)
+
+	// --- Act ---
+	value, err := G[int](manager).GetOrSet(ctx, "k", time.Minute, func() (int, error) { return 42, nil })
+
+	// --- Assert ---
+	assert.NoError(t, err)
+	assert.Equal(t, 42, value)
+	assert.Equal(t, []Op{OpGet, OpSet, OpGetOrSet}, rec.ops(), "expected nested Get and Set inside GetOrSet")
+	assert.Equal(t, ErrCacheMiss, rec.calls[0].Err, "expected the miss on the nested Get")
+	assert.Equal(t, 42, rec.calls[2].Result, "expected the loaded value as the GetOrSet result")
+}
+
+func TestManager_UseLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		loaded        any
+		expectedValue any
+		expectedErr   error
+	}{
+		{name: "should store the value returned by a wrapped loader", loaded: 43, expectedValue: 43},
+		{name: "should return ErrTypeMismatch when a wrapped loader changes the type", loaded: "x", expectedValue: 0, expectedErr: ErrTypeMismatch},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := omnicachemock.NewMockStore(t)
+			store.Mock.On("Get", ctx, "k").Return(nil, ErrCacheMiss)
+			store.Mock.On("Set", ctx, "k", 43, time.Minute).Return(nil)
+
+			var original any
+			manager := &Manager{store: store}
+			manager.Use(func(next Handler) Handler {
+				return func(ctx context.Context, call *Call) error {
+					load := call.Load
+					call.Load = func(ctx context.Context) (any, error) {
+						original, _ = load(ctx)
+						return tt.loaded, nil
+					}
+					return next(ctx, call)
+				}
+			})
+
+			// --- Act ---
+			value, err := G[int](manager).GetOrSet(ctx, "k", time.Minute, func() (int, error) { return 42, nil })
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedErr, err, "error must match")
+			assert.Equal(t, tt.expectedValue, value, "value must match")
+			assert.Equal(t, 42, original, "expected the wrapper to call the original loader")
+		})
+	}
+}
+
+func TestManager_UseStoreView(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	main := omnicachemock.NewMockStore(t)
+	other := omnicachemock.NewMockStore(t)
+	other.Mock.On("Delete", ctx, "k").Return(nil)
+
+	rec := &recorder{}
+	manager := NewManager()
+	assert.NoError(t, manager.Register("main", main))
+	assert.NoError(t, manager.Register("other", other))
+	manager.Use(rec.middleware)
+
+	// --- Act ---
+	err := manager.Store("other").Delete(ctx, "k")
+
+	// --- Assert ---
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(rec.calls), "expected the view to inherit the middleware")
+	assert.Equal(t, "other", rec.calls[0].Alias, "expected the alias of the view")
+}
@@ -0,0 +1,34 @@
+package omnicachemock
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// MockStreamStore is a MockStore that also implements contract.Streamer.
+type MockStreamStore struct {
+	*MockStore
+}
+
+func NewMockStreamStore(t *testing.T) *MockStreamStore {
+	return &MockStreamStore{MockStore: NewMockStore(t)}
+}
+
+func (m *MockStreamStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Mock.Called("GetReader", ctx, key)
+	if len(args) >= 2 {
+		reader, _ := args[0].(io.ReadCloser)
+		return reader, asError(args[1])
+	}
+	return nil, nil
+}
+
+func (m *MockStreamStore) SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error {
+	args := m.Mock.Called("SetFromReader", ctx, key, r, ttl)
+	if len(args) == 0 {
+		return nil
+	}
+	return asError(args[0])
+}
@@ -0,0 +1,99 @@
+package omnicache
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+)
+
+// Option configures a Manager created with NewManager.
+type Option func(*Manager)
+
+// WithCodec sets the codec used to decode string, []byte and codec.Raw
+// values read from stores into typed results (see GenericManager).
+// Payloads carrying another codec's header are still decoded with that codec.
+//
+// default: codec.Default
+func WithCodec(c codec.Codec) Option {
+	return func(m *Manager) {
+		if c != nil {
+			m.codec = c
+		}
+	}
+}
+
+// WithLogger sets the logger warnings and slow operations are logged to.
+// Use a logger with a discarding handler to silence them.
+//
+// default: slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		if logger != nil {
+			m.logger = logger
+		}
+	}
+}
+
+// WithSlowThreshold logs a warning for every operation whose store
+// latency, excluding middleware, reaches threshold. Keys are not logged.
+//
+// default: 0 (disabled)
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(m *Manager) {
+		m.slowThreshold = threshold
+	}
+}
+
+// WithHealthTimeout sets how long Health waits for each store to answer
+// before reporting it down.
+//
+// default: 2 seconds
+func WithHealthTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		if timeout > 0 {
+			m.healthTimeout = timeout
+		}
+	}
+}
+
+// WithFailOpen makes GetOrSet treat store failures as misses: when the
+// lookup fails, the loader is called anyway, and when storing the loaded
+// value fails, the value is returned without error. The failures are
+// still counted in Stats and seen by middleware. Errors caused by the
+// caller, such as an invalid TTL or a context that is done, are returned
+// as usual.
+//
+// default: false
+func WithFailOpen(enabled bool) Option {
+	return func(m *Manager) {
+		m.failOpen = enabled
+	}
+}
+
+// UnknownAliasMode selects what Manager.Store does when an alias has not
+// been registered.
+type UnknownAliasMode int
+
+const (
+	// UnknownAliasFallback logs a warning and returns the Manager itself,
+	// bound to its current store.
+	UnknownAliasFallback UnknownAliasMode = iota
+
+	// UnknownAliasPanic panics with an error wrapping ErrInvalidStore.
+	UnknownAliasPanic
+
+	// UnknownAliasError returns a Manager whose operations fail with an
+	// error wrapping ErrInvalidStore.
+	UnknownAliasError
+)
+
+// WithUnknownAlias sets what Store does when an alias has not been
+// registered. StoreE and MustStore are not affected.
+//
+// default: UnknownAliasFallback
+func WithUnknownAlias(mode UnknownAliasMode) Option {
+	return func(m *Manager) {
+		m.unknownAlias = mode
+	}
+}
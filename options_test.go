@@ -0,0 +1,273 @@
+package omnicache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+func TestOptions_WithCodec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected codec.Codec
+	}{
+		{
+			name:     "should use codec.Default when no codec is given",
+			expected: codec.Default,
+		},
+		{
+			name:     "should use the given codec",
+			opts:     []Option{WithCodec(codec.MsgPack)},
+			expected: codec.MsgPack,
+		},
+		{
+			name:     "should ignore a nil codec",
+			opts:     []Option{WithCodec(nil)},
+			expected: codec.Default,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			manager := NewManager(tt.opts...)
+
+			// --- Assert ---
+			assert.Equal(t, tt.expected, manager.codec, "manager codec must match")
+		})
+	}
+}
+
+func TestOptions_WithCodecDecodesStoredPayloads(t *testing.T) {
+	t.Parallel()
+
+	type profile struct {
+		Name string
+		Age  int
+	}
+
+	cbor, err := codec.Encode(codec.CBOR, profile{Name: "ada", Age: 36})
+	assert.NoError(t, err)
+
+	headerless, err := codec.MsgPack.Marshal(profile{Name: "bob", Age: 41})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		stored   any
+		expected profile
+	}{
+		{
+			name:     "should decode codec.Raw with the codec named in its header",
+			stored:   codec.Raw(cbor),
+			expected: profile{Name: "ada", Age: 36},
+		},
+		{
+			name:     "should decode headerless bytes with the manager codec",
+			stored:   headerless,
+			expected: profile{Name: "bob", Age: 41},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			mockStore := omnicachemock.NewMockStore(t)
+			mockStore.Mock.On("Get", ctx, "key").Return(tt.stored, nil)
+
+			manager := NewManager(WithCodec(codec.MsgPack))
+			assert.NoError(t, manager.Register("mock", mockStore))
+
+			// --- Act ---
+			result, err := G[profile](manager.Store("mock")).Get(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when decoding stored payload")
+			assert.Equal(t, tt.expected, result, "decoded value must match")
+		})
+	}
+}
+
+func TestOptions_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	manager := NewManager(WithLogger(logger))
+	assert.NoError(t, manager.Register("main", omnicachemock.NewMockStore(t)))
+
+	// --- Act ---
+	view := manager.Store("missing")
+
+	// --- Assert ---
+	assert.True(t, view == manager, "expected the default store to be used")
+	assert.True(t, strings.Contains(buf.String(), "store alias not found"), "expected the warning to be logged")
+	assert.True(t, strings.Contains(buf.String(), "alias=missing"), "expected the alias to be logged")
+}
+
+// delayingStore is a store whose Delete takes at least delay.
+type delayingStore struct {
+	contract.Store
+	delay time.Duration
+}
+
+func (s delayingStore) Delete(ctx context.Context, key string) error {
+	time.Sleep(s.delay)
+	return s.Store.Delete(ctx, key)
+}
+
+func TestOptions_WithSlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		delay     time.Duration
+		expected  bool
+	}{
+		{name: "should not log when disabled", threshold: 0, delay: 5 * time.Millisecond, expected: false},
+		{name: "should not log operations below the threshold", threshold: time.Hour, delay: 0, expected: false},
+		{name: "should log operations reaching the threshold", threshold: time.Millisecond, delay: 5 * time.Millisecond, expected: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := omnicachemock.NewMockStore(t)
+			store.Mock.On("Delete", ctx, "user:1").Return(nil)
+
+			var buf bytes.Buffer
+			manager := NewManager(WithLogger(slog.New(slog.NewTextHandler(&buf, nil))), WithSlowThreshold(tt.threshold))
+			assert.NoError(t, manager.Register("main", delayingStore{Store: store, delay: tt.delay}))
+
+			// --- Act ---
+			err := manager.Delete(ctx, "user:1")
+
+			// --- Assert ---
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.Contains(buf.String(), "slow operation"), "slow operation log mismatch")
+			assert.False(t, strings.Contains(buf.String(), "user:1"), "expected keys not to be logged")
+		})
+	}
+}
+
+func TestOptions_WithHealthTimeout(t *testing.T) {
+	t.Parallel()
+
+	// --- Act ---
+	defaulted := NewManager(WithHealthTimeout(0))
+	custom := NewManager(WithHealthTimeout(time.Second))
+
+	// --- Assert ---
+	assert.Equal(t, DefaultHealthTimeout, defaulted.healthTimeout, "expected the default timeout for non-positive values")
+	assert.Equal(t, time.Second, custom.healthTimeout, "expected the custom timeout")
+}
+
+func TestOptions_WithFailOpen(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("dial tcp: connection refused")
+
+	tests := []struct {
+		name        string
+		failOpen    bool
+		getErr      error
+		setErr      error
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "should load the value when the lookup fails",
+			failOpen: true,
+			getErr:   storeErr,
+			expected: "loaded",
+		},
+		{
+			name:     "should return the loaded value when storing it fails",
+			failOpen: true,
+			getErr:   ErrCacheMiss,
+			setErr:   storeErr,
+			expected: "loaded",
+		},
+		{
+			name:        "should return errors caused by the caller",
+			failOpen:    true,
+			getErr:      ErrCacheMiss,
+			setErr:      ErrInvalidValue,
+			expected:    "loaded",
+			expectedErr: ErrInvalidValue,
+		},
+		{
+			name:        "should return lookup failures by default",
+			getErr:      storeErr,
+			expectedErr: storeErr,
+		},
+		{
+			name:        "should return store failures by default",
+			getErr:      ErrCacheMiss,
+			setErr:      storeErr,
+			expected:    "loaded",
+			expectedErr: storeErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			mockStore := omnicachemock.NewMockStore(t)
+			mockStore.Mock.On("Get", ctx, "k").Return(nil, tt.getErr)
+			mockStore.Mock.On("Set", ctx, "k", "loaded", time.Minute).Return(tt.setErr)
+
+			m := NewManager(WithFailOpen(tt.failOpen))
+			assert.NoError(t, m.Register("main", mockStore))
+
+			load := func() (string, error) { return "loaded", nil }
+
+			// --- Act ---
+			value, err := m.GetOrSet(ctx, "k", time.Minute, func() (any, error) { return load() })
+			typed, typedErr := G[string](m).GetOrSet(ctx, "k", time.Minute, load)
+
+			// --- Assert ---
+			for _, err := range []error{err, typedErr} {
+				if tt.expectedErr != nil {
+					assert.True(t, errors.Is(err, tt.expectedErr), "expected the error to be returned")
+				} else {
+					assert.NoError(t, err, "expected no error")
+				}
+			}
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, value, "expected the loaded value")
+			}
+			assert.Equal(t, tt.expected, typed, "expected the loaded value from G")
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package omnicache
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets.
+// Durations above the last bound fall into an overflow bucket.
+var latencyBounds = [...]time.Duration{
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+}
+
+// Stats is a snapshot of the counters Manager collects for a store.
+type Stats struct {
+	// Hits and Misses count Get and GetReader calls, including those made
+	// by GetOrSet, that found or did not find the key.
+	Hits   uint64
+	Misses uint64
+
+	// Sets counts Set and SetFromReader calls that succeeded.
+	Sets uint64
+
+	// Deletes counts keys passed to Delete and DeleteMany, and
+	// DeleteByPattern and Clear calls, that succeeded.
+	Deletes uint64
+
+	// Errors counts store operations that failed, not counting misses.
+	Errors uint64
+
+	// LoaderCalls and LoaderErrors count GetOrSet loader invocations and
+	// the ones that returned an error.
+	LoaderCalls  uint64
+	LoaderErrors uint64
+
+	// Fallbacks counts operations served by the fallback store because
+	// the store failed (see Manager.SetFallback). They are not counted
+	// as Errors.
+	Fallbacks uint64
+
+	// Evictions counts entries the store evicted before they expired.
+	// It is 0 for stores that do not implement contract.EvictionCounter.
+	Evictions uint64
+
+	// Entries and Bytes are the number of entries in the store and their
+	// approximate size, as reported by the store when the snapshot is
+	// taken. They are -1 for stores that do not implement contract.Sizer.
+	Entries int64
+	Bytes   int64
+
+	// Latency holds the store latency of each operation, excluding
+	// middleware. Operations that were never called are omitted.
+	Latency map[Op]LatencyHistogram
+}
+
+// HitRatio returns Hits / (Hits + Misses), or 0 if there were no lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// LatencyHistogram is a snapshot of the latency of an operation.
+type LatencyHistogram struct {
+	// Count is the number of calls and Sum their total duration.
+	Count uint64
+	Sum   time.Duration
+
+	// Bounds are the inclusive upper bounds of the buckets, in ascending order.
+	Bounds []time.Duration
+
+	// Buckets holds the number of calls per bucket, not cumulative.
+	// It has one more element than Bounds, counting calls slower than
+	// the last bound.
+	Buckets []uint64
+}
+
+// Mean returns the average duration, or 0 if there were no calls.
+func (h LatencyHistogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+
+	return h.Sum / time.Duration(h.Count)
+}
+
+// storeStats holds the counters of one store alias. The counters are
+// accessed atomically; the statsSource is guarded by the mutex of the
+// Manager owning the stores.
+type storeStats struct {
+	statsSource
+
+	hits, misses, sets, deletes, errs uint64
+	loaderCalls, loaderErrors         uint64
+	fallbacks                         uint64
+
+	latency [len(opIndexes)]histogram
+}
+
+// statsSource is the store a storeStats reports the size and evictions of.
+type statsSource struct {
+	store contract.Store
+
+	evictionsBase     uint64 // evictions reported by store when counting started
+	evictionsReplaced uint64 // evictions counted on stores replaced since
+}
+
+// histogram has no count of its own: snapshot sums the buckets, so
+// Count always matches Buckets.
+type histogram struct {
+	sum     int64
+	buckets [len(latencyBounds) + 1]uint64
+}
+
+// opIndexes maps an Op to its histogram in storeStats.latency.
+var opIndexes = [...]Op{
+	OpGet, OpGetOrSet, OpHas, OpSet, OpDelete, OpDeleteMany,
+	OpDeleteByPattern, OpClear, OpGetReader, OpSetFromReader,
+}
+
+func newStoreStats(store contract.Store) *storeStats {
+	return &storeStats{statsSource: statsSource{store: store, evictionsBase: evictions(store)}}
+}
+
+// record updates the counters for a completed operation on keys keys.
+func (s *storeStats) record(op Op, keys int, d time.Duration, err error) {
+	if i := opIndex(op); i >= 0 {
+		s.latency[i].observe(d)
+	}
+
+	switch {
+	case (op == OpGet || op == OpGetReader) && errors.Is(err, ErrCacheMiss):
+		atomic.AddUint64(&s.misses, 1)
+		return
+	case err != nil:
+		if op != OpGetOrSet {
+			atomic.AddUint64(&s.errs, 1)
+		}
+		return
+	}
+
+	switch op {
+	case OpGet, OpGetReader:
+		atomic.AddUint64(&s.hits, 1)
+	case OpSet, OpSetFromReader:
+		atomic.AddUint64(&s.sets, 1)
+	case OpDelete, OpDeleteMany:
+		atomic.AddUint64(&s.deletes, uint64(keys))
+	case OpDeleteByPattern, OpClear:
+		atomic.AddUint64(&s.deletes, 1)
+	}
+}
+
+// recordLoad counts a GetOrSet loader invocation.
+func (s *storeStats) recordLoad(err error) {
+	atomic.AddUint64(&s.loaderCalls, 1)
+	if err != nil {
+		atomic.AddUint64(&s.loaderErrors, 1)
+	}
+}
+
+// snapshot returns the counters, and the size and evictions of the store
+// of src, a copy of s.statsSource taken under the mutex of the Manager.
+func (s *storeStats) snapshot(src statsSource) Stats {
+	stats := Stats{
+		Hits:         atomic.LoadUint64(&s.hits),
+		Misses:       atomic.LoadUint64(&s.misses),
+		Sets:         atomic.LoadUint64(&s.sets),
+		Deletes:      atomic.LoadUint64(&s.deletes),
+		Errors:       atomic.LoadUint64(&s.errs),
+		LoaderCalls:  atomic.LoadUint64(&s.loaderCalls),
+		LoaderErrors: atomic.LoadUint64(&s.loaderErrors),
+		Fallbacks:    atomic.LoadUint64(&s.fallbacks),
+		Latency:      make(map[Op]LatencyHistogram),
+	}
+
+	stats.Entries, stats.Bytes = -1, -1
+	if sizer, ok := src.store.(contract.Sizer); ok {
+		stats.Entries, stats.Bytes = sizer.Size()
+	}
+
+	stats.Evictions = src.evictionsReplaced + src.evictionsSinceBase()
+
+	for i := range s.latency {
+		h := &s.latency[i]
+
+		var count uint64
+		buckets := make([]uint64, len(h.buckets))
+		for j := range h.buckets {
+			buckets[j] = atomic.LoadUint64(&h.buckets[j])
+			count += buckets[j]
+		}
+		if count == 0 {
+			continue
+		}
+
+		stats.Latency[opIndexes[i]] = LatencyHistogram{
+			Count:   count,
+			Sum:     time.Duration(atomic.LoadInt64(&h.sum)),
+			Bounds:  append([]time.Duration(nil), latencyBounds[:]...),
+			Buckets: buckets,
+		}
+	}
+
+	return stats
+}
+
+func (s *storeStats) reset() {
+	for _, p := range []*uint64{&s.hits, &s.misses, &s.sets, &s.deletes, &s.errs, &s.loaderCalls, &s.loaderErrors, &s.fallbacks} {
+		atomic.StoreUint64(p, 0)
+	}
+
+	s.evictionsBase = evictions(s.store)
+	s.evictionsReplaced = 0
+
+	for i := range s.latency {
+		h := &s.latency[i]
+		atomic.StoreInt64(&h.sum, 0)
+		for j := range h.buckets {
+			atomic.StoreUint64(&h.buckets[j], 0)
+		}
+	}
+}
+
+// replace switches the store evictions are read from, keeping the
+// evictions counted so far.
+func (s *storeStats) replace(store contract.Store) {
+	s.evictionsReplaced += s.evictionsSinceBase()
+	s.store = store
+	s.evictionsBase = evictions(store)
+}
+
+func (s statsSource) evictionsSinceBase() uint64 {
+	if n := evictions(s.store); n > s.evictionsBase {
+		return n - s.evictionsBase
+	}
+
+	return 0
+}
+
+func (h *histogram) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+
+	atomic.AddUint64(&h.buckets[i], 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+func opIndex(op Op) int {
+	for i, o := range opIndexes {
+		if o == op {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func evictions(store contract.Store) uint64 {
+	if c, ok := store.(contract.EvictionCounter); ok {
+		return c.Evictions()
+	}
+
+	return 0
+}
+
+// recordLoad counts a GetOrSet loader invocation against the current store.
+func (m *Manager) recordLoad(err error) {
+	m.mu.RLock()
+	alias := m.alias
+	m.mu.RUnlock()
+
+	if stats := m.statsFor(alias); stats != nil {
+		stats.recordLoad(err)
+	}
+}
+
+// Stats returns a snapshot of the counters of every registered store,
+// keyed by alias.
+func (m *Manager) Stats() map[string]Stats {
+	type entry struct {
+		stats *storeStats
+		src   statsSource
+	}
+
+	// Stores are asked for their size and evictions without holding the
+	// lock, so that slow stores do not block Register, Replace and the like.
+	reg := m.registry()
+	reg.mu.RLock()
+	entries := make(map[string]entry, len(reg.statsByAlias))
+	for alias, s := range reg.statsByAlias {
+		entries[alias] = entry{stats: s, src: s.statsSource}
+	}
+	reg.mu.RUnlock()
+
+	stats := make(map[string]Stats, len(entries))
+	for alias, e := range entries {
+		stats[alias] = e.stats.snapshot(e.src)
+	}
+
+	return stats
+}
+
+// ResetStats zeroes the counters of every registered store.
+// Counters are reset one by one, so calls running concurrently may be
+// partially counted.
+func (m *Manager) ResetStats() {
+	reg := m.registry()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for _, s := range reg.statsByAlias {
+		s.reset()
+	}
+}
@@ -0,0 +1,309 @@
+package omnicache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache/internal/assert"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+// sizedStore is a MockStore that reports a settable eviction count and a fixed size.
+type sizedStore struct {
+	*omnicachemock.MockStore
+	evictions uint64
+}
+
+func (s *sizedStore) Evictions() uint64 {
+	return atomic.LoadUint64(&s.evictions)
+}
+
+func (s *sizedStore) Size() (entries int64, bytes int64) {
+	return 3, 42
+}
+
+func TestManager_Stats(t *testing.T) {
+	t.Parallel()
+
+	storeErr := errors.New("store failed")
+	loaderErr := errors.New("loader failed")
+
+	tests := []struct {
+		name     string
+		setup    func(ctx context.Context, store *omnicachemock.MockStore)
+		act      func(ctx context.Context, m *Manager)
+		expected Stats
+		ops      []Op
+	}{
+		{
+			name: "should count hits and misses",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Get", ctx, "hit").Return("v", nil)
+				store.Mock.On("Get", ctx, "miss").Return(nil, ErrCacheMiss)
+			},
+			act: func(ctx context.Context, m *Manager) {
+				_, _ = m.Get(ctx, "hit")
+				_, _ = m.Get(ctx, "hit")
+				_, _ = m.Get(ctx, "miss")
+			},
+			expected: Stats{Hits: 2, Misses: 1, Entries: -1, Bytes: -1},
+			ops:      []Op{OpGet},
+		},
+		{
+			name: "should count errors separately from misses",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Get", ctx, "k").Return(nil, storeErr)
+				store.Mock.On("Set", ctx, "k", "v", time.Minute).Return(storeErr)
+			},
+			act: func(ctx context.Context, m *Manager) {
+				_, _ = m.Get(ctx, "k")
+				_ = m.Set(ctx, "k", "v", time.Minute)
+			},
+			expected: Stats{Errors: 2, Entries: -1, Bytes: -1},
+			ops:      []Op{OpGet, OpSet},
+		},
+		{
+			name: "should count sets and deleted keys",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Set", ctx, "k", "v", time.Minute).Return(nil)
+				store.Mock.On("Delete", ctx, "k").Return(nil)
+				store.Mock.On("DeleteMany", ctx, []string{"a", "b", "c"}).Return(nil)
+				store.Mock.On("DeleteByPattern", ctx, "user:*").Return(nil)
+				store.Mock.On("Clear", ctx).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) {
+				_ = m.Set(ctx, "k", "v", time.Minute)
+				_ = m.Delete(ctx, "k")
+				_ = m.DeleteMany(ctx, "a", "b", "c")
+				_ = m.DeleteByPattern(ctx, "user:*")
+				_ = m.Clear(ctx)
+			},
+			expected: Stats{Sets: 1, Deletes: 6, Entries: -1, Bytes: -1},
+			ops:      []Op{OpSet, OpDelete, OpDeleteMany, OpDeleteByPattern, OpClear},
+		},
+		{
+			name: "should count loader calls and loader errors",
+			setup: func(ctx context.Context, store *omnicachemock.MockStore) {
+				store.Mock.On("Get", ctx, "ok").Return(nil, ErrCacheMiss)
+				store.Mock.On("Get", ctx, "bad").Return(nil, ErrCacheMiss)
+				store.Mock.On("Set", ctx, "ok", "loaded", time.Minute).Return(nil)
+			},
+			act: func(ctx context.Context, m *Manager) {
+				_, _ = m.GetOrSet(ctx, "ok", time.Minute, func() (any, error) { return "loaded", nil })
+				_, _ = m.GetOrSet(ctx, "bad", time.Minute, func() (any, error) { return nil, loaderErr })
+			},
+			expected: Stats{Misses: 2, Sets: 1, LoaderCalls: 2, LoaderErrors: 1, Entries: -1, Bytes: -1},
+			ops:      []Op{OpGet, OpSet, OpGetOrSet},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			store := omnicachemock.NewMockStore(t)
+			tt.setup(ctx, store)
+
+			m := NewManager()
+			assert.NoError(t, m.Register("primary", store))
+
+			// --- Act ---
+			tt.act(ctx, m)
+			stats := m.Stats()["primary"]
+
+			// --- Assert ---
+			latency := stats.Latency
+			stats.Latency = nil
+			assert.Equal(t, tt.expected, stats, "expected counters to match")
+			assert.Equal(t, len(tt.ops), len(latency), "expected latency for the called operations only")
+			for _, op := range tt.ops {
+				h, ok := latency[op]
+				assert.True(t, ok, "expected latency for "+string(op))
+				assert.Equal(t, len(h.Bounds)+1, len(h.Buckets), "expected an overflow bucket")
+
+				var total uint64
+				for _, n := range h.Buckets {
+					total += n
+				}
+				assert.Equal(t, h.Count, total, "expected buckets to add up to the count")
+			}
+		})
+	}
+}
+
+func TestManager_Stats_perAlias(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	primary := omnicachemock.NewMockStore(t)
+	secondary := omnicachemock.NewMockStore(t)
+	primary.Mock.On("Get", ctx, "k").Return("v", nil)
+	secondary.Mock.On("Get", ctx, "k").Return(nil, ErrCacheMiss)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("primary", primary))
+	assert.NoError(t, m.Register("secondary", secondary))
+
+	// --- Act ---
+	_, _ = m.Get(ctx, "k")
+	_, _ = m.Store("secondary").Get(ctx, "k")
+	_, _ = G[string](m.Store("secondary")).Get(ctx, "k")
+	stats := m.Stats()
+
+	// --- Assert ---
+	assert.Equal(t, 2, len(stats), "expected stats for every registered alias")
+	assert.Equal(t, uint64(1), stats["primary"].Hits, "expected the hit on the default store")
+	assert.Equal(t, uint64(2), stats["secondary"].Misses, "expected view misses to be counted under their alias")
+	assert.Equal(t, 1.0, stats["primary"].HitRatio(), "expected hit ratio of 1")
+	assert.Equal(t, 0.0, stats["secondary"].HitRatio(), "expected hit ratio of 0")
+}
+
+func TestManager_ResetStats(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	store := &sizedStore{MockStore: omnicachemock.NewMockStore(t), evictions: 5}
+	store.Mock.On("Get", ctx, "k").Return("v", nil)
+
+	m := NewManager()
+	assert.NoError(t, m.Register("primary", store))
+	_, _ = m.Get(ctx, "k")
+	atomic.AddUint64(&store.evictions, 3)
+
+	before := m.Stats()["primary"]
+
+	// --- Act ---
+	m.ResetStats()
+	after := m.Stats()["primary"]
+	atomic.AddUint64(&store.evictions, 2)
+	later := m.Stats()["primary"]
+
+	// --- Assert ---
+	assert.Equal(t, uint64(1), before.Hits, "expected the hit before reset")
+	assert.Equal(t, uint64(3), before.Evictions, "expected evictions since registration")
+	assert.Equal(t, int64(3), before.Entries, "expected entries reported by the store")
+	assert.Equal(t, int64(42), before.Bytes, "expected bytes reported by the store")
+	assert.Equal(t, uint64(0), after.Hits, "expected hits to be reset")
+	assert.Equal(t, 0, len(after.Latency), "expected latency to be reset")
+	assert.Equal(t, uint64(0), after.Evictions, "expected evictions to be reset")
+	assert.Equal(t, uint64(2), later.Evictions, "expected evictions since reset")
+}
+
+// blockingSizedStore is a MockStore whose Size blocks until release is
+// closed, signaling sizing once it is entered.
+type blockingSizedStore struct {
+	*omnicachemock.MockStore
+	sizing  chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSizedStore) Size() (entries int64, bytes int64) {
+	close(s.sizing)
+	<-s.release
+
+	return 0, 0
+}
+
+func TestManager_Stats_sizesWithoutLock(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	store := &blockingSizedStore{
+		MockStore: omnicachemock.NewMockStore(t),
+		sizing:    make(chan struct{}),
+		release:   make(chan struct{}),
+	}
+
+	m := NewManager()
+	assert.NoError(t, m.Register("slow", store))
+
+	statsDone := make(chan struct{})
+	go func() {
+		defer close(statsDone)
+		m.Stats()
+	}()
+	<-store.sizing
+
+	// --- Act ---
+	registered := make(chan error, 1)
+	go func() {
+		registered <- m.Register("other", omnicachemock.NewMockStore(t))
+	}()
+
+	// --- Assert ---
+	select {
+	case err := <-registered:
+		assert.NoError(t, err, "expected no error when registering")
+	case <-time.After(time.Second):
+		t.Error("expected Register not to wait for the store to report its size")
+	}
+
+	close(store.release)
+	<-statsDone
+}
+
+func TestManager_Stats_latencyConsistent(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	m := NewManager()
+	assert.NoError(t, m.Register("main", valueStore{}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_, _ = m.Get(context.Background(), "key")
+		}
+	}()
+
+	// --- Act & Assert ---
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		h := m.Stats()["main"].Latency[OpGet]
+		var total uint64
+		for _, n := range h.Buckets {
+			total += n
+		}
+		assert.Equal(t, h.Count, total, "expected Count to match the sum of the buckets")
+	}
+}
+
+func TestLatencyHistogram_Mean(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		histogram LatencyHistogram
+		expected  time.Duration
+	}{
+		{name: "should return 0 without calls", histogram: LatencyHistogram{}, expected: 0},
+		{name: "should return the average", histogram: LatencyHistogram{Count: 4, Sum: 10 * time.Millisecond}, expected: 2500 * time.Microsecond},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			result := tt.histogram.Mean()
+
+			// --- Assert ---
+			assert.Equal(t, tt.expected, result, "expected mean to match")
+		})
+	}
+}
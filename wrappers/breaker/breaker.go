@@ -0,0 +1,361 @@
+package breakerstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/stream"
+)
+
+// State is the state of a circuit.
+type State int
+
+const (
+	// Closed lets every operation through.
+	Closed State = iota
+
+	// Open fails every operation with ErrCircuitOpen, or serves it from
+	// the fallback, until the cool-down has passed.
+	Open
+
+	// HalfOpen lets a limited number of trial operations through to find
+	// out whether the store has recovered.
+	HalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// StateChange describes a transition of a circuit.
+type StateChange struct {
+	// Name is BreakerConfig.Name.
+	Name string
+
+	From State
+	To   State
+
+	// Err is the failure that opened the circuit; nil for other transitions.
+	Err error
+
+	// At is when the transition happened.
+	At time.Time
+}
+
+// BreakerStore wraps a contract.Store with a circuit breaker, so that a
+// degraded backend fails fast instead of adding its timeouts to every
+// operation.
+//
+// The circuit starts closed. After FailureThreshold consecutive failures
+// it opens, and operations fail with ErrCircuitOpen, or are served by the
+// fallback, without reaching the store (see BreakerConfig.Fallback for
+// what the store misses meanwhile). Once the cool-down has passed, the
+// circuit turns half-open and lets HalfOpenRequests trial operations
+// through: it closes if they all succeed and opens again if one fails.
+//
+// Misses and errors caused by the caller are not failures (see
+// omnicache.IsStoreFailure). Operations whose context is done before the
+// store fails are not counted either way.
+type BreakerStore struct {
+	inner            contract.Store
+	fallback         contract.Store
+	name             string
+	failureThreshold int
+	coolDown         time.Duration
+	halfOpenRequests int
+	isFailure        func(ctx context.Context, err error) bool
+	onStateChange    func(StateChange)
+	now              func() time.Time
+
+	mu         sync.Mutex
+	state      State
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	trials     int
+	successes  int
+}
+
+// NewBreakerStore creates a BreakerStore wrapping inner.
+//
+// The returned store is a *BreakerStore.
+// Returns ErrInvalidConfig if inner is nil or a threshold or the cool-down
+// is negative.
+func NewBreakerStore(inner contract.Store, config BreakerConfig) (contract.Store, error) {
+	if inner == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if config.FailureThreshold < 0 || config.HalfOpenRequests < 0 || config.CoolDown < 0 {
+		return nil, fmt.Errorf("%w: breaker: negative threshold or cool-down", omnicache.ErrInvalidConfig)
+	}
+
+	if config.FailureThreshold == 0 {
+		config.FailureThreshold = DefaultFailureThreshold
+	}
+
+	if config.CoolDown == 0 {
+		config.CoolDown = DefaultCoolDown
+	}
+
+	if config.HalfOpenRequests == 0 {
+		config.HalfOpenRequests = DefaultHalfOpenRequests
+	}
+
+	if config.IsFailure == nil {
+		config.IsFailure = omnicache.IsStoreFailure
+	}
+
+	return &BreakerStore{
+		inner:            inner,
+		fallback:         config.Fallback,
+		name:             config.Name,
+		failureThreshold: config.FailureThreshold,
+		coolDown:         config.CoolDown,
+		halfOpenRequests: config.HalfOpenRequests,
+		isFailure:        config.IsFailure,
+		onStateChange:    config.OnStateChange,
+		now:              time.Now,
+	}, nil
+}
+
+// State returns the current state of the circuit. An open circuit whose
+// cool-down has passed is reported half-open.
+func (b *BreakerStore) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.coolDown {
+		return HalfOpen
+	}
+
+	return b.state
+}
+
+func (b *BreakerStore) Clear(ctx context.Context) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return store.Clear(ctx)
+	})
+}
+
+// Close closes the wrapped store, whatever the state of the circuit.
+func (b *BreakerStore) Close(ctx context.Context) error {
+	return b.inner.Close(ctx)
+}
+
+func (b *BreakerStore) Delete(ctx context.Context, key string) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return store.Delete(ctx, key)
+	})
+}
+
+func (b *BreakerStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return store.DeleteByPattern(ctx, pattern)
+	})
+}
+
+func (b *BreakerStore) DeleteMany(ctx context.Context, keys ...string) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return store.DeleteMany(ctx, keys...)
+	})
+}
+
+// Evictions returns the evictions of the wrapped store, or 0 if it does not
+// implement contract.EvictionCounter. It does not go through the circuit.
+func (b *BreakerStore) Evictions() uint64 {
+	return stream.Evictions(b.inner)
+}
+
+func (b *BreakerStore) Get(ctx context.Context, key string) (any, error) {
+	var value any
+	err := b.do(ctx, func(store contract.Store) error {
+		var err error
+		value, err = store.Get(ctx, key)
+		return err
+	})
+
+	return value, err
+}
+
+// GetReader streams the value of key if the store serving it implements
+// contract.Streamer, and reads it with Get otherwise. Only opening the
+// reader goes through the circuit; errors while reading are not counted.
+func (b *BreakerStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	err := b.do(ctx, func(store contract.Store) error {
+		var err error
+		reader, err = stream.GetReader(ctx, store, key)
+		return err
+	})
+
+	return reader, err
+}
+
+func (b *BreakerStore) Has(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := b.do(ctx, func(store contract.Store) error {
+		var err error
+		exists, err = store.Has(ctx, key)
+		return err
+	})
+
+	return exists, err
+}
+
+// Ping pings the wrapped store, or probes it with Has if it does not
+// implement contract.Pinger. Pings go through the circuit, so an open
+// circuit is reported as ErrCircuitOpen; the fallback is not pinged.
+func (b *BreakerStore) Ping(ctx context.Context) error {
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+
+	err = stream.Ping(ctx, b.inner)
+	b.done(ctx, generation, err)
+
+	return err
+}
+
+func (b *BreakerStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return store.Set(ctx, key, value, ttl)
+	})
+}
+
+// SetFromReader streams r into the store serving it if that store
+// implements contract.Streamer, and reads it into memory and calls Set
+// otherwise.
+func (b *BreakerStore) SetFromReader(ctx context.Context, key string, r io.Reader, ttl time.Duration) error {
+	return b.do(ctx, func(store contract.Store) error {
+		return stream.SetFromReader(ctx, store, key, r, ttl)
+	})
+}
+
+// Size returns the size of the wrapped store, or -1 entries and bytes if
+// it does not implement contract.Sizer. It does not go through the circuit.
+func (b *BreakerStore) Size() (entries int64, bytes int64) {
+	return stream.Size(b.inner)
+}
+
+// do runs op against the wrapped store if the circuit lets it through, and
+// against the fallback otherwise.
+func (b *BreakerStore) do(ctx context.Context, op func(store contract.Store) error) error {
+	generation, err := b.allow()
+	if err != nil {
+		if b.fallback != nil {
+			return op(b.fallback)
+		}
+		return err
+	}
+
+	err = op(b.inner)
+	b.done(ctx, generation, err)
+
+	return err
+}
+
+// allow reports whether an operation may reach the wrapped store. The
+// returned generation identifies the state the operation was admitted in.
+func (b *BreakerStore) allow() (uint64, error) {
+	b.mu.Lock()
+
+	var change *StateChange
+	if b.state == Open && b.now().Sub(b.openedAt) >= b.coolDown {
+		change = b.transition(HalfOpen, nil)
+	}
+
+	var err error
+	switch b.state {
+	case Open:
+		err = omnicache.ErrCircuitOpen
+	case HalfOpen:
+		if b.trials >= b.halfOpenRequests {
+			err = omnicache.ErrCircuitOpen
+		} else {
+			b.trials++
+		}
+	}
+	generation := b.generation
+
+	b.mu.Unlock()
+	b.notify(change)
+
+	if err != nil && b.name != "" {
+		err = fmt.Errorf("%w: store %q", err, b.name)
+	}
+
+	return generation, err
+}
+
+// done records the outcome of an operation admitted by allow. Outcomes of
+// operations admitted before the last transition are ignored.
+func (b *BreakerStore) done(ctx context.Context, generation uint64, err error) {
+	failed := b.isFailure(ctx, err)
+	abandoned := !failed && ctx.Err() != nil
+
+	b.mu.Lock()
+
+	var change *StateChange
+	if generation == b.generation {
+		switch {
+		case abandoned && b.state == HalfOpen:
+			b.trials--
+		case abandoned:
+		case b.state == Closed && failed:
+			b.failures++
+			if b.failures >= b.failureThreshold {
+				change = b.transition(Open, err)
+			}
+		case b.state == Closed:
+			b.failures = 0
+		case b.state == HalfOpen && failed:
+			change = b.transition(Open, err)
+		case b.state == HalfOpen:
+			b.successes++
+			if b.successes >= b.halfOpenRequests {
+				change = b.transition(Closed, nil)
+			}
+		}
+	}
+
+	b.mu.Unlock()
+	b.notify(change)
+}
+
+// transition moves the circuit to state. b.mu must be held.
+func (b *BreakerStore) transition(state State, err error) *StateChange {
+	change := &StateChange{Name: b.name, From: b.state, To: state, Err: err, At: b.now()}
+
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.trials = 0
+	b.successes = 0
+	if state == Open {
+		b.openedAt = change.At
+	}
+
+	return change
+}
+
+func (b *BreakerStore) notify(change *StateChange) {
+	if change != nil && b.onStateChange != nil {
+		b.onStateChange(*change)
+	}
+}
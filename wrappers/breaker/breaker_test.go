@@ -0,0 +1,388 @@
+package breakerstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil/storetest"
+)
+
+var errUnreachable = errors.New("dial tcp: connection refused")
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newBreaker wraps inner in a BreakerStore driven by the returned clock,
+// recording state changes into changes.
+func newBreaker(t *testing.T, inner contract.Store, config BreakerConfig, changes *[]StateChange) (*BreakerStore, *clock) {
+	t.Helper()
+
+	var mu sync.Mutex
+	config.OnStateChange = func(change StateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		*changes = append(*changes, change)
+	}
+
+	store, err := NewBreakerStore(inner, config)
+	assert.NoError(t, err, "expected no error when creating the breaker")
+
+	c := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := store.(*BreakerStore)
+	b.now = c.Now
+
+	return b, c
+}
+
+func transitions(changes []StateChange) []string {
+	result := make([]string, len(changes))
+	for i, change := range changes {
+		result[i] = change.From.String() + "->" + change.To.String()
+	}
+
+	return result
+}
+
+func TestNewBreakerStore(t *testing.T) {
+	t.Parallel()
+
+	inner, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+
+	tests := []struct {
+		name   string
+		inner  contract.Store
+		config BreakerConfig
+	}{
+		{name: "should reject a nil store", config: BreakerConfig{}},
+		{name: "should reject a negative threshold", inner: inner, config: BreakerConfig{FailureThreshold: -1}},
+		{name: "should reject a negative cool-down", inner: inner, config: BreakerConfig{CoolDown: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewBreakerStore(tt.inner, tt.config)
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+			assert.Nil(t, store, "expected no store")
+		})
+	}
+}
+
+func TestBreakerStore_opens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedState State
+	}{
+		{
+			name:          "should open after consecutive failures",
+			errs:          []error{errUnreachable, errUnreachable, errUnreachable},
+			expectedState: Open,
+		},
+		{
+			name:          "should reset the failures on success",
+			errs:          []error{errUnreachable, errUnreachable, nil, errUnreachable, errUnreachable, nil},
+			expectedState: Closed,
+		},
+		{
+			name:          "should not count misses as failures",
+			errs:          []error{omnicache.ErrCacheMiss, omnicache.ErrCacheMiss, omnicache.ErrCacheMiss},
+			expectedState: Closed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := &storetest.FlakyStore{}
+			var changes []StateChange
+			b, _ := newBreaker(t, inner, BreakerConfig{Name: "main", FailureThreshold: 3}, &changes)
+
+			// --- Act ---
+			for _, err := range tt.errs {
+				inner.Fail(err)
+				_, _ = b.Get(ctx, "k")
+			}
+			_, err := b.Get(ctx, "k")
+
+			// --- Assert ---
+			assert.Equal(t, tt.expectedState, b.State(), "expected the state of the circuit")
+			if tt.expectedState != Open {
+				assert.Equal(t, 0, len(changes), "expected no state change")
+				return
+			}
+
+			assert.True(t, errors.Is(err, omnicache.ErrCircuitOpen), "expected ErrCircuitOpen")
+			assert.Equal(t, `cache: circuit open: store "main"`, err.Error(), "expected the store to be named")
+			assert.Equal(t, len(tt.errs), inner.Calls(), "expected the store not to be called while open")
+			assert.Equal(t, []string{"closed->open"}, transitions(changes), "expected the circuit to open")
+			assert.EqualError(t, errUnreachable, changes[0].Err, "expected the failure that opened the circuit")
+			assert.Equal(t, "main", changes[0].Name, "expected the store to be named")
+		})
+	}
+}
+
+func TestBreakerStore_halfOpen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		trialErr            error
+		expectedState       State
+		expectedTransitions []string
+	}{
+		{
+			name:                "should close when the trials succeed",
+			expectedState:       Closed,
+			expectedTransitions: []string{"closed->open", "open->half-open", "half-open->closed"},
+		},
+		{
+			name:                "should open again when a trial fails",
+			trialErr:            errUnreachable,
+			expectedState:       Open,
+			expectedTransitions: []string{"closed->open", "open->half-open", "half-open->open"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := &storetest.FlakyStore{Err: errUnreachable}
+			var changes []StateChange
+			b, c := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}, &changes)
+
+			_, _ = b.Get(ctx, "k")
+			c.advance(time.Minute)
+			inner.Fail(tt.trialErr)
+
+			// --- Act ---
+			assert.Equal(t, HalfOpen, b.State(), "expected the circuit to be half-open after the cool-down")
+			_, err := b.Get(ctx, "k")
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, tt.trialErr), "expected the result of the trial")
+			assert.Equal(t, tt.expectedState, b.State(), "expected the state of the circuit")
+			assert.Equal(t, tt.expectedTransitions, transitions(changes), "expected the state changes")
+		})
+	}
+}
+
+func TestBreakerStore_halfOpenLimitsTrials(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := &storetest.FlakyStore{Err: errUnreachable}
+	var changes []StateChange
+	b, c := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute, HalfOpenRequests: 2}, &changes)
+
+	_, _ = b.Get(ctx, "k")
+	c.advance(time.Minute)
+
+	// --- Act ---
+	first, _ := b.allow()
+	second, _ := b.allow()
+	_, err := b.allow()
+
+	b.done(ctx, first, nil)
+	stateAfterFirst := b.State()
+	b.done(ctx, second, nil)
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, omnicache.ErrCircuitOpen), "expected operations beyond the trials to be rejected")
+	assert.Equal(t, HalfOpen, stateAfterFirst, "expected the circuit to wait for every trial")
+	assert.Equal(t, Closed, b.State(), "expected the circuit to close once every trial succeeded")
+}
+
+func TestBreakerStore_ignoresCanceledOperations(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	inner := &storetest.FlakyStore{Err: context.Canceled}
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1}, &changes)
+
+	// --- Act ---
+	_, err := b.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, context.Canceled), "expected the caller's error")
+	assert.Equal(t, Closed, b.State(), "expected canceled operations not to count as failures")
+}
+
+func TestBreakerStore_fallback(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	fallback, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+	assert.NoError(t, fallback.Set(ctx, "k", "local", 0))
+
+	inner := &storetest.FlakyStore{Err: errUnreachable}
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1, Fallback: fallback}, &changes)
+	_, _ = b.Get(ctx, "k")
+
+	// --- Act ---
+	value, err := b.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected the fallback to serve the operation")
+	assert.Equal(t, "local", value, "expected the fallback value")
+	assert.Equal(t, 1, inner.Calls(), "expected the store not to be called while open")
+}
+
+func TestBreakerStore_managerFallback(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	local, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+	assert.NoError(t, local.Set(ctx, "k", "local", 0))
+
+	inner := &storetest.FlakyStore{Err: errUnreachable}
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1}, &changes)
+
+	m := omnicache.NewManager()
+	assert.NoError(t, m.Register("main", b))
+	assert.NoError(t, m.Register("local", local))
+	assert.NoError(t, m.SetFallback("main", "local"))
+
+	// --- Act ---
+	_, _ = m.Get(ctx, "k")
+	value, err := m.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected the Manager to fail over while the circuit is open")
+	assert.Equal(t, "local", value, "expected the fallback value")
+	assert.Equal(t, 1, inner.Calls(), "expected the store not to be called while open")
+}
+
+func TestBreakerStore_capabilities(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{}, &changes)
+
+	// --- Act ---
+	err = b.SetFromReader(ctx, "k", strings.NewReader("streamed"), 0)
+	assert.NoError(t, err, "expected no error when streaming the value in")
+
+	reader, err := b.GetReader(ctx, "k")
+	assert.NoError(t, err, "expected no error when streaming the value out")
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err, "expected no error when reading the value")
+	assert.NoError(t, reader.Close())
+
+	entries, _ := b.Size()
+
+	// --- Assert ---
+	var store contract.Store = b
+	_, isStreamer := store.(contract.Streamer)
+	_, isSizer := store.(contract.Sizer)
+	_, isEvictionCounter := store.(contract.EvictionCounter)
+	assert.True(t, isStreamer, "expected the wrapper to be a contract.Streamer")
+	assert.True(t, isSizer, "expected the wrapper to be a contract.Sizer")
+	assert.True(t, isEvictionCounter, "expected the wrapper to be a contract.EvictionCounter")
+	assert.Equal(t, "streamed", string(data), "expected the streamed value")
+	assert.Equal(t, int64(1), entries, "expected the size of the wrapped store")
+}
+
+func TestBreakerStore_capabilitiesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := &storetest.EvictingStore{Store: &storetest.FlakyStore{}}
+
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{}, &changes)
+
+	// --- Act ---
+	reader, err := b.GetReader(ctx, "k")
+	assert.NoError(t, err, "expected the value to be read with Get")
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err, "expected no error when reading the value")
+
+	entries, bytes := b.Size()
+
+	// --- Assert ---
+	assert.Equal(t, "value", string(data), "expected the value read with Get")
+	assert.Equal(t, int64(-1), entries, "expected -1 entries when the store is not a Sizer")
+	assert.Equal(t, int64(-1), bytes, "expected -1 bytes when the store is not a Sizer")
+	assert.Equal(t, uint64(7), b.Evictions(), "expected the evictions of the wrapped store")
+}
+
+func TestBreakerStore_getReaderOpens(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := &storetest.FlakyStore{Err: errUnreachable}
+
+	var changes []StateChange
+	b, _ := newBreaker(t, inner, BreakerConfig{FailureThreshold: 1}, &changes)
+
+	// --- Act ---
+	_, err := b.GetReader(ctx, "k")
+	_, errOpen := b.GetReader(ctx, "k")
+
+	// --- Assert ---
+	assert.True(t, errors.Is(err, errUnreachable), "expected the store error")
+	assert.True(t, errors.Is(errOpen, omnicache.ErrCircuitOpen), "expected GetReader to go through the circuit")
+	assert.Equal(t, 1, inner.Calls(), "expected the store not to be called while open")
+}
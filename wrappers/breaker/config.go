@@ -0,0 +1,73 @@
+package breakerstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/shoraid/omnicache/contract"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures that
+	// opens the circuit.
+	DefaultFailureThreshold = 5
+
+	// DefaultCoolDown is how long the circuit stays open before trial
+	// operations are let through.
+	DefaultCoolDown = 30 * time.Second
+
+	// DefaultHalfOpenRequests is the number of trial operations let
+	// through while the circuit is half-open.
+	DefaultHalfOpenRequests = 1
+)
+
+type BreakerConfig struct {
+
+	// Name identifies the store in state changes, e.g. its alias.
+	//
+	// default: ""
+	Name string
+
+	// FailureThreshold is the number of consecutive failures that opens
+	// the circuit.
+	//
+	// default: 5
+	FailureThreshold int
+
+	// CoolDown is how long the circuit stays open before it turns
+	// half-open and lets trial operations through.
+	//
+	// default: 30s
+	CoolDown time.Duration
+
+	// HalfOpenRequests is the number of trial operations let through while
+	// the circuit is half-open. The circuit closes once they all succeed
+	// and opens again as soon as one fails.
+	//
+	// default: 1
+	HalfOpenRequests int
+
+	// IsFailure reports whether an operation failed with err and counts
+	// towards opening the circuit.
+	//
+	// default: omnicache.IsStoreFailure
+	IsFailure func(ctx context.Context, err error) bool
+
+	// Fallback serves operations while the circuit is open, instead of
+	// failing them with ErrCircuitOpen. It is not closed with the store.
+	//
+	// Writes and deletes served by the fallback do not reach the store:
+	// once the circuit closes, the store serves the values it held before,
+	// including those deleted in the meantime, and values written to the
+	// fallback are not copied back. Prefer short TTLs for data that must
+	// not go stale.
+	//
+	// default: nil
+	Fallback contract.Store
+
+	// OnStateChange is called after every state change, e.g. to alert when
+	// the circuit opens. It is called synchronously and must not block.
+	//
+	// default: nil
+	OnStateChange func(StateChange)
+}
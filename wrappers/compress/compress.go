@@ -0,0 +1,354 @@
+package compressstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/payload"
+)
+
+// magic starts every payload written by CompressStore.
+const magic byte = 0xc7
+
+// headerSize is the size of magic plus the algorithm byte.
+const headerSize = 2
+
+var (
+	errMalformedPayload = fmt.Errorf("%w: compress: malformed payload", omnicache.ErrIntegrity)
+	errTooLarge         = fmt.Errorf("%w: compress: decompressed payload exceeds MaxDecodedSize", omnicache.ErrInvalidValue)
+)
+
+// CompressStore wraps a contract.Store and compresses values above a size
+// threshold before delegating to it.
+type CompressStore struct {
+	inner          contract.Store
+	algorithm      Algorithm
+	threshold      int
+	level          int
+	maxDecodedSize int
+	codec          codec.Codec
+
+	gzipWriters sync.Pool
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	closeOnce   sync.Once
+
+	compressed        uint64
+	skipped           uint64
+	uncompressedBytes uint64
+	compressedBytes   uint64
+}
+
+// CompressStats reports how well values written through a CompressStore compressed.
+type CompressStats struct {
+	// Compressed is the number of values stored compressed.
+	Compressed uint64
+
+	// Skipped is the number of values stored uncompressed, because they
+	// were below the threshold or did not shrink.
+	Skipped uint64
+
+	// UncompressedBytes and CompressedBytes are the total sizes of the
+	// compressed values before and after compression.
+	UncompressedBytes uint64
+	CompressedBytes   uint64
+}
+
+// Ratio returns UncompressedBytes / CompressedBytes, e.g. 4 when values
+// shrank to a quarter of their size. It returns 0 if nothing was compressed.
+func (s CompressStats) Ratio() float64 {
+	if s.CompressedBytes == 0 {
+		return 0
+	}
+
+	return float64(s.UncompressedBytes) / float64(s.CompressedBytes)
+}
+
+// NewCompressStore creates a CompressStore wrapping inner.
+//
+// Values are serialized with config.Codec; payloads of at least
+// config.Threshold bytes are compressed with config.Algorithm. Every payload
+// records its algorithm, so values written uncompressed, with another
+// algorithm, or before the wrapper was introduced remain readable.
+// The returned store is a *CompressStore.
+// Returns ErrInvalidConfig if inner is nil or the algorithm or level is unknown.
+func NewCompressStore(inner contract.Store, config CompressConfig) (contract.Store, error) {
+	if inner == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if config.Algorithm == None {
+		config.Algorithm = Zstd
+	}
+
+	if config.Algorithm > Snappy {
+		return nil, fmt.Errorf("%w: compress: unknown algorithm %d", omnicache.ErrInvalidConfig, config.Algorithm)
+	}
+
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultThreshold
+	}
+
+	if config.Level == 0 {
+		config.Level = gzip.DefaultCompression
+	}
+
+	if config.Level != gzip.DefaultCompression && (config.Level < gzip.BestSpeed || config.Level > gzip.BestCompression) {
+		return nil, fmt.Errorf("%w: compress: invalid gzip level %d", omnicache.ErrInvalidConfig, config.Level)
+	}
+
+	if config.MaxDecodedSize <= 0 {
+		config.MaxDecodedSize = DefaultMaxDecodedSize
+	}
+
+	if config.Codec == nil {
+		config.Codec = codec.Default
+	}
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(config.MaxDecodedSize)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompressStore{
+		inner:          inner,
+		algorithm:      config.Algorithm,
+		threshold:      config.Threshold,
+		level:          config.Level,
+		maxDecodedSize: config.MaxDecodedSize,
+		codec:          config.Codec,
+		zstdEncoder:    encoder,
+		zstdDecoder:    decoder,
+	}, nil
+}
+
+// Stats returns a snapshot of the compression statistics.
+func (c *CompressStore) Stats() CompressStats {
+	return CompressStats{
+		Compressed:        atomic.LoadUint64(&c.compressed),
+		Skipped:           atomic.LoadUint64(&c.skipped),
+		UncompressedBytes: atomic.LoadUint64(&c.uncompressedBytes),
+		CompressedBytes:   atomic.LoadUint64(&c.compressedBytes),
+	}
+}
+
+// Clear removes all entries from the inner store.
+func (c *CompressStore) Clear(ctx context.Context) error {
+	return c.inner.Clear(ctx)
+}
+
+// Close releases the compressors and closes the inner store.
+// It is safe to call Close multiple times.
+func (c *CompressStore) Close(ctx context.Context) error {
+	c.closeOnce.Do(func() {
+		c.zstdEncoder.Close()
+		c.zstdDecoder.Close()
+	})
+
+	return c.inner.Close(ctx)
+}
+
+// Delete removes the entry associated with the given key.
+func (c *CompressStore) Delete(ctx context.Context, key string) error {
+	return c.inner.Delete(ctx, key)
+}
+
+// DeleteByPattern removes all entries whose keys match the given pattern.
+func (c *CompressStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	return c.inner.DeleteByPattern(ctx, pattern)
+}
+
+// DeleteMany removes multiple keys.
+func (c *CompressStore) DeleteMany(ctx context.Context, keys ...string) error {
+	return c.inner.DeleteMany(ctx, keys...)
+}
+
+// Get retrieves a value and decompresses it.
+//
+// []byte values written by the wrapper are returned as []byte; other
+// values are returned as codec.Raw holding the serialized value, so use
+// GenericManager or codec.Decode to get a typed value. Values not written
+// by the wrapper are returned unchanged.
+// Returns ErrIntegrity if a payload is malformed, and ErrInvalidValue if it
+// decompresses to more than MaxDecodedSize bytes.
+func (c *CompressStore) Get(ctx context.Context, key string) (any, error) {
+	value, err := c.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := payload.Extract(value, magic)
+	if !ok {
+		return value, nil
+	}
+
+	body, err := c.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.Unmarshal(body), nil
+}
+
+// Has checks whether a key exists in the inner store.
+func (c *CompressStore) Has(ctx context.Context, key string) (bool, error) {
+	return c.inner.Has(ctx, key)
+}
+
+// Set serializes the value, compresses it if it is at least Threshold
+// bytes and actually shrinks, and stores the result in the inner store.
+func (c *CompressStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	body, err := payload.Marshal(c.codec, value)
+	if err != nil {
+		return err
+	}
+
+	data, err := c.compress(body)
+	if err != nil {
+		return err
+	}
+
+	return c.inner.Set(ctx, key, data, ttl)
+}
+
+// compress returns the payload for body, compressed if worthwhile.
+func (c *CompressStore) compress(body []byte) ([]byte, error) {
+	if len(body) < c.threshold {
+		atomic.AddUint64(&c.skipped, 1)
+		return frame(None, body), nil
+	}
+
+	var out []byte
+	switch c.algorithm {
+	case Gzip:
+		var err error
+		if out, err = c.gzip(body); err != nil {
+			return nil, err
+		}
+	case Zstd:
+		out = c.zstdEncoder.EncodeAll(body, nil)
+	case Snappy:
+		out = s2.EncodeSnappy(nil, body)
+	}
+
+	if len(out) >= len(body) {
+		atomic.AddUint64(&c.skipped, 1)
+		return frame(None, body), nil
+	}
+
+	atomic.AddUint64(&c.compressed, 1)
+	atomic.AddUint64(&c.uncompressedBytes, uint64(len(body)))
+	atomic.AddUint64(&c.compressedBytes, uint64(len(out)))
+
+	return frame(c.algorithm, out), nil
+}
+
+func (c *CompressStore) gzip(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, _ := c.gzipWriters.Get().(*gzip.Writer)
+	if w == nil {
+		var err error
+		if w, err = gzip.NewWriterLevel(&buf, c.level); err != nil {
+			return nil, err
+		}
+	} else {
+		w.Reset(&buf)
+	}
+	defer c.gzipWriters.Put(w)
+
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompress reverses compress, enforcing MaxDecodedSize.
+func (c *CompressStore) decompress(data []byte) ([]byte, error) {
+	if len(data) < headerSize {
+		return nil, errMalformedPayload
+	}
+
+	algorithm, body := Algorithm(data[1]), data[headerSize:]
+
+	switch algorithm {
+	case None:
+		return append([]byte{}, body...), nil
+
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errMalformedPayload, err)
+		}
+		defer r.Close()
+
+		out, err := io.ReadAll(io.LimitReader(r, int64(c.maxDecodedSize)+1))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errMalformedPayload, err)
+		}
+		if len(out) > c.maxDecodedSize {
+			return nil, errTooLarge
+		}
+		return out, nil
+
+	case Zstd:
+		out, err := c.zstdDecoder.DecodeAll(body, nil)
+		if errors.Is(err, zstd.ErrDecoderSizeExceeded) || errors.Is(err, zstd.ErrWindowSizeExceeded) {
+			return nil, errTooLarge
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errMalformedPayload, err)
+		}
+		return out, nil
+
+	case Snappy:
+		n, err := s2.DecodedLen(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errMalformedPayload, err)
+		}
+		if n > c.maxDecodedSize {
+			return nil, errTooLarge
+		}
+		out, err := s2.Decode(nil, body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errMalformedPayload, err)
+		}
+		return out, nil
+
+	default:
+		return nil, errMalformedPayload
+	}
+}
+
+// frame prepends the payload header.
+func frame(algorithm Algorithm, body []byte) []byte {
+	out := make([]byte, 0, headerSize+len(body))
+	out = append(out, magic, byte(algorithm))
+
+	return append(out, body...)
+}
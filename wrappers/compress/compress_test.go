@@ -0,0 +1,383 @@
+package compressstore
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	filestore "github.com/shoraid/omnicache/drivers/file"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil/storetest"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+)
+
+type apiResponse struct {
+	Items []string `json:"items"`
+}
+
+// largeResponse returns a highly compressible value well above DefaultThreshold.
+func largeResponse() apiResponse {
+	items := make([]string, 500)
+	for i := range items {
+		items[i] = "item-" + strings.Repeat("x", 20)
+	}
+
+	return apiResponse{Items: items}
+}
+
+func newCompressStore(t *testing.T, inner contract.Store, config CompressConfig) *CompressStore {
+	t.Helper()
+
+	store, err := NewCompressStore(inner, config)
+	assert.NoError(t, err)
+
+	return store.(*CompressStore)
+}
+
+func TestCompressStore_NewCompressStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		inner       contract.Store
+		config      CompressConfig
+		expectedErr bool
+	}{
+		{
+			name:   "should apply defaults",
+			inner:  omnicachemock.NewMockStore(t),
+			config: CompressConfig{},
+		},
+		{
+			name:        "should return ErrInvalidConfig when inner store is nil",
+			config:      CompressConfig{},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig for unknown algorithm",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      CompressConfig{Algorithm: 9},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig for invalid gzip level",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      CompressConfig{Algorithm: Gzip, Level: 12},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewCompressStore(tt.inner, tt.config)
+
+			// --- Assert ---
+			if tt.expectedErr {
+				assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when creating store")
+
+			c := store.(*CompressStore)
+			assert.Equal(t, Zstd, c.algorithm, "expected Zstd by default")
+			assert.Equal(t, DefaultThreshold, c.threshold, "expected default threshold")
+			assert.Equal(t, DefaultMaxDecodedSize, c.maxDecodedSize, "expected default max decoded size")
+			assert.Equal(t, codec.Default, c.codec, "expected default codec")
+		})
+	}
+}
+
+func TestCompressStore_SetGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+	}{
+		{name: "should round-trip large values with gzip", algorithm: Gzip},
+		{name: "should round-trip large values with zstd", algorithm: Zstd},
+		{name: "should round-trip large values with snappy", algorithm: Snappy},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := storetest.NewMemoryStore(t)
+			store := newCompressStore(t, inner, CompressConfig{Algorithm: tt.algorithm})
+			value := largeResponse()
+
+			// --- Act ---
+			err := store.Set(ctx, "key", value, time.Minute)
+			assert.NoError(t, err, "expected no error when setting value")
+
+			result, err := store.Get(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, err, "expected no error when getting value")
+			assert.Equal(t, value, storetest.Decode[apiResponse](t, result), "decoded value must match")
+
+			stored, _ := inner.Get(ctx, "key")
+			assert.Equal(t, byte(tt.algorithm), stored.([]byte)[1], "expected algorithm flag in payload")
+
+			stats := store.Stats()
+			assert.Equal(t, uint64(1), stats.Compressed, "expected one compressed value")
+			assert.True(t, stats.Ratio() > 5, "expected a high compression ratio")
+		})
+	}
+}
+
+func TestCompressStore_Set(t *testing.T) {
+	t.Parallel()
+
+	random := make([]byte, 4096)
+	_, _ = rand.Read(random)
+
+	tests := []struct {
+		name            string
+		value           any
+		ttl             time.Duration
+		expectedFlag    Algorithm
+		expectedSkipped uint64
+		expectedErr     error
+	}{
+		{
+			name:            "should store values below threshold uncompressed",
+			value:           "small",
+			expectedFlag:    None,
+			expectedSkipped: 1,
+		},
+		{
+			name:            "should store incompressible values uncompressed",
+			value:           random,
+			expectedFlag:    None,
+			expectedSkipped: 1,
+		},
+		{
+			name:         "should compress values above threshold",
+			value:        largeResponse(),
+			expectedFlag: Zstd,
+		},
+		{
+			name:        "should return ErrInvalidValue when TTL is negative",
+			value:       "small",
+			ttl:         -time.Second,
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := storetest.NewMemoryStore(t)
+			store := newCompressStore(t, inner, CompressConfig{})
+
+			// --- Act ---
+			err := store.Set(ctx, "key", tt.value, tt.ttl)
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.EqualError(t, tt.expectedErr, err, "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when setting value")
+
+			stored, _ := inner.Get(ctx, "key")
+			assert.Equal(t, magic, stored.([]byte)[0], "expected payload magic")
+			assert.Equal(t, byte(tt.expectedFlag), stored.([]byte)[1], "expected algorithm flag")
+			assert.Equal(t, tt.expectedSkipped, store.Stats().Skipped, "skipped count must match")
+		})
+	}
+}
+
+func TestCompressStore_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, inner contract.Store)
+		config      CompressConfig
+		expected    any
+		expectedErr error
+	}{
+		{
+			name: "should return values not written by the wrapper unchanged",
+			setup: func(t *testing.T, inner contract.Store) {
+				assert.NoError(t, inner.Set(context.Background(), "key", "legacy", 0))
+			},
+			expected: "legacy",
+		},
+		{
+			name: "should read values written with another algorithm",
+			setup: func(t *testing.T, inner contract.Store) {
+				gz := newCompressStore(t, inner, CompressConfig{Algorithm: Gzip})
+				assert.NoError(t, gz.Set(context.Background(), "key", largeResponse(), 0))
+			},
+			config:   CompressConfig{Algorithm: Snappy},
+			expected: largeResponse(),
+		},
+		{
+			name: "should return []byte values as []byte",
+			setup: func(t *testing.T, inner contract.Store) {
+				raw := newCompressStore(t, inner, CompressConfig{})
+				assert.NoError(t, raw.Set(context.Background(), "key", []byte(strings.Repeat("ab", 1024)), 0))
+			},
+			expected: []byte(strings.Repeat("ab", 1024)),
+		},
+		{
+			name: "should return ErrCacheMiss for missing keys",
+			setup: func(t *testing.T, inner contract.Store) {
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+		{
+			name: "should reject payloads larger than MaxDecodedSize",
+			setup: func(t *testing.T, inner contract.Store) {
+				gz := newCompressStore(t, inner, CompressConfig{Algorithm: Gzip})
+				assert.NoError(t, gz.Set(context.Background(), "key", largeResponse(), 0))
+			},
+			config:      CompressConfig{MaxDecodedSize: 1024},
+			expectedErr: omnicache.ErrInvalidValue,
+		},
+		{
+			name: "should reject corrupted payloads",
+			setup: func(t *testing.T, inner contract.Store) {
+				assert.NoError(t, inner.Set(context.Background(), "key", []byte{magic, byte(Gzip), 'x'}, 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+		{
+			name: "should reject payloads with unknown algorithm",
+			setup: func(t *testing.T, inner contract.Store) {
+				assert.NoError(t, inner.Set(context.Background(), "key", []byte{magic, 42, 'x'}, 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := storetest.NewMemoryStore(t)
+			tt.setup(t, inner)
+			store := newCompressStore(t, inner, tt.config)
+
+			// --- Act ---
+			result, err := store.Get(ctx, "key")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "error must match the expected error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error when getting value")
+			if _, ok := tt.expected.(apiResponse); ok {
+				result = storetest.Decode[apiResponse](t, result)
+			}
+			assert.Equal(t, tt.expected, result, "value must match")
+		})
+	}
+}
+
+func TestCompressStore_JSONEncodingInnerStore(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner, err := filestore.NewFileStore(filestore.FileConfig{Dir: t.TempDir()})
+	assert.NoError(t, err)
+	defer inner.Close(ctx)
+
+	store := newCompressStore(t, inner, CompressConfig{Algorithm: Gzip})
+	value := largeResponse()
+
+	// --- Act ---
+	assert.NoError(t, store.Set(ctx, "key", value, 0))
+	result, err := store.Get(ctx, "key")
+
+	// --- Assert ---
+	assert.NoError(t, err, "expected no error reading through a JSON-encoding store")
+	assert.Equal(t, value, storetest.Decode[apiResponse](t, result), "decoded value must match")
+}
+
+func TestCompressStore_Delegation(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := omnicachemock.NewMockStore(t)
+	inner.Mock.On("Clear", ctx).Return(nil)
+	inner.Mock.On("Close", ctx).Return(nil)
+	inner.Mock.On("Delete", ctx, "a").Return(nil)
+	inner.Mock.On("DeleteByPattern", ctx, "a*").Return(nil)
+	inner.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+	inner.Mock.On("Has", ctx, "a").Return(true, nil)
+	store := newCompressStore(t, inner, CompressConfig{})
+
+	// --- Act ---
+	errClear := store.Clear(ctx)
+	errDelete := store.Delete(ctx, "a")
+	errPattern := store.DeleteByPattern(ctx, "a*")
+	errMany := store.DeleteMany(ctx, "a", "b")
+	has, errHas := store.Has(ctx, "a")
+	errClose := store.Close(ctx)
+	errCloseAgain := store.Close(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, errClear)
+	assert.NoError(t, errDelete)
+	assert.NoError(t, errPattern)
+	assert.NoError(t, errMany)
+	assert.NoError(t, errHas)
+	assert.NoError(t, errClose)
+	assert.NoError(t, errCloseAgain, "expected Close to be idempotent")
+	assert.True(t, has, "expected Has to delegate")
+	inner.Mock.AssertExpectations(t)
+}
+
+func TestCompressStore_StatsRatio(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		stats    CompressStats
+		expected float64
+	}{
+		{name: "should return 0 when nothing was compressed", stats: CompressStats{}, expected: 0},
+		{name: "should divide uncompressed by compressed bytes", stats: CompressStats{UncompressedBytes: 400, CompressedBytes: 100}, expected: 4},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act & Assert ---
+			assert.Equal(t, tt.expected, tt.stats.Ratio(), "ratio must match")
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package compressstore
+
+import "github.com/shoraid/omnicache/codec"
+
+// Algorithm selects the compression format. Its value is stored in every
+// payload, so entries written with different algorithms can coexist.
+type Algorithm byte
+
+const (
+	// None stores the payload uncompressed.
+	None Algorithm = 0
+
+	// Gzip uses gzip (RFC 1952). Widely supported, slower and denser.
+	Gzip Algorithm = 1
+
+	// Zstd uses Zstandard. Good ratio at high speed.
+	Zstd Algorithm = 2
+
+	// Snappy uses the Snappy block format. Fastest, lowest ratio.
+	Snappy Algorithm = 3
+)
+
+const (
+	// DefaultThreshold is the payload size from which values are compressed.
+	DefaultThreshold = 1024
+
+	// DefaultMaxDecodedSize caps the size of a decompressed payload.
+	DefaultMaxDecodedSize = 64 << 20
+)
+
+type CompressConfig struct {
+
+	// Algorithm used to compress new values.
+	//
+	// default: Zstd
+	Algorithm Algorithm
+
+	// Threshold is the encoded size in bytes from which values are
+	// compressed. Smaller values are stored uncompressed, since the
+	// compression overhead outweighs the savings.
+	//
+	// default: 1024 bytes
+	Threshold int
+
+	// Level is the gzip compression level (1-9). Ignored by other algorithms.
+	//
+	// default: gzip.DefaultCompression
+	Level int
+
+	// MaxDecodedSize caps the size of a decompressed payload, protecting
+	// readers from decompression bombs.
+	//
+	// default: 64 MiB
+	MaxDecodedSize int
+
+	// Codec serializes values before compression.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
@@ -0,0 +1,29 @@
+package encryptstore
+
+import "github.com/shoraid/omnicache/codec"
+
+type EncryptConfig struct {
+
+	// Keys maps key IDs to AES keys of 16, 24 or 32 bytes (AES-128, AES-192
+	// or AES-256). Every value records the ID of the key that encrypted it,
+	// so to rotate, add a new key, make it active, and keep the old one
+	// until the values it encrypted have expired.
+	Keys map[string][]byte
+
+	// ActiveKeyID is the ID of the key in Keys used to encrypt new values.
+	// IDs are at most 255 bytes long.
+	ActiveKeyID string
+
+	// KeyHMACSecret, if set, replaces every cache key with the hex-encoded
+	// HMAC-SHA256 of the key, so plaintext keys (which may contain e.g.
+	// e-mail addresses) never reach the inner store. Pattern deletes are
+	// then not supported. Changing the secret orphans existing entries.
+	//
+	// default: nil (keys are stored as-is)
+	KeyHMACSecret []byte
+
+	// Codec serializes values before encryption.
+	//
+	// default: codec.Default
+	Codec codec.Codec
+}
@@ -0,0 +1,250 @@
+package encryptstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/codec"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/payload"
+)
+
+// magic starts every payload written by EncryptStore.
+const magic byte = 0xe5
+
+// nonceSize is the AES-GCM nonce size.
+const nonceSize = 12
+
+// errUnknownKeyID reports a value encrypted with a key that is not, or no
+// longer, configured.
+var errUnknownKeyID = fmt.Errorf("%w: encrypt: unknown key id", omnicache.ErrIntegrity)
+
+// EncryptStore wraps a contract.Store and encrypts values with AES-GCM
+// before delegating to it.
+//
+// A payload is laid out as
+//
+//	[magic][key ID length][key ID][nonce][ciphertext and tag]
+//
+// The header and the stored key are authenticated as additional data, so a
+// payload moved to another key fails to decrypt like a tampered one.
+type EncryptStore struct {
+	inner     contract.Store
+	activeID  string
+	aeads     map[string]cipher.AEAD
+	keySecret []byte
+	codec     codec.Codec
+}
+
+// NewEncryptStore creates an EncryptStore wrapping inner.
+//
+// Returns ErrInvalidConfig if inner is nil, Keys is empty, a key has an
+// invalid ID or size, or ActiveKeyID is not in Keys.
+func NewEncryptStore(inner contract.Store, config EncryptConfig) (contract.Store, error) {
+	if inner == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if len(config.Keys) == 0 {
+		return nil, fmt.Errorf("%w: encrypt: no keys", omnicache.ErrInvalidConfig)
+	}
+
+	aeads := make(map[string]cipher.AEAD, len(config.Keys))
+	for id, key := range config.Keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("%w: encrypt: key id %q must be 1 to 255 bytes", omnicache.ErrInvalidConfig, id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encrypt: key %q: %v", omnicache.ErrInvalidConfig, id, err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encrypt: key %q: %v", omnicache.ErrInvalidConfig, id, err)
+		}
+
+		aeads[id] = aead
+	}
+
+	if _, ok := aeads[config.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("%w: encrypt: active key id %q not in keys", omnicache.ErrInvalidConfig, config.ActiveKeyID)
+	}
+
+	if config.Codec == nil {
+		config.Codec = codec.Default
+	}
+
+	return &EncryptStore{
+		inner:     inner,
+		activeID:  config.ActiveKeyID,
+		aeads:     aeads,
+		keySecret: config.KeyHMACSecret,
+		codec:     config.Codec,
+	}, nil
+}
+
+// Clear removes all entries from the inner store.
+func (e *EncryptStore) Clear(ctx context.Context) error {
+	return e.inner.Clear(ctx)
+}
+
+// Close closes the inner store.
+func (e *EncryptStore) Close(ctx context.Context) error {
+	return e.inner.Close(ctx)
+}
+
+// Delete removes the entry associated with the given key.
+func (e *EncryptStore) Delete(ctx context.Context, key string) error {
+	return e.inner.Delete(ctx, e.storedKey(key))
+}
+
+// DeleteByPattern removes all entries whose keys match the given pattern.
+// Returns ErrPatternNotSupported if keys are HMACed, since the stored keys
+// no longer resemble the originals.
+func (e *EncryptStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	if e.keySecret != nil {
+		return omnicache.ErrPatternNotSupported
+	}
+
+	return e.inner.DeleteByPattern(ctx, pattern)
+}
+
+// DeleteMany removes multiple keys.
+func (e *EncryptStore) DeleteMany(ctx context.Context, keys ...string) error {
+	stored := make([]string, len(keys))
+	for i, key := range keys {
+		stored[i] = e.storedKey(key)
+	}
+
+	return e.inner.DeleteMany(ctx, stored...)
+}
+
+// Get retrieves a value and decrypts it.
+//
+// []byte values are returned as []byte; other values are returned as
+// codec.Raw holding the serialized value, so use GenericManager or
+// codec.Decode to get a typed value.
+// Returns ErrIntegrity if the value was not written by the wrapper, was
+// encrypted with an unknown key or fails authentication.
+func (e *EncryptStore) Get(ctx context.Context, key string) (any, error) {
+	key = e.storedKey(key)
+
+	value, err := e.inner.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := payload.Extract(value, magic)
+	if !ok {
+		return nil, fmt.Errorf("%w: encrypt: value is not encrypted", omnicache.ErrIntegrity)
+	}
+
+	body, err := e.open(key, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return payload.Unmarshal(body), nil
+}
+
+// Has checks whether a key exists in the inner store.
+func (e *EncryptStore) Has(ctx context.Context, key string) (bool, error) {
+	return e.inner.Has(ctx, e.storedKey(key))
+}
+
+// Set serializes the value, encrypts it with the active key and stores the
+// result in the inner store.
+func (e *EncryptStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if ttl < 0 {
+		return omnicache.ErrInvalidValue
+	}
+
+	body, err := payload.Marshal(e.codec, value)
+	if err != nil {
+		return err
+	}
+
+	key = e.storedKey(key)
+
+	data, err := e.seal(key, body)
+	if err != nil {
+		return err
+	}
+
+	return e.inner.Set(ctx, key, data, ttl)
+}
+
+// storedKey returns the key used in the inner store.
+func (e *EncryptStore) storedKey(key string) string {
+	if e.keySecret == nil {
+		return key
+	}
+
+	mac := hmac.New(sha256.New, e.keySecret)
+	mac.Write([]byte(key))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// seal encrypts body with the active key.
+func (e *EncryptStore) seal(key string, body []byte) ([]byte, error) {
+	aead := e.aeads[e.activeID]
+
+	headerSize := 2 + len(e.activeID) + nonceSize
+	out := make([]byte, headerSize, headerSize+len(body)+aead.Overhead())
+	out[0] = magic
+	out[1] = byte(len(e.activeID))
+	copy(out[2:], e.activeID)
+
+	nonce := out[2+len(e.activeID):]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(out, nonce, body, additionalData(out, key)), nil
+}
+
+// open reverses seal.
+func (e *EncryptStore) open(key string, data []byte) ([]byte, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: encrypt: truncated payload", omnicache.ErrIntegrity)
+	}
+
+	idEnd := 2 + int(data[1])
+	headerSize := idEnd + nonceSize
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("%w: encrypt: truncated payload", omnicache.ErrIntegrity)
+	}
+
+	id := string(data[2:idEnd])
+	aead, ok := e.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", errUnknownKeyID, id)
+	}
+
+	header := data[:headerSize]
+	body, err := aead.Open(nil, data[idEnd:headerSize], data[headerSize:], additionalData(header, key))
+	if err != nil {
+		return nil, fmt.Errorf("%w: encrypt: %v", omnicache.ErrIntegrity, err)
+	}
+
+	return body, nil
+}
+
+// additionalData binds a payload to its header and stored key.
+func additionalData(header []byte, key string) []byte {
+	ad := make([]byte, 0, len(header)+len(key))
+	ad = append(ad, header...)
+
+	return append(ad, key...)
+}
@@ -0,0 +1,405 @@
+package encryptstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	filestore "github.com/shoraid/omnicache/drivers/file"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil/storetest"
+	omnicachemock "github.com/shoraid/omnicache/mock"
+	compressstore "github.com/shoraid/omnicache/wrappers/compress"
+)
+
+type patient struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func newEncryptStore(t *testing.T, inner contract.Store, config EncryptConfig) *EncryptStore {
+	t.Helper()
+
+	store, err := NewEncryptStore(inner, config)
+	assert.NoError(t, err)
+
+	return store.(*EncryptStore)
+}
+
+func TestEncryptStore_NewEncryptStore(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		inner       contract.Store
+		config      EncryptConfig
+		expectedErr bool
+	}{
+		{
+			name:   "should accept AES-128, AES-192 and AES-256 keys",
+			inner:  omnicachemock.NewMockStore(t),
+			config: EncryptConfig{Keys: map[string][]byte{"a": make([]byte, 16), "b": make([]byte, 24), "c": make([]byte, 32)}, ActiveKeyID: "c"},
+		},
+		{
+			name:        "should return ErrInvalidConfig when inner store is nil",
+			inner:       nil,
+			config:      EncryptConfig{Keys: map[string][]byte{"a": key1}, ActiveKeyID: "a"},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when no keys are given",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      EncryptConfig{ActiveKeyID: "a"},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when key size is invalid",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      EncryptConfig{Keys: map[string][]byte{"a": make([]byte, 10)}, ActiveKeyID: "a"},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when key id is empty",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      EncryptConfig{Keys: map[string][]byte{"": key1}, ActiveKeyID: ""},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when key id is too long",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      EncryptConfig{Keys: map[string][]byte{strings.Repeat("k", 256): key1}, ActiveKeyID: strings.Repeat("k", 256)},
+			expectedErr: true,
+		},
+		{
+			name:        "should return ErrInvalidConfig when active key id is unknown",
+			inner:       omnicachemock.NewMockStore(t),
+			config:      EncryptConfig{Keys: map[string][]byte{"a": key1}, ActiveKeyID: "b"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewEncryptStore(tt.inner, tt.config)
+
+			// --- Assert ---
+			if tt.expectedErr {
+				assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+				assert.True(t, store == nil, "expected nil store on error")
+				return
+			}
+
+			assert.NoError(t, err, "expected no error with valid config")
+			assert.NotNil(t, store, "expected store")
+		})
+	}
+}
+
+func TestEncryptStore_SetGet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		inner    func(t *testing.T) contract.Store
+		value    any
+		expected any
+	}{
+		{
+			name:     "should round-trip structs as codec.Raw",
+			inner:    storetest.NewMemoryStore,
+			value:    patient{Name: "ada", Email: "ada@example.com"},
+			expected: patient{Name: "ada", Email: "ada@example.com"},
+		},
+		{
+			name:     "should round-trip []byte as []byte",
+			inner:    storetest.NewMemoryStore,
+			value:    []byte{0x00, 0xff, 'a'},
+			expected: []byte{0x00, 0xff, 'a'},
+		},
+		{
+			name: "should round-trip through a JSON-encoding inner store",
+			inner: func(t *testing.T) contract.Store {
+				inner, err := filestore.NewFileStore(filestore.FileConfig{Dir: t.TempDir()})
+				assert.NoError(t, err)
+				t.Cleanup(func() { inner.Close(context.Background()) })
+				return inner
+			},
+			value:    patient{Name: "ada", Email: "ada@example.com"},
+			expected: patient{Name: "ada", Email: "ada@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			ctx := context.Background()
+			inner := tt.inner(t)
+			store := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+
+			// --- Act ---
+			errSet := store.Set(ctx, "key", tt.value, 0)
+			result, errGet := store.Get(ctx, "key")
+
+			// --- Assert ---
+			assert.NoError(t, errSet, "expected no error when setting value")
+			assert.NoError(t, errGet, "expected no error when getting value")
+			if _, ok := tt.expected.(patient); ok {
+				result = storetest.Decode[patient](t, result)
+			}
+			assert.Equal(t, tt.expected, result, "value must round-trip")
+		})
+	}
+}
+
+func TestEncryptStore_Set(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should not store the plaintext", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		inner := storetest.NewMemoryStore(t)
+		store := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+
+		// --- Act ---
+		err := store.Set(ctx, "key", patient{Name: "ada", Email: "ada@example.com"}, 0)
+
+		// --- Assert ---
+		assert.NoError(t, err, "expected no error when setting value")
+		stored, _ := inner.Get(ctx, "key")
+		assert.Equal(t, magic, stored.([]byte)[0], "expected payload magic")
+		assert.False(t, bytes.Contains(stored.([]byte), []byte("ada@example.com")), "expected ciphertext only")
+	})
+
+	t.Run("should use a fresh nonce for every value", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		ctx := context.Background()
+		inner := storetest.NewMemoryStore(t)
+		store := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+
+		// --- Act ---
+		assert.NoError(t, store.Set(ctx, "a", "same", 0))
+		assert.NoError(t, store.Set(ctx, "b", "same", 0))
+
+		// --- Assert ---
+		a, _ := inner.Get(ctx, "a")
+		b, _ := inner.Get(ctx, "b")
+		assert.False(t, bytes.Equal(a.([]byte), b.([]byte)), "expected different ciphertexts")
+	})
+
+	t.Run("should return ErrInvalidValue when TTL is negative", func(t *testing.T) {
+		t.Parallel()
+
+		// --- Arrange ---
+		store := newEncryptStore(t, storetest.NewMemoryStore(t), EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+
+		// --- Act ---
+		err := store.Set(context.Background(), "key", "value", -1)
+
+		// --- Assert ---
+		assert.EqualError(t, omnicache.ErrInvalidValue, err, "error must match the expected error")
+	})
+}
+
+func TestEncryptStore_KeyRotation(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := storetest.NewMemoryStore(t)
+	old := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+	assert.NoError(t, old.Set(ctx, "old", "written with k1", 0))
+
+	rotated := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1, "k2": key2}, ActiveKeyID: "k2"})
+
+	// --- Act ---
+	oldValue, errOld := rotated.Get(ctx, "old")
+	errSet := rotated.Set(ctx, "new", "written with k2", 0)
+	_, errStale := old.Get(ctx, "new")
+
+	// --- Assert ---
+	assert.NoError(t, errOld, "expected values written with a retired key to stay readable")
+	assert.Equal(t, "written with k1", storetest.Decode[string](t, oldValue), "value must match")
+	assert.NoError(t, errSet)
+	stored, _ := inner.Get(ctx, "new")
+	assert.Equal(t, "k2", string(stored.([]byte)[2:4]), "expected active key id in payload")
+	assert.True(t, errors.Is(errStale, errUnknownKeyID), "expected errUnknownKeyID for a key the store does not know")
+	assert.True(t, errors.Is(errStale, omnicache.ErrIntegrity), "expected an unknown key to fail the integrity check")
+}
+
+func TestEncryptStore_Integrity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		setup       func(t *testing.T, inner contract.Store, store *EncryptStore)
+		expectedErr error
+	}{
+		{
+			name: "should return ErrIntegrity when ciphertext is tampered with",
+			setup: func(t *testing.T, inner contract.Store, store *EncryptStore) {
+				ctx := context.Background()
+				assert.NoError(t, store.Set(ctx, "key", "value", 0))
+				stored, _ := inner.Get(ctx, "key")
+				data := append([]byte{}, stored.([]byte)...)
+				data[len(data)-1] ^= 0xff
+				assert.NoError(t, inner.Set(ctx, "key", data, 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+		{
+			name: "should return ErrIntegrity when payload is moved to another key",
+			setup: func(t *testing.T, inner contract.Store, store *EncryptStore) {
+				ctx := context.Background()
+				assert.NoError(t, store.Set(ctx, "other", "value", 0))
+				stored, _ := inner.Get(ctx, "other")
+				assert.NoError(t, inner.Set(ctx, "key", stored, 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+		{
+			name: "should return ErrIntegrity when value is not encrypted",
+			setup: func(t *testing.T, inner contract.Store, store *EncryptStore) {
+				assert.NoError(t, inner.Set(context.Background(), "key", "plaintext", 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+		{
+			name: "should return ErrIntegrity when payload is truncated",
+			setup: func(t *testing.T, inner contract.Store, store *EncryptStore) {
+				assert.NoError(t, inner.Set(context.Background(), "key", []byte{magic, 2, 'k', '1'}, 0))
+			},
+			expectedErr: omnicache.ErrIntegrity,
+		},
+		{
+			name: "should return ErrCacheMiss for missing keys",
+			setup: func(t *testing.T, inner contract.Store, store *EncryptStore) {
+			},
+			expectedErr: omnicache.ErrCacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			inner := storetest.NewMemoryStore(t)
+			store := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+			tt.setup(t, inner, store)
+
+			// --- Act ---
+			result, err := store.Get(context.Background(), "key")
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, tt.expectedErr), "error must match the expected error")
+			assert.True(t, result == nil, "expected nil value on error")
+		})
+	}
+}
+
+func TestEncryptStore_KeyHMAC(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := storetest.NewMemoryStore(t)
+	store := newEncryptStore(t, inner, EncryptConfig{
+		Keys:          map[string][]byte{"k1": key1},
+		ActiveKeyID:   "k1",
+		KeyHMACSecret: []byte("secret"),
+	})
+
+	// --- Act ---
+	errSet := store.Set(ctx, "user:ada@example.com", "value", 0)
+	plainExists, _ := inner.Has(ctx, "user:ada@example.com")
+	exists, errHas := store.Has(ctx, "user:ada@example.com")
+	value, errGet := store.Get(ctx, "user:ada@example.com")
+	errPattern := store.DeleteByPattern(ctx, "user:*")
+	errDelete := store.Delete(ctx, "user:ada@example.com")
+	existsAfterDelete, _ := store.Has(ctx, "user:ada@example.com")
+
+	// --- Assert ---
+	assert.NoError(t, errSet)
+	assert.NoError(t, errHas)
+	assert.NoError(t, errGet)
+	assert.NoError(t, errDelete)
+	assert.False(t, plainExists, "expected the plaintext key not to reach the inner store")
+	assert.True(t, exists, "expected Has to use the HMACed key")
+	assert.Equal(t, "value", storetest.Decode[string](t, value), "value must match")
+	assert.EqualError(t, omnicache.ErrPatternNotSupported, errPattern, "expected pattern deletes to be rejected")
+	assert.False(t, existsAfterDelete, "expected Delete to use the HMACed key")
+}
+
+func TestEncryptStore_StackedWithCompress(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	encrypted := newEncryptStore(t, storetest.NewMemoryStore(t), EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+	store, err := compressstore.NewCompressStore(encrypted, compressstore.CompressConfig{Threshold: 1})
+	assert.NoError(t, err)
+	value := patient{Name: strings.Repeat("ada", 100), Email: "ada@example.com"}
+
+	// --- Act ---
+	errSet := store.Set(ctx, "key", value, 0)
+	result, errGet := store.Get(ctx, "key")
+
+	// --- Assert ---
+	assert.NoError(t, errSet)
+	assert.NoError(t, errGet)
+	assert.Equal(t, value, storetest.Decode[patient](t, result), "value must survive compression and encryption")
+}
+
+func TestEncryptStore_Delegation(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner := omnicachemock.NewMockStore(t)
+	inner.Mock.On("Clear", ctx).Return(nil)
+	inner.Mock.On("Close", ctx).Return(nil)
+	inner.Mock.On("Delete", ctx, "a").Return(nil)
+	inner.Mock.On("DeleteByPattern", ctx, "a*").Return(nil)
+	inner.Mock.On("DeleteMany", ctx, []string{"a", "b"}).Return(nil)
+	inner.Mock.On("Has", ctx, "a").Return(true, nil)
+	store := newEncryptStore(t, inner, EncryptConfig{Keys: map[string][]byte{"k1": key1}, ActiveKeyID: "k1"})
+
+	// --- Act ---
+	errClear := store.Clear(ctx)
+	errDelete := store.Delete(ctx, "a")
+	errPattern := store.DeleteByPattern(ctx, "a*")
+	errMany := store.DeleteMany(ctx, "a", "b")
+	has, errHas := store.Has(ctx, "a")
+	errClose := store.Close(ctx)
+
+	// --- Assert ---
+	assert.NoError(t, errClear)
+	assert.NoError(t, errDelete)
+	assert.NoError(t, errPattern)
+	assert.NoError(t, errMany)
+	assert.NoError(t, errHas)
+	assert.NoError(t, errClose)
+	assert.True(t, has, "expected Has to delegate")
+	inner.Mock.AssertExpectations(t)
+}
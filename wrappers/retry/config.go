@@ -0,0 +1,79 @@
+package retrystore
+
+import (
+	"context"
+	"time"
+
+	"github.com/shoraid/omnicache"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times an operation is tried,
+	// including the first attempt.
+	DefaultMaxAttempts = 3
+
+	// DefaultInitialBackoff is the delay before the first retry.
+	DefaultInitialBackoff = 50 * time.Millisecond
+
+	// DefaultMaxBackoff caps the delay between two attempts.
+	DefaultMaxBackoff = time.Second
+
+	// DefaultMultiplier is the factor the delay grows by after each retry.
+	DefaultMultiplier = 2
+
+	// DefaultJitter is the fraction of each delay that is randomized.
+	DefaultJitter = 0.2
+)
+
+type RetryConfig struct {
+
+	// MaxAttempts is the number of times an operation is tried, including
+	// the first attempt. 1 disables retries.
+	//
+	// default: 3
+	MaxAttempts int
+
+	// Attempts overrides MaxAttempts per operation, e.g. to retry reads
+	// more eagerly than writes.
+	//
+	// default: nil
+	Attempts map[omnicache.Op]int
+
+	// Idempotent overrides which operations may be retried. The operations
+	// of contract.Store and GetReader are idempotent; operations run with
+	// RetryStore.Do under any other name, such as OpIncrement, are only
+	// retried if they are set to true here. SetFromReader cannot be set to
+	// true, because its reader is consumed by the first attempt.
+	//
+	// default: nil
+	Idempotent map[omnicache.Op]bool
+
+	// InitialBackoff is the delay before the first retry.
+	//
+	// default: 50ms
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts.
+	//
+	// default: 1s
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor the delay grows by after each retry.
+	//
+	// default: 2
+	Multiplier float64
+
+	// Jitter is the fraction of each delay that is randomized, between 0
+	// and 1, so that clients recovering together do not retry in lockstep.
+	// A delay d is shortened to a random value in [d*(1-Jitter), d]. A
+	// negative value disables jitter.
+	//
+	// default: 0.2
+	Jitter float64
+
+	// IsTransient reports whether an operation that failed with err may
+	// succeed when tried again.
+	//
+	// default: omnicache.IsStoreFailure, except for ErrCircuitOpen
+	IsTransient func(ctx context.Context, err error) bool
+}
@@ -0,0 +1,300 @@
+package retrystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/internal/stream"
+)
+
+// OpIncrement names an increment run with RetryStore.Do. It is not
+// idempotent, so it is only retried if RetryConfig.Idempotent allows it.
+const OpIncrement omnicache.Op = "increment"
+
+// idempotent lists the operations of contract.Store and GetReader, which
+// may be retried by default. SetFromReader is not, because its reader is
+// consumed by the first attempt.
+var idempotent = map[omnicache.Op]bool{
+	omnicache.OpClear:           true,
+	omnicache.OpDelete:          true,
+	omnicache.OpDeleteByPattern: true,
+	omnicache.OpDeleteMany:      true,
+	omnicache.OpGet:             true,
+	omnicache.OpGetReader:       true,
+	omnicache.OpHas:             true,
+	omnicache.OpSet:             true,
+}
+
+// RetryStore wraps a contract.Store and retries operations that fail with
+// a transient error, waiting with exponential backoff and jitter between
+// attempts.
+//
+// Only idempotent operations are retried (see RetryConfig.Idempotent).
+// Retries stop early when the context is done; the error of the last
+// attempt is returned.
+type RetryStore struct {
+	inner          contract.Store
+	maxAttempts    int
+	attempts       map[omnicache.Op]int
+	idempotent     map[omnicache.Op]bool
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	isTransient    func(ctx context.Context, err error) bool
+
+	// random and wait are replaced by tests.
+	random func() float64
+	wait   func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryStore creates a RetryStore wrapping inner.
+//
+// The returned store is a *RetryStore.
+// Returns ErrInvalidConfig if inner is nil, an attempt count or backoff is
+// negative, SetFromReader is made idempotent, the multiplier is below 1 or
+// the jitter above 1.
+func NewRetryStore(inner contract.Store, config RetryConfig) (contract.Store, error) {
+	if inner == nil {
+		return nil, omnicache.ErrInvalidConfig
+	}
+
+	if config.MaxAttempts < 0 || config.InitialBackoff < 0 || config.MaxBackoff < 0 {
+		return nil, fmt.Errorf("%w: retry: negative attempts or backoff", omnicache.ErrInvalidConfig)
+	}
+
+	for op, attempts := range config.Attempts {
+		if attempts < 1 {
+			return nil, fmt.Errorf("%w: retry: %s must be attempted at least once", omnicache.ErrInvalidConfig, op)
+		}
+	}
+
+	if config.Idempotent[omnicache.OpSetFromReader] {
+		return nil, fmt.Errorf("%w: retry: %s cannot be retried, its reader is consumed by the first attempt", omnicache.ErrInvalidConfig, omnicache.OpSetFromReader)
+	}
+
+	if config.Multiplier != 0 && config.Multiplier < 1 {
+		return nil, fmt.Errorf("%w: retry: multiplier %g is below 1", omnicache.ErrInvalidConfig, config.Multiplier)
+	}
+
+	if config.Jitter > 1 {
+		return nil, fmt.Errorf("%w: retry: jitter %g is above 1", omnicache.ErrInvalidConfig, config.Jitter)
+	}
+
+	if config.MaxAttempts == 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if config.InitialBackoff == 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if config.Multiplier == 0 {
+		config.Multiplier = DefaultMultiplier
+	}
+
+	if config.Jitter == 0 {
+		config.Jitter = DefaultJitter
+	}
+
+	if config.Jitter < 0 {
+		config.Jitter = 0
+	}
+
+	if config.IsTransient == nil {
+		config.IsTransient = isTransient
+	}
+
+	return &RetryStore{
+		inner:          inner,
+		maxAttempts:    config.MaxAttempts,
+		attempts:       config.Attempts,
+		idempotent:     config.Idempotent,
+		initialBackoff: config.InitialBackoff,
+		maxBackoff:     config.MaxBackoff,
+		multiplier:     config.Multiplier,
+		jitter:         config.Jitter,
+		isTransient:    config.IsTransient,
+		random:         rand.Float64,
+		wait:           wait,
+	}, nil
+}
+
+func (r *RetryStore) Clear(ctx context.Context) error {
+	return r.Do(ctx, omnicache.OpClear, func(ctx context.Context) error {
+		return r.inner.Clear(ctx)
+	})
+}
+
+// Close closes the wrapped store. It is not retried.
+func (r *RetryStore) Close(ctx context.Context) error {
+	return r.inner.Close(ctx)
+}
+
+func (r *RetryStore) Delete(ctx context.Context, key string) error {
+	return r.Do(ctx, omnicache.OpDelete, func(ctx context.Context) error {
+		return r.inner.Delete(ctx, key)
+	})
+}
+
+func (r *RetryStore) DeleteByPattern(ctx context.Context, pattern string) error {
+	return r.Do(ctx, omnicache.OpDeleteByPattern, func(ctx context.Context) error {
+		return r.inner.DeleteByPattern(ctx, pattern)
+	})
+}
+
+func (r *RetryStore) DeleteMany(ctx context.Context, keys ...string) error {
+	return r.Do(ctx, omnicache.OpDeleteMany, func(ctx context.Context) error {
+		return r.inner.DeleteMany(ctx, keys...)
+	})
+}
+
+// Do runs fn, retrying it with the policy of op. Use it to retry
+// operations the wrapped store's client offers beyond contract.Store:
+//
+//	err := store.Do(ctx, retrystore.OpIncrement, func(ctx context.Context) error {
+//		return client.Incr(ctx, "visits").Err()
+//	})
+//
+// Operations that are not idempotent, such as OpIncrement, are run once,
+// unless allowed by RetryConfig.Idempotent.
+func (r *RetryStore) Do(ctx context.Context, op omnicache.Op, fn func(ctx context.Context) error) error {
+	attempts := r.maxAttempts
+	if n, ok := r.attempts[op]; ok {
+		attempts = n
+	}
+
+	retryable, ok := r.idempotent[op]
+	if !ok {
+		retryable = idempotent[op]
+	}
+	if !retryable {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || attempt >= attempts || !r.isTransient(ctx, err) {
+			return err
+		}
+
+		if waitErr := r.wait(ctx, r.backoff(attempt)); waitErr != nil {
+			return err
+		}
+	}
+}
+
+// Evictions returns the evictions of the wrapped store, or 0 if it does not
+// implement contract.EvictionCounter.
+func (r *RetryStore) Evictions() uint64 {
+	return stream.Evictions(r.inner)
+}
+
+func (r *RetryStore) Get(ctx context.Context, key string) (any, error) {
+	var value any
+	err := r.Do(ctx, omnicache.OpGet, func(ctx context.Context) error {
+		var err error
+		value, err = r.inner.Get(ctx, key)
+		return err
+	})
+
+	return value, err
+}
+
+// GetReader streams the value of key if the wrapped store implements
+// contract.Streamer, and reads it with Get otherwise. Only opening the
+// reader is retried.
+func (r *RetryStore) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	err := r.Do(ctx, omnicache.OpGetReader, func(ctx context.Context) error {
+		var err error
+		reader, err = stream.GetReader(ctx, r.inner, key)
+		return err
+	})
+
+	return reader, err
+}
+
+func (r *RetryStore) Has(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := r.Do(ctx, omnicache.OpHas, func(ctx context.Context) error {
+		var err error
+		exists, err = r.inner.Has(ctx, key)
+		return err
+	})
+
+	return exists, err
+}
+
+// Ping pings the wrapped store, or probes it with Has if it does not
+// implement contract.Pinger. Pings are not retried, so health checks see
+// transient errors.
+func (r *RetryStore) Ping(ctx context.Context) error {
+	return stream.Ping(ctx, r.inner)
+}
+
+func (r *RetryStore) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return r.Do(ctx, omnicache.OpSet, func(ctx context.Context) error {
+		return r.inner.Set(ctx, key, value, ttl)
+	})
+}
+
+// SetFromReader streams r into the wrapped store if it implements
+// contract.Streamer, and reads it into memory and calls Set otherwise. It
+// is never retried, because r is consumed by the first attempt.
+func (r *RetryStore) SetFromReader(ctx context.Context, key string, reader io.Reader, ttl time.Duration) error {
+	return r.Do(ctx, omnicache.OpSetFromReader, func(ctx context.Context) error {
+		return stream.SetFromReader(ctx, r.inner, key, reader, ttl)
+	})
+}
+
+// Size returns the size of the wrapped store, or -1 entries and bytes if
+// it does not implement contract.Sizer.
+func (r *RetryStore) Size() (entries int64, bytes int64) {
+	return stream.Size(r.inner)
+}
+
+// backoff returns the delay after the given failed attempt.
+func (r *RetryStore) backoff(attempt int) time.Duration {
+	d := float64(r.initialBackoff) * math.Pow(r.multiplier, float64(attempt-1))
+	if d > float64(r.maxBackoff) {
+		d = float64(r.maxBackoff)
+	}
+
+	return time.Duration(d * (1 - r.jitter*r.random()))
+}
+
+// isTransient is the default RetryConfig.IsTransient. An open circuit
+// fails the same way when tried again.
+func isTransient(ctx context.Context, err error) bool {
+	if errors.Is(err, omnicache.ErrCircuitOpen) {
+		return false
+	}
+
+	return omnicache.IsStoreFailure(ctx, err)
+}
+
+// wait sleeps for d or until ctx is done.
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,317 @@
+package retrystore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shoraid/omnicache"
+	"github.com/shoraid/omnicache/contract"
+	"github.com/shoraid/omnicache/drivers/memory"
+	"github.com/shoraid/omnicache/internal/assert"
+	"github.com/shoraid/omnicache/internal/testutil/storetest"
+)
+
+var errTimeout = errors.New("i/o timeout")
+
+// newRetry wraps inner in a RetryStore that records its delays instead of
+// sleeping.
+func newRetry(t *testing.T, inner contract.Store, config RetryConfig) (*RetryStore, *[]time.Duration) {
+	t.Helper()
+
+	store, err := NewRetryStore(inner, config)
+	assert.NoError(t, err, "expected no error when creating the retry store")
+
+	delays := &[]time.Duration{}
+	r := store.(*RetryStore)
+	r.random = func() float64 { return 1 }
+	r.wait = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+
+	return r, delays
+}
+
+func TestNewRetryStore(t *testing.T) {
+	t.Parallel()
+
+	inner, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+
+	tests := []struct {
+		name   string
+		inner  contract.Store
+		config RetryConfig
+	}{
+		{name: "should reject a nil store", config: RetryConfig{}},
+		{name: "should reject negative attempts", inner: inner, config: RetryConfig{MaxAttempts: -1}},
+		{name: "should reject operations never attempted", inner: inner, config: RetryConfig{Attempts: map[omnicache.Op]int{omnicache.OpGet: 0}}},
+		{name: "should reject a negative backoff", inner: inner, config: RetryConfig{MaxBackoff: -time.Second}},
+		{name: "should reject retrying SetFromReader", inner: inner, config: RetryConfig{Idempotent: map[omnicache.Op]bool{omnicache.OpSetFromReader: true}}},
+		{name: "should reject a multiplier below 1", inner: inner, config: RetryConfig{Multiplier: 0.5}},
+		{name: "should reject a jitter above 1", inner: inner, config: RetryConfig{Jitter: 1.5}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Act ---
+			store, err := NewRetryStore(tt.inner, tt.config)
+
+			// --- Assert ---
+			assert.True(t, errors.Is(err, omnicache.ErrInvalidConfig), "expected ErrInvalidConfig")
+			assert.Nil(t, store, "expected no store")
+		})
+	}
+}
+
+func TestRetryStore_Get(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		config         RetryConfig
+		errs           []error
+		expectedErr    error
+		expectedCalls  int
+		expectedDelays []time.Duration
+	}{
+		{
+			name:           "should retry transient errors with exponential backoff",
+			config:         RetryConfig{Jitter: -1},
+			errs:           []error{errTimeout, errTimeout},
+			expectedCalls:  3,
+			expectedDelays: []time.Duration{50 * time.Millisecond, 100 * time.Millisecond},
+		},
+		{
+			name:           "should return the last error once the attempts are used up",
+			config:         RetryConfig{Jitter: -1},
+			errs:           []error{errTimeout, errTimeout, errTimeout},
+			expectedErr:    errTimeout,
+			expectedCalls:  3,
+			expectedDelays: []time.Duration{50 * time.Millisecond, 100 * time.Millisecond},
+		},
+		{
+			name:           "should cap the backoff",
+			config:         RetryConfig{MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 1500 * time.Millisecond, Jitter: -1},
+			errs:           []error{errTimeout, errTimeout, errTimeout},
+			expectedCalls:  4,
+			expectedDelays: []time.Duration{time.Second, 1500 * time.Millisecond, 1500 * time.Millisecond},
+		},
+		{
+			name:           "should shorten delays by the jitter",
+			config:         RetryConfig{Jitter: 0.5},
+			errs:           []error{errTimeout},
+			expectedCalls:  2,
+			expectedDelays: []time.Duration{25 * time.Millisecond},
+		},
+		{
+			name:           "should use the attempts of the operation",
+			config:         RetryConfig{Attempts: map[omnicache.Op]int{omnicache.OpGet: 2}, Jitter: -1},
+			errs:           []error{errTimeout, errTimeout},
+			expectedErr:    errTimeout,
+			expectedCalls:  2,
+			expectedDelays: []time.Duration{50 * time.Millisecond},
+		},
+		{
+			name:           "should not retry misses",
+			errs:           []error{omnicache.ErrCacheMiss},
+			expectedErr:    omnicache.ErrCacheMiss,
+			expectedCalls:  1,
+			expectedDelays: []time.Duration{},
+		},
+		{
+			name:           "should not retry an open circuit",
+			errs:           []error{omnicache.ErrCircuitOpen},
+			expectedErr:    omnicache.ErrCircuitOpen,
+			expectedCalls:  1,
+			expectedDelays: []time.Duration{},
+		},
+		{
+			name: "should use the classifier",
+			config: RetryConfig{
+				IsTransient: func(ctx context.Context, err error) bool { return errors.Is(err, omnicache.ErrCacheMiss) },
+				Jitter:      -1,
+			},
+			errs:           []error{omnicache.ErrCacheMiss, errTimeout},
+			expectedErr:    errTimeout,
+			expectedCalls:  2,
+			expectedDelays: []time.Duration{50 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			inner := &storetest.FlakyStore{Errs: tt.errs}
+			r, delays := newRetry(t, inner, tt.config)
+
+			// --- Act ---
+			value, err := r.Get(context.Background(), "k")
+
+			// --- Assert ---
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "expected the error of the last attempt")
+			} else {
+				assert.NoError(t, err, "expected no error")
+				assert.Equal(t, "value", value, "expected the value")
+			}
+			assert.Equal(t, tt.expectedCalls, inner.Calls(), "expected the number of attempts")
+			assert.Equal(t, tt.expectedDelays, *delays, "expected the delays between attempts")
+		})
+	}
+}
+
+func TestRetryStore_idempotency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		config        RetryConfig
+		act           func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error
+		expectedCalls int
+	}{
+		{
+			name: "should not retry operations that are not idempotent",
+			act: func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error {
+				return r.Do(ctx, OpIncrement, func(ctx context.Context) error { return inner.Set(ctx, "counter", 1, 0) })
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:   "should retry operations allowed explicitly",
+			config: RetryConfig{Idempotent: map[omnicache.Op]bool{OpIncrement: true}},
+			act: func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error {
+				return r.Do(ctx, OpIncrement, func(ctx context.Context) error { return inner.Set(ctx, "counter", 1, 0) })
+			},
+			expectedCalls: 3,
+		},
+		{
+			name: "should retry GetReader",
+			act: func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error {
+				_, err := r.GetReader(ctx, "k")
+				return err
+			},
+			expectedCalls: 3,
+		},
+		{
+			name: "should not retry SetFromReader",
+			act: func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error {
+				return r.SetFromReader(ctx, "k", strings.NewReader("v"), time.Minute)
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:   "should not retry store operations disallowed explicitly",
+			config: RetryConfig{Idempotent: map[omnicache.Op]bool{omnicache.OpSet: false}},
+			act: func(ctx context.Context, r *RetryStore, inner *storetest.FlakyStore) error {
+				return r.Set(ctx, "k", "v", time.Minute)
+			},
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// --- Arrange ---
+			inner := &storetest.FlakyStore{Errs: []error{errTimeout, errTimeout, errTimeout}}
+			r, _ := newRetry(t, inner, tt.config)
+
+			// --- Act ---
+			err := tt.act(context.Background(), r, inner)
+
+			// --- Assert ---
+			assert.EqualError(t, errTimeout, err, "expected the error of the last attempt")
+			assert.Equal(t, tt.expectedCalls, inner.Calls(), "expected the number of attempts")
+		})
+	}
+}
+
+func TestRetryStore_capabilities(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	inner, err := memory.NewMemoryStore(memory.MemoryConfig{})
+	assert.NoError(t, err, "expected no error when creating the memory store")
+
+	r, _ := newRetry(t, inner, RetryConfig{})
+
+	// --- Act ---
+	err = r.SetFromReader(ctx, "k", strings.NewReader("streamed"), 0)
+	assert.NoError(t, err, "expected no error when streaming the value in")
+
+	reader, err := r.GetReader(ctx, "k")
+	assert.NoError(t, err, "expected no error when streaming the value out")
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err, "expected no error when reading the value")
+	assert.NoError(t, reader.Close())
+
+	entries, _ := r.Size()
+
+	// --- Assert ---
+	var store contract.Store = r
+	_, isStreamer := store.(contract.Streamer)
+	_, isSizer := store.(contract.Sizer)
+	_, isEvictionCounter := store.(contract.EvictionCounter)
+	assert.True(t, isStreamer, "expected the wrapper to be a contract.Streamer")
+	assert.True(t, isSizer, "expected the wrapper to be a contract.Sizer")
+	assert.True(t, isEvictionCounter, "expected the wrapper to be a contract.EvictionCounter")
+	assert.Equal(t, "streamed", string(data), "expected the streamed value")
+	assert.Equal(t, int64(1), entries, "expected the size of the wrapped store")
+}
+
+func TestRetryStore_capabilitiesUnsupported(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx := context.Background()
+	r, _ := newRetry(t, &storetest.EvictingStore{Store: &storetest.FlakyStore{}}, RetryConfig{})
+
+	// --- Act ---
+	reader, err := r.GetReader(ctx, "k")
+	assert.NoError(t, err, "expected the value to be read with Get")
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err, "expected no error when reading the value")
+
+	entries, bytes := r.Size()
+
+	// --- Assert ---
+	assert.Equal(t, "value", string(data), "expected the value read with Get")
+	assert.Equal(t, int64(-1), entries, "expected -1 entries when the store is not a Sizer")
+	assert.Equal(t, int64(-1), bytes, "expected -1 bytes when the store is not a Sizer")
+	assert.Equal(t, uint64(7), r.Evictions(), "expected the evictions of the wrapped store")
+}
+
+func TestRetryStore_stopsWhenContextDone(t *testing.T) {
+	t.Parallel()
+
+	// --- Arrange ---
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	inner := &storetest.FlakyStore{Errs: []error{errTimeout, errTimeout}}
+	store, err := NewRetryStore(inner, RetryConfig{InitialBackoff: time.Minute})
+	assert.NoError(t, err, "expected no error when creating the retry store")
+
+	// --- Act ---
+	start := time.Now()
+	_, err = store.Get(ctx, "k")
+
+	// --- Assert ---
+	assert.EqualError(t, errTimeout, err, "expected the error of the last attempt")
+	assert.Equal(t, 1, inner.Calls(), "expected no retry once the context is done")
+	assert.True(t, time.Since(start) < time.Minute, "expected the backoff to be interrupted")
+}